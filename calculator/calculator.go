@@ -2,6 +2,7 @@ package calculator
 
 import (
 	"errors"
+	"fmt"
 	"strings"
 
 	"github.com/DeepAung/qcal/internal/evaluator"
@@ -11,25 +12,117 @@ import (
 )
 
 type Calculator struct {
-	env *object.Environment
+	env          *object.Environment
+	random       *object.Random // kept by Reset, so the seed(n) state survives it
+	results      int            // number of results stored as `$1`, `$2`, ...
+	numberFormat object.NumberFormat
 }
 
 func NewCalculator() *Calculator {
+	random := object.NewTimeSeededRandom()
 	return &Calculator{
-		env: object.NewEnvironment(),
+		env:          object.NewEnvironmentWithRandom(random),
+		random:       random,
+		numberFormat: object.DefaultNumberFormat,
 	}
 }
 
 func (c *Calculator) Calculate(input string) (object.Object, error) {
-	program, errMessages := parser.New(lexer.New(input)).ParseProgram()
-	if errMessages != nil && len(errMessages) > 0 {
-		if len(errMessages) == 1 {
-			return nil, errors.New("ERROR: " + errMessages[0])
+	evaluated, err := c.evaluate(input)
+	if err != nil || evaluated == nil {
+		return nil, err
+	}
+
+	c.storeResult(evaluated)
+	return evaluated, nil
+}
+
+// Inspect returns the result formatted with the calculator number format.
+func (c *Calculator) Inspect(result object.Object) string {
+	return c.numberFormat.FormatObject(result)
+}
+
+func (c *Calculator) NumberFormat() object.NumberFormat {
+	return c.numberFormat
+}
+
+func (c *Calculator) SetNumberFormat(format object.NumberFormat) {
+	c.numberFormat = format
+}
+
+// Type evaluates the input in a sandbox copy of the environment, without
+// changing the bindings, the random numbers or the files, and returns the type
+// of its result.
+func (c *Calculator) Type(input string) (object.ObjectType, error) {
+	evaluated, err := evaluate(input, c.env.Sandbox())
+	if err != nil {
+		return "", err
+	}
+	if evaluated == nil {
+		return object.NULL_OBJ, nil
+	}
+	if letValue, ok := evaluated.(*object.LetValue); ok {
+		return letValue.Value.Type(), nil
+	}
+	return evaluated.Type(), nil
+}
+
+// PlotFile writes the plot of the comma separated functions, e.g.
+// `x => x^2, sin`, to the .svg or .png file at the path.
+func (c *Calculator) PlotFile(path string, functions string, xmin, xmax float64) error {
+	exps, errMessages := parser.New(lexer.New(functions)).ParseExpressionList()
+	if err := parseError(errMessages); err != nil {
+		return err
+	}
+	if len(exps) == 0 {
+		return errors.New("ERROR: no functions to plot")
+	}
+
+	fns := make([]object.Object, len(exps))
+	for i, exp := range exps {
+		fns[i] = evaluator.Eval(exp, c.env)
+		if evaluator.IsError(fns[i]) {
+			return errors.New(fns[i].Inspect())
 		}
-		return nil, errors.New("ERROR:\n" + strings.Join(errMessages, "\n"))
 	}
 
-	evaluated := evaluator.Eval(program, c.env)
+	return evaluator.WritePlotFile(path, fns, xmin, xmax)
+}
+
+// Vars returns the bindings of the calculator as `name = value` strings,
+// sorted by name.
+func (c *Calculator) Vars() []string {
+	var vars []string
+	for _, name := range c.env.Names() {
+		obj, _ := c.env.Get(name)
+		vars = append(vars, name+" = "+c.Inspect(obj))
+	}
+	return vars
+}
+
+// Delete removes the binding and reports whether it existed.
+func (c *Calculator) Delete(name string) bool {
+	return c.env.Delete(name)
+}
+
+// Reset removes every binding and result reference, the random number
+// generator keeps its state.
+func (c *Calculator) Reset() {
+	c.env = object.NewEnvironmentWithRandom(c.random)
+	c.results = 0
+}
+
+func (c *Calculator) evaluate(input string) (object.Object, error) {
+	return evaluate(input, c.env)
+}
+
+func evaluate(input string, env *object.Environment) (object.Object, error) {
+	program, errMessages := parser.New(lexer.New(input)).ParseProgram()
+	if err := parseError(errMessages); err != nil {
+		return nil, err
+	}
+
+	evaluated := evaluator.Eval(program, env)
 	if evaluated == nil {
 		return nil, nil
 	}
@@ -40,3 +133,31 @@ func (c *Calculator) Calculate(input string) (object.Object, error) {
 
 	return evaluated, nil
 }
+
+func parseError(errMessages []string) error {
+	switch len(errMessages) {
+	case 0:
+		return nil
+	case 1:
+		return errors.New("ERROR: " + errMessages[0])
+	default:
+		return errors.New("ERROR:\n" + strings.Join(errMessages, "\n"))
+	}
+}
+
+// ResultCount returns the number of stored results, so the latest result
+// can be referenced as `$<ResultCount()>`.
+func (c *Calculator) ResultCount() int {
+	return c.results
+}
+
+// storeResult binds the result to `ans` and to the next numbered reference.
+func (c *Calculator) storeResult(result object.Object) {
+	if letValue, ok := result.(*object.LetValue); ok {
+		result = letValue.Value
+	}
+
+	c.results++
+	c.env.Set("ans", result)
+	c.env.Set(fmt.Sprintf("$%d", c.results), result)
+}
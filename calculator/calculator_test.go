@@ -0,0 +1,153 @@
+package calculator
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/DeepAung/qcal/internal/object"
+)
+
+func TestType(t *testing.T) {
+	tests := []struct {
+		input  string
+		expect object.ObjectType
+	}{
+		{"x", object.NUMBER_OBJ},
+		{"x = true", object.BOOLEAN_OBJ},
+		{"y = 5", object.NUMBER_OBJ},
+		{"if true { x = 2; z = 3 }", object.NUMBER_OBJ},
+		{"f = n => n", object.FUNCTION_OBJ},
+	}
+
+	c := NewCalculator()
+	if _, err := c.Calculate("x = 1"); err != nil {
+		t.Fatalf("Calculate failed: %v", err)
+	}
+	for _, tt := range tests {
+		got, err := c.Type(tt.input)
+		if err != nil {
+			t.Fatalf("Type(%q) failed: %v", tt.input, err)
+		}
+		if got != tt.expect {
+			t.Errorf("invalid type of %q, expect=%s, got=%s", tt.input, tt.expect, got)
+		}
+	}
+
+	if x, _ := c.env.Get("x"); x.Inspect() != "1" {
+		t.Errorf("Type assigned x, expect=1, got=%s", x.Inspect())
+	}
+	for _, name := range []string{"y", "z", "f"} {
+		if _, ok := c.env.Get(name); ok {
+			t.Errorf("Type bound %s", name)
+		}
+	}
+}
+
+func TestTypeSandbox(t *testing.T) {
+	c := NewCalculator()
+	if _, err := c.Calculate("count = 0; inc = () => { count += 1 }; m = memo(n => n * 2); seed(7)"); err != nil {
+		t.Fatalf("Calculate failed: %v", err)
+	}
+
+	tests := []struct {
+		input  string
+		expect object.ObjectType
+	}{
+		{"inc()", object.NUMBER_OBJ},
+		{"m(3)", object.NUMBER_OBJ},
+		{"rand()", object.NUMBER_OBJ},
+		{"seed(1)", object.NULL_OBJ},
+		{`plotfile("typed.svg", sin, 0, 1)`, object.STRING_OBJ},
+	}
+	for _, tt := range tests {
+		got, err := c.Type(tt.input)
+		if err != nil {
+			t.Fatalf("Type(%q) failed: %v", tt.input, err)
+		}
+		if got != tt.expect {
+			t.Errorf("invalid type of %q, expect=%s, got=%s", tt.input, tt.expect, got)
+		}
+	}
+
+	if count, _ := c.env.Get("count"); count.Inspect() != "0" {
+		t.Errorf("Type changed count, expect=0, got=%s", count.Inspect())
+	}
+	if m, _ := c.env.Get("m"); len(m.(*object.MemoFunction).Cache) != 0 {
+		t.Errorf("Type filled the memo cache")
+	}
+	if _, err := os.Stat("typed.svg"); err == nil {
+		os.Remove("typed.svg")
+		t.Errorf("Type wrote typed.svg")
+	}
+
+	seeded := NewCalculator()
+	seeded.Calculate("seed(7)")
+	expect, _ := seeded.Calculate("rand()")
+	if got, _ := c.Calculate("rand()"); got.Inspect() != expect.Inspect() {
+		t.Errorf("Type advanced the random numbers, expect=%s, got=%s", expect.Inspect(), got.Inspect())
+	}
+}
+
+func TestPlotFile(t *testing.T) {
+	dir := t.TempDir()
+	c := NewCalculator()
+	if _, err := c.Calculate("k = 2"); err != nil {
+		t.Fatal(err)
+	}
+
+	path := filepath.Join(dir, "plot.svg")
+	if err := c.PlotFile(path, "x => k * x, sin", -1, 1); err != nil {
+		t.Fatalf("cannot plot: %v", err)
+	}
+	data, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatalf("cannot read the plot: %v", err)
+	}
+	if !strings.Contains(string(data), "<svg") {
+		t.Fatalf("invalid plot file, expect an svg, got=%q", data)
+	}
+
+	tests := []struct {
+		path      string
+		functions string
+		expect    string
+	}{
+		{"plot.txt", "sin", `ERROR: "plotfile": unsupported file extension ".txt", expect .svg or .png`},
+		{"plot.svg", "", "ERROR: no functions to plot"},
+		{"plot.svg", "y", "ERROR: identifier not found: y"},
+		{"plot.svg", `"a")`, "ERROR: "},
+	}
+
+	for _, tt := range tests {
+		err := c.PlotFile(filepath.Join(dir, tt.path), tt.functions, -1, 1)
+		if err == nil || !strings.HasPrefix(err.Error(), tt.expect) {
+			t.Fatalf("invalid error for %q, expect=%q, got=%v", tt.functions, tt.expect, err)
+		}
+	}
+}
+
+func TestResetKeepsSeed(t *testing.T) {
+	c := NewCalculator()
+	calculate := func(input string) string {
+		t.Helper()
+		result, err := c.Calculate(input)
+		if err != nil {
+			t.Fatalf("Calculate(%q) failed: %v", input, err)
+		}
+		if result == nil {
+			return ""
+		}
+		return result.Inspect()
+	}
+
+	calculate("seed(42)")
+	expect := calculate("rand()")
+
+	calculate("seed(42)")
+	c.Reset()
+	if got := calculate("rand()"); got != expect {
+		t.Errorf("Reset discarded the seed, expect=%s, got=%s", expect, got)
+	}
+}
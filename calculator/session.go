@@ -0,0 +1,330 @@
+package calculator
+
+import (
+	"bufio"
+	"errors"
+	"fmt"
+	"io"
+	"math"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/DeepAung/qcal/internal/ast"
+	"github.com/DeepAung/qcal/internal/evaluator"
+	"github.com/DeepAung/qcal/internal/lexer"
+	"github.com/DeepAung/qcal/internal/object"
+	"github.com/DeepAung/qcal/internal/parser"
+)
+
+// Save writes every user variable and function as a qcal statement, one per
+// line and sorted by name, e.g.
+//
+//	const g = 9.81
+//	f = (x) => (x ^ 2)
+//	x = 5
+//
+// The imported modules and the constants are written first, as the memo
+// functions may read them. The `ans` and `$n` result references are not saved.
+// A binding without a source, e.g. a caught error, is skipped and reported by
+// a *SkippedError after the other bindings are written.
+func (c *Calculator) Save(w io.Writer) error {
+	var skipped []error
+	names := c.env.Names()
+	sort.SliceStable(names, func(i, j int) bool {
+		return c.saveOrder(names[i]) < c.saveOrder(names[j])
+	})
+
+	for _, name := range names {
+		if isResultRef(name) {
+			continue
+		}
+
+		obj, _ := c.env.Get(name)
+		if obj.Type() == object.NULL_OBJ {
+			continue
+		}
+		if module, ok := obj.(*object.Module); ok && module.Name == name {
+			if _, err := fmt.Fprintf(w, "import %s\n", ast.Quote(module.File)); err != nil {
+				return err
+			}
+			continue
+		}
+
+		src, err := source(obj, c.env, map[*object.Environment]bool{})
+		if err != nil {
+			skipped = append(skipped, fmt.Errorf("cannot save %q: %w", name, err))
+			continue
+		}
+
+		if _, err := fmt.Fprintf(w, "%s%s = %s\n", constPrefix(c.env, name), name, src); err != nil {
+			return err
+		}
+	}
+
+	if len(skipped) > 0 {
+		return &SkippedError{Errors: skipped}
+	}
+	return nil
+}
+
+// SkippedError reports the bindings which Save could not write, the other
+// bindings are saved.
+type SkippedError struct {
+	Errors []error
+}
+
+func (e *SkippedError) Error() string {
+	messages := make([]string, len(e.Errors))
+	for i, err := range e.Errors {
+		messages[i] = err.Error()
+	}
+	return strings.Join(messages, "\n")
+}
+
+// Load evaluates every line written by Save into the calculator environment.
+func (c *Calculator) Load(r io.Reader) error {
+	scanner := bufio.NewScanner(r)
+	lineNumber := 0
+
+	for scanner.Scan() {
+		lineNumber++
+		line := strings.TrimSpace(scanner.Text())
+		if line == "" {
+			continue
+		}
+
+		program, errMessages := parser.New(lexer.New(line)).ParseProgram()
+		if len(errMessages) > 0 {
+			return fmt.Errorf("line %d: %s", lineNumber, strings.Join(errMessages, ", "))
+		}
+
+		evaluated := evaluator.Eval(program, c.env)
+		if evaluator.IsError(evaluated) {
+			return fmt.Errorf("line %d: %s", lineNumber, evaluated.Inspect())
+		}
+	}
+
+	return scanner.Err()
+}
+
+// saveOrder returns the rank of the binding in the saved session, the modules
+// first, then the constants, then the other bindings.
+func (c *Calculator) saveOrder(name string) int {
+	if obj, _ := c.env.Get(name); obj != nil && obj.Type() == object.MODULE_OBJ {
+		return 0
+	}
+	if c.env.IsConst(name) {
+		return 1
+	}
+	return 2
+}
+
+// constPrefix returns the `const ` keyword of the constant bindings.
+func constPrefix(env *object.Environment, name string) string {
+	if env.IsConst(name) {
+		return "const "
+	}
+	return ""
+}
+
+func isResultRef(name string) bool {
+	return name == "ans" || strings.HasPrefix(name, "$")
+}
+
+// source returns the qcal source of obj, see closureSource for the functions
+// of an enclosed environment.
+func source(
+	obj object.Object,
+	top *object.Environment,
+	visiting map[*object.Environment]bool,
+) (string, error) {
+	switch obj := obj.(type) {
+	case *object.Number:
+		if obj.Exact != nil {
+			return obj.Exact.String(), nil
+		}
+		return numberSource(obj.Value), nil
+
+	case *object.Estimate:
+		return numberSource(obj.Value), nil
+
+	case *object.Boolean:
+		return obj.Inspect(), nil
+
+	case *object.String:
+		return ast.Quote(obj.Value), nil
+
+	case *object.NormalFunction:
+		literal := ast.ParametersString(obj.Parameters) + " => {}"
+		if body := obj.Body.String(); body != "" {
+			literal = ast.ParametersString(obj.Parameters) + " => { " + body + " }"
+		}
+		return closureSource(literal, obj.Env, top, visiting)
+
+	case *object.ConciseFunction:
+		literal := ast.ParametersString(obj.Parameters) + " => " + obj.Body.String()
+		return closureSource(literal, obj.Env, top, visiting)
+
+	case *object.MemoFunction:
+		fn, err := source(obj.Function, top, visiting)
+		if err != nil {
+			return "", err
+		}
+		return "memo(" + fn + ")", nil
+
+	case *object.PartialFunction:
+		fn, err := source(obj.Function, top, visiting)
+		if err != nil {
+			return "", err
+		}
+
+		args := make([]string, len(obj.Arguments))
+		named := false
+		for i, arg := range obj.Arguments {
+			prefix := ""
+			if namedArg, ok := arg.(*object.NamedArgument); ok {
+				prefix, arg, named = namedArg.Name+" = ", namedArg.Value, true
+			}
+			s, err := source(arg, top, visiting)
+			if err != nil {
+				return "", err
+			}
+			args[i] = prefix + s
+		}
+		// partial takes positional arguments only, a user function called
+		// with named arguments and without all of them is partial again.
+		if named {
+			return "(" + fn + ")(" + strings.Join(args, ", ") + ")", nil
+		}
+		return "partial(" + strings.Join(append([]string{fn}, args...), ", ") + ")", nil
+
+	case *object.ComposedFunction:
+		outer, err := source(obj.Outer, top, visiting)
+		if err != nil {
+			return "", err
+		}
+		inner, err := source(obj.Inner, top, visiting)
+		if err != nil {
+			return "", err
+		}
+		return "compose(" + outer + ", " + inner + ")", nil
+
+	case *object.List:
+		elements := make([]string, len(obj.Elements))
+		for i, element := range obj.Elements {
+			s, err := source(element, top, visiting)
+			if err != nil {
+				return "", err
+			}
+			elements[i] = s
+		}
+		return "[" + strings.Join(elements, ", ") + "]", nil
+
+	case *object.Polynomial:
+		coeffs := make([]string, len(obj.Coeffs))
+		for i, c := range obj.Coeffs {
+			coeffs[len(coeffs)-1-i] = numberSource(c)
+		}
+		return "poly([" + strings.Join(coeffs, ", ") + "])", nil
+
+	case *object.Matrix:
+		rows := make([]string, len(obj.Rows))
+		for i, row := range obj.Rows {
+			values := make([]string, len(row))
+			for j, v := range row {
+				values[j] = numberSource(v)
+			}
+			rows[i] = "[" + strings.Join(values, ", ") + "]"
+		}
+		return "[" + strings.Join(rows, ", ") + "]", nil
+
+	case *object.Complex:
+		return "complex(" + numberSource(real(obj.Value)) + ", " + numberSource(imag(obj.Value)) + ")", nil
+
+	case *object.Equation:
+		if obj.Env != top {
+			return "", errors.New("cannot save an equation of an enclosed environment")
+		}
+		return obj.Inspect(), nil
+
+	default:
+		return "", errors.New("unsupported type " + string(obj.Type()))
+	}
+}
+
+// closureSource returns the source of the function literal evaluated in its
+// captured bindings, e.g. `(() => { let n = 2; () => { n += 1; n } })()`. The
+// bindings are declared once by the call which builds the function, so the
+// function still shares them between its calls.
+func closureSource(
+	literal string,
+	env *object.Environment,
+	top *object.Environment,
+	visiting map[*object.Environment]bool,
+) (string, error) {
+	captured, err := capturedStatements(env, top, visiting)
+	if err != nil {
+		return "", err
+	}
+	if len(captured) == 0 {
+		return literal, nil
+	}
+	return "(() => { " + strings.Join(append(captured, literal), "; ") + " })()", nil
+}
+
+func capturedStatements(
+	env *object.Environment,
+	top *object.Environment,
+	visiting map[*object.Environment]bool,
+) ([]string, error) {
+	var statements []string
+	seen := map[string]bool{}
+
+	for ; env != nil && env != top; env = env.Outer() {
+		if visiting[env] {
+			continue
+		}
+		visiting[env] = true
+
+		for _, name := range env.Names() {
+			if seen[name] {
+				continue // shadowed by an inner binding
+			}
+			seen[name] = true
+
+			obj, _ := env.Get(name)
+			if obj.Type() == object.NULL_OBJ {
+				continue
+			}
+			src, err := source(obj, top, visiting)
+			if err != nil {
+				return nil, fmt.Errorf("captured %q: %w", name, err)
+			}
+			keyword := "let "
+			if env.IsConst(name) {
+				keyword = "const "
+			}
+			statements = append(statements, keyword+name+" = "+src)
+		}
+
+		delete(visiting, env)
+	}
+
+	return statements, nil
+}
+
+// numberSource formats the number so that it parses back to the same value.
+// The infinities and NaN have no literal, so they are written as e ^ 1000,
+// which overflows to +Inf.
+func numberSource(value float64) string {
+	switch {
+	case math.IsNaN(value):
+		return "0 * e ^ 1000"
+	case math.IsInf(value, 1):
+		return "e ^ 1000"
+	case math.IsInf(value, -1):
+		return "-e ^ 1000"
+	}
+	return strconv.FormatFloat(value, 'f', -1, 64)
+}
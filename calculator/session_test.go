@@ -0,0 +1,178 @@
+package calculator
+
+import (
+	"bytes"
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/DeepAung/qcal/internal/ast"
+)
+
+func TestSaveLoad(t *testing.T) {
+	inputs := []string{
+		"x = 5",
+		"const grav = 9.81",
+		"weight = memo(m => m * grav)",
+		"b = true",
+		"bounds = [-e ^ 1000, e ^ 1000, 0 * e ^ 1000]",
+		"f = x => x ^ 2",
+		"adder = a => b => a + b",
+		"addtwo = adder(2)",
+		"g = (a) => { y = a * 2; if y > 3 { y } else { 0 } }",
+		"p = poly([3, -2, 1])",
+		"z = complex(1, -2)",
+		"l = [1, [true, p]]",
+		"m = [[1, 2], [3, 4.5]]",
+		"n = 25!",
+		"h = (x, base = 10, ...rest) => x + base",
+		"k = ((x, y, z) => x * y + z)(2, z = 1)",
+		"q = partial(h, 1)",
+		"fg = f ∘ (x => x + 1)",
+		"make = () => { count = 0; () => { count += 1; count } }",
+		"counter = make()",
+		"counter()",
+		"counter()",
+		"fib = memo(n => if n < 2 { n } else { fib(n - 1) + fib(n - 2) })",
+		"1 + 1",
+	}
+	expectSaved := `const grav = 9.81
+adder = (a) => (b) => (a + b)
+addtwo = (() => { let a = 2; (b) => (a + b) })()
+b = true
+bounds = [-e ^ 1000, e ^ 1000, 0 * e ^ 1000]
+counter = (() => { let count = 2; () => { count = (count + 1); count } })()
+f = (x) => (x ^ 2)
+fg = compose((x) => (x ^ 2), (x) => (x + 1))
+fib = memo((n) => if (n < 2) { n } else { (fib((n - 1)) + fib((n - 2))) })
+g = (a) => { y = (a * 2); if (y > 3) { y } else { 0 } }
+h = (x, base = 10, ...rest) => (x + base)
+k = ((x, y, z) => ((x * y) + z))(2, z = 1)
+l = [1, [true, poly([3, -2, 1])]]
+m = [[1, 2], [3, 4.5]]
+make = () => { count = 0; () => { count = (count + 1); count } }
+n = 15511210043330985984000000
+p = poly([3, -2, 1])
+q = partial((x, base = 10, ...rest) => (x + base), 1)
+weight = memo((m) => (m * grav))
+x = 5
+z = complex(1, -2)
+`
+
+	c := NewCalculator()
+	for _, input := range inputs {
+		if _, err := c.Calculate(input); err != nil {
+			t.Fatalf("Calculate(%q) failed: %v", input, err)
+		}
+	}
+
+	var buf bytes.Buffer
+	if err := c.Save(&buf); err != nil {
+		t.Fatalf("Save failed: %v", err)
+	}
+	if buf.String() != expectSaved {
+		t.Fatalf("invalid saved session, expect=\n%s\ngot=\n%s", expectSaved, buf.String())
+	}
+
+	loaded := NewCalculator()
+	if err := loaded.Load(&buf); err != nil {
+		t.Fatalf("Load failed: %v", err)
+	}
+
+	tests := []struct {
+		input  string
+		expect string
+	}{
+		{"x", "5"},
+		{"b", "true"},
+		{"bounds[1] > 10 ^ 300", "true"},
+		{"f(3)", "9"},
+		{"addtwo(3)", "5"},
+		{"a = 100", "100"},
+		{"addtwo(3)", "5"},
+		{"a", "100"},
+		{"g(5)", "10"},
+		{"n + 1", "15511210043330985984000001"},
+		{"fib(80)", "23416728348467685"},
+		{"h(1, base = 2)", "3"},
+		{"k(3)", "7"},
+		{"q()", "11"},
+		{"fg(2)", "9"},
+		{"weight(2)", "19.62"},
+		{"counter()", "3"},
+		{"counter()", "4"},
+		{"make()()", "1"},
+	}
+	for _, tt := range tests {
+		result, err := loaded.Calculate(tt.input)
+		if err != nil {
+			t.Fatalf("Calculate(%q) failed: %v", tt.input, err)
+		}
+		if result.Inspect() != tt.expect {
+			t.Fatalf("invalid result of %q, expect=%s, got=%s", tt.input, tt.expect, result.Inspect())
+		}
+	}
+
+	if _, err := loaded.Calculate("grav = 10"); err == nil {
+		t.Fatalf("expect an error of the assignment of the loaded constant grav")
+	}
+}
+
+func TestSaveSkipped(t *testing.T) {
+	c := NewCalculator()
+	for _, input := range []string{"x = 5", `err = try { raise("x") } catch q { q }`} {
+		if _, err := c.Calculate(input); err != nil {
+			t.Fatalf("Calculate(%q) failed: %v", input, err)
+		}
+	}
+
+	var buf bytes.Buffer
+	err := c.Save(&buf)
+	var skipped *SkippedError
+	if !errors.As(err, &skipped) {
+		t.Fatalf("expect a *SkippedError, got=%v", err)
+	}
+	expectErr := `cannot save "err": unsupported type ERROR_VALUE`
+	if skipped.Error() != expectErr {
+		t.Fatalf("invalid error, expect=%q, got=%q", expectErr, skipped.Error())
+	}
+	if buf.String() != "x = 5\n" {
+		t.Fatalf("invalid saved session, expect=%q, got=%q", "x = 5\n", buf.String())
+	}
+}
+
+func TestSaveLoadImport(t *testing.T) {
+	file := filepath.Join(t.TempDir(), "lib.qcal")
+	if err := os.WriteFile(file, []byte("// the helpers\nconst square = x => x ^ 2\n"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+
+	c := NewCalculator()
+	for _, input := range []string{"const k = 3", "import " + ast.Quote(file), "f = memo(x => lib.square(x) + k)"} {
+		if _, err := c.Calculate(input); err != nil {
+			t.Fatalf("Calculate(%q) failed: %v", input, err)
+		}
+	}
+
+	var buf bytes.Buffer
+	if err := c.Save(&buf); err != nil {
+		t.Fatalf("Save failed: %v", err)
+	}
+	expectSaved := "import " + ast.Quote(file) + "\nconst k = 3\nf = memo((x) => ((lib . square)(x) + k))\n"
+	if buf.String() != expectSaved {
+		t.Fatalf("invalid saved session, expect=\n%s\ngot=\n%s", expectSaved, buf.String())
+	}
+
+	loaded := NewCalculator()
+	if err := loaded.Load(&buf); err != nil {
+		t.Fatalf("Load failed: %v", err)
+	}
+	result, err := loaded.Calculate("f(2)")
+	if err != nil {
+		t.Fatalf("Calculate(%q) failed: %v", "f(2)", err)
+	}
+	if result.Inspect() != "7" {
+		t.Fatalf("invalid result of f(2), expect=7, got=%s", result.Inspect())
+	}
+}
@@ -0,0 +1,220 @@
+package main
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+
+	"github.com/DeepAung/qcal/calculator"
+	"github.com/DeepAung/qcal/internal/evaluator"
+	"github.com/DeepAung/qcal/internal/object"
+)
+
+var commandHelp = []string{
+	":vars          list the variables and functions",
+	":funcs         list the builtin functions",
+	":del <name>    remove a variable or function",
+	":clear         clear the history",
+	":reset         remove every variable and function",
+	":help [fn]     show this help or the documentation of a builtin function",
+	":type <expr>   show the type of the expression result",
+	":set [opt val] show or change the number format",
+	"                 precision <n|off>, sigfigs <n|off>, notation <auto|fixed|sci|eng>",
+	"                 grouping <on|off>, decimal <.|,>, trim <on|off>",
+	":save <name>   save the variables and functions to a file",
+	":load <name>   load the variables and functions from a file",
+}
+
+// runCommand runs a colon command, e.g. `:save mysession`, and returns its output.
+// The `:clear` command is handled by Update because it removes the history entry
+// of the command itself.
+func (m *model) runCommand(input string) (string, error) {
+	name, arg, _ := strings.Cut(strings.TrimSpace(strings.TrimPrefix(input, ":")), " ")
+	arg = strings.TrimSpace(arg)
+
+	switch name {
+	case "vars":
+		return strings.Join(m.calculator.Vars(), "\n"), nil
+	case "funcs":
+		var signatures []string
+		for _, fn := range evaluator.BuiltinNames() {
+			signature, _ := evaluator.BuiltinSignature(fn)
+			signatures = append(signatures, signature)
+		}
+		return strings.Join(signatures, "\n"), nil
+	case "del":
+		if arg == "" {
+			return "", errors.New("ERROR: usage: :del <name>")
+		}
+		if !m.calculator.Delete(arg) {
+			return "", fmt.Errorf("ERROR: %q is not defined", arg)
+		}
+		return "deleted " + arg, nil
+	case "reset":
+		m.calculator.Reset()
+		return "reset the environment", nil
+	case "help":
+		if arg == "" {
+			return strings.Join(commandHelp, "\n"), nil
+		}
+		signature, ok := evaluator.BuiltinSignature(arg)
+		if !ok {
+			return "", fmt.Errorf("ERROR: %q is not a builtin function", arg)
+		}
+		doc, _ := evaluator.BuiltinDoc(arg)
+		return signature + "\n  " + doc, nil
+	case "type":
+		if arg == "" {
+			return "", errors.New("ERROR: usage: :type <expr>")
+		}
+		objectType, err := m.calculator.Type(arg)
+		if err != nil {
+			return "", err
+		}
+		return string(objectType), nil
+	case "set":
+		if arg == "" {
+			return formatSettings(m.calculator.NumberFormat()), nil
+		}
+		option, value, _ := strings.Cut(arg, " ")
+		format, err := setFormatOption(m.calculator.NumberFormat(), option, strings.TrimSpace(value))
+		if err != nil {
+			return "", err
+		}
+		m.calculator.SetNumberFormat(format)
+		return formatSettings(format), nil
+	case "save":
+		if arg == "" {
+			return "", errors.New("ERROR: usage: :save <name>")
+		}
+		return m.save(sessionPath(arg))
+	case "load":
+		if arg == "" {
+			return "", errors.New("ERROR: usage: :load <name>")
+		}
+		return m.load(sessionPath(arg))
+	default:
+		return "", errors.New("ERROR: unknown command :" + name + " (see :help)")
+	}
+}
+
+func (m *model) save(path string) (string, error) {
+	f, err := os.Create(path)
+	if err != nil {
+		return "", errors.New("ERROR: " + err.Error())
+	}
+	defer f.Close()
+
+	var skipped *calculator.SkippedError
+	if err := m.calculator.Save(f); errors.As(err, &skipped) {
+		return "saved to " + path + ", skipped:\n" + skipped.Error(), nil
+	} else if err != nil {
+		return "", errors.New("ERROR: " + err.Error())
+	}
+	return "saved to " + path, nil
+}
+
+func (m *model) load(path string) (string, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return "", errors.New("ERROR: " + err.Error())
+	}
+	defer f.Close()
+
+	if err := m.calculator.Load(f); err != nil {
+		return "", errors.New("ERROR: " + err.Error())
+	}
+	return "loaded from " + path, nil
+}
+
+// sessionPath adds the `.qcal` extension to a session name without one.
+func sessionPath(name string) string {
+	if filepath.Ext(name) == "" {
+		return name + ".qcal"
+	}
+	return name
+}
+
+var notations = map[string]object.Notation{
+	"auto":        object.NOTATION_AUTO,
+	"fixed":       object.NOTATION_FIXED,
+	"sci":         object.NOTATION_SCIENTIFIC,
+	"scientific":  object.NOTATION_SCIENTIFIC,
+	"eng":         object.NOTATION_ENGINEERING,
+	"engineering": object.NOTATION_ENGINEERING,
+}
+
+func setFormatOption(format object.NumberFormat, option, value string) (object.NumberFormat, error) {
+	switch option {
+	case "precision", "sigfigs":
+		n := 0
+		if value != "off" {
+			var err error
+			n, err = strconv.Atoi(value)
+			if err != nil || n < 0 || (option == "sigfigs" && n == 0) {
+				return format, fmt.Errorf("ERROR: invalid %s %q", option, value)
+			}
+		}
+
+		// precision and significant figures cannot be used together
+		format.Precision, format.SignificantDigits = -1, 0
+		if value != "off" && option == "precision" {
+			format.Precision = n
+		} else if value != "off" {
+			format.SignificantDigits = n
+		}
+	case "notation":
+		notation, ok := notations[value]
+		if !ok {
+			return format, fmt.Errorf("ERROR: invalid notation %q", value)
+		}
+		format.Notation = notation
+	case "grouping", "trim":
+		if value != "on" && value != "off" {
+			return format, fmt.Errorf("ERROR: invalid %s %q, expect on or off", option, value)
+		}
+		if option == "grouping" {
+			format.GroupDigits = value == "on"
+		} else {
+			format.TrimZeros = value == "on"
+		}
+	case "decimal":
+		if value != "." && value != "," {
+			return format, fmt.Errorf("ERROR: invalid decimal separator %q", value)
+		}
+		format.DecimalSeparator = value
+	default:
+		return format, fmt.Errorf("ERROR: unknown option %q (see :help)", option)
+	}
+
+	return format, nil
+}
+
+func formatSettings(format object.NumberFormat) string {
+	onOff := func(b bool) string {
+		if b {
+			return "on"
+		}
+		return "off"
+	}
+
+	precision, sigfigs := "off", "off"
+	if format.Precision >= 0 {
+		precision = strconv.Itoa(format.Precision)
+	}
+	if format.SignificantDigits > 0 {
+		sigfigs = strconv.Itoa(format.SignificantDigits)
+	}
+
+	return strings.Join([]string{
+		"precision " + precision,
+		"sigfigs " + sigfigs,
+		"notation " + string(format.Notation),
+		"grouping " + onOff(format.GroupDigits),
+		"decimal " + format.DecimalSeparator,
+		"trim " + onOff(format.TrimZeros),
+	}, "\n")
+}
@@ -4,12 +4,15 @@ package main
 // component library.
 
 import (
+	"flag"
 	"fmt"
 	"log"
 	"strings"
 
 	"github.com/DeepAung/qcal/calculator"
+	"github.com/atotto/clipboard"
 	"github.com/charmbracelet/bubbles/textinput"
+	"github.com/charmbracelet/bubbles/viewport"
 	tea "github.com/charmbracelet/bubbletea"
 	"github.com/charmbracelet/lipgloss"
 )
@@ -22,6 +25,19 @@ const (
 )
 
 func main() {
+	plotExpr := flag.String("plot", "", `write the plot of the functions, e.g. "x => x^2, sin", to -out and exit`)
+	xmin := flag.Float64("xmin", -10, "the start of the plot x range")
+	xmax := flag.Float64("xmax", 10, "the end of the plot x range")
+	out := flag.String("out", "plot.svg", "the .svg or .png file of the plot")
+	flag.Parse()
+
+	if *plotExpr != "" {
+		if err := calculator.NewCalculator().PlotFile(*out, *plotExpr, *xmin, *xmax); err != nil {
+			log.Fatal(err)
+		}
+		return
+	}
+
 	p := tea.NewProgram(initialModel())
 	if _, err := p.Run(); err != nil {
 		log.Fatal(err)
@@ -32,11 +48,17 @@ type (
 	errMsg error
 )
 
+const headerHeight = 1 // the welcome line
+
 type model struct {
 	textInput  textinput.Model
+	viewport   viewport.Model
 	calculator *calculator.Calculator
 	history    []history
 	historyIdx int
+	selected   int // the selected history entry, -1 if there is none
+	maxHeight  int // the maximum height of the history viewport
+	status     string
 	err        error
 }
 
@@ -44,6 +66,7 @@ type history struct {
 	input   string
 	output  string
 	isError bool
+	ref     int // the `$n` reference of the result, 0 if there is none
 }
 
 func initialModel() model {
@@ -53,9 +76,12 @@ func initialModel() model {
 
 	return model{
 		textInput:  ti,
+		viewport:   viewport.New(80, 0),
 		calculator: calculator.NewCalculator(),
 		history:    []history{},
 		historyIdx: -1,
+		selected:   -1,
+		maxHeight:  20,
 		err:        nil,
 	}
 }
@@ -68,12 +94,29 @@ func (m model) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 	var cmd tea.Cmd
 
 	switch msg := msg.(type) {
+	case tea.WindowSizeMsg:
+		m.viewport.Width = msg.Width
+		m.maxHeight = max(1, msg.Height-headerHeight-2) // the input and status lines
+		m.refreshViewport()
+		m.viewport.GotoBottom()
+		return m, nil
+
 	case tea.KeyMsg:
+		m.status = ""
+
 		switch msg.Type {
 
-		case tea.KeyCtrlC, tea.KeyCtrlD, tea.KeyEsc:
+		case tea.KeyCtrlC, tea.KeyCtrlD:
 			return m, tea.Quit
 
+		case tea.KeyEsc:
+			if m.selected == -1 {
+				return m, tea.Quit
+			}
+			m.selected = -1
+			m.refreshViewport()
+			return m, nil
+
 		case tea.KeyUp:
 			m.historyIdx = max(0, m.historyIdx-1)
 			if m.historyIdx == len(m.history) {
@@ -89,26 +132,60 @@ func (m model) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 				m.textInput.SetValue(m.history[m.historyIdx].input)
 			}
 
-		case tea.KeyEnter:
-			input := m.textInput.Value()
-			var output string
-			var isError bool
-
-			result, err := m.calculator.Calculate(input)
-
-			if err != nil {
-				output = err.Error()
-				isError = true
-			} else if result == nil {
-				output = ""
-				isError = false
+		case tea.KeyPgUp:
+			m.viewport.ViewUp()
+			return m, nil
+		case tea.KeyPgDown:
+			m.viewport.ViewDown()
+			return m, nil
+
+		case tea.KeyShiftUp:
+			if len(m.history) == 0 {
+				return m, nil
+			}
+			if m.selected == -1 {
+				m.selected = len(m.history) - 1
 			} else {
-				output = result.Inspect()
-				isError = false
+				m.selected = max(0, m.selected-1)
+			}
+			m.refreshViewport()
+			m.scrollToSelected()
+			return m, nil
+		case tea.KeyShiftDown:
+			if m.selected == -1 {
+				return m, nil
+			}
+			m.selected++
+			if m.selected == len(m.history) {
+				m.selected = -1
 			}
+			m.refreshViewport()
+			m.scrollToSelected()
+			return m, nil
 
-			m.history = append(m.history, history{input: input, output: output, isError: isError})
-			m.historyIdx = len(m.history)
+		case tea.KeyCtrlY:
+			m.copyOutput()
+			return m, nil
+
+		case tea.KeyCtrlR:
+			if m.selected == -1 {
+				return m, nil
+			}
+			input := m.history[m.selected].input
+			m.selected = -1
+			m.submit(input)
+			return m, nil
+
+		case tea.KeyEnter:
+			if m.selected != -1 {
+				m.textInput.SetValue(m.history[m.selected].input)
+				m.textInput.CursorEnd()
+				m.selected = -1
+				m.refreshViewport()
+				return m, nil
+			}
+
+			m.submit(m.textInput.Value())
 			m.textInput.Reset()
 			return m, cmd
 		}
@@ -122,14 +199,110 @@ func (m model) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 	return m, cmd
 }
 
-func (m model) View() string {
-	if m.err != nil {
-		return fmt.Sprintf("ERROR: %v\n", m.err)
+// submit evaluates the input, or runs it as a colon command, and appends the
+// result to the history.
+func (m *model) submit(input string) {
+	if strings.TrimSpace(input) == ":clear" {
+		m.history = []history{}
+		m.historyIdx = 0
+		m.refreshViewport()
+		return
+	}
+
+	var output string
+	var isError bool
+	var ref int
+
+	if strings.HasPrefix(input, ":") {
+		commandOutput, err := m.runCommand(input)
+		if err != nil {
+			output = err.Error()
+			isError = true
+		} else {
+			output = commandOutput
+			isError = false
+		}
+	} else {
+		result, err := m.calculator.Calculate(input)
+
+		if err != nil {
+			output = err.Error()
+			isError = true
+		} else if result == nil {
+			output = ""
+			isError = false
+		} else {
+			output = m.calculator.Inspect(result)
+			isError = false
+			ref = m.calculator.ResultCount()
+		}
 	}
 
+	m.history = append(
+		m.history,
+		history{input: input, output: output, isError: isError, ref: ref},
+	)
+	m.historyIdx = len(m.history)
+	m.refreshViewport()
+	m.viewport.GotoBottom()
+}
+
+// copyOutput copies the output of the selected history entry, or of the last
+// entry if there is no selection, to the clipboard.
+func (m *model) copyOutput() {
+	idx := m.selected
+	if idx == -1 {
+		idx = len(m.history) - 1
+	}
+	if idx < 0 || m.history[idx].output == "" {
+		m.status = "nothing to copy"
+		return
+	}
+
+	if err := clipboard.WriteAll(m.history[idx].output); err != nil {
+		m.status = "ERROR: " + err.Error()
+		return
+	}
+	m.status = "copied to clipboard"
+}
+
+// refreshViewport renders the history into the viewport, which grows with its
+// content up to maxHeight.
+func (m *model) refreshViewport() {
+	content, _ := m.renderHistory()
+	m.viewport.Height = min(lipgloss.Height(content), m.maxHeight)
+	if content == "" {
+		m.viewport.Height = 0
+	}
+	m.viewport.SetContent(content)
+}
+
+func (m *model) scrollToSelected() {
+	if m.selected == -1 {
+		m.viewport.GotoBottom()
+		return
+	}
+
+	_, offsets := m.renderHistory()
+	top := offsets[m.selected]
+	if top < m.viewport.YOffset || top >= m.viewport.YOffset+m.viewport.Height {
+		m.viewport.SetYOffset(top)
+	}
+}
+
+// renderHistory returns the rendered history and the first line of each entry.
+func (m model) renderHistory() (string, []int) {
 	var historyStr []string
-	for _, h := range m.history {
-		str := ">> " + h.input
+	offsets := make([]int, len(m.history))
+	line := 0
+
+	for i, h := range m.history {
+		prompt := ">> "
+		if i == m.selected {
+			prompt = setColor("> ", hotPink) + " "
+		}
+
+		str := prompt + h.input
 		if h.output != "" {
 			var coloredOutput string
 			if h.isError {
@@ -137,20 +310,42 @@ func (m model) View() string {
 			} else {
 				coloredOutput = setColor(h.output, darkGray)
 			}
+			if h.ref > 0 {
+				coloredOutput = setColor(fmt.Sprintf("$%d = ", h.ref), darkPink) + coloredOutput
+			}
 			str += "\n" + coloredOutput
 		}
 
+		offsets[i] = line
+		line += lipgloss.Height(str)
 		historyStr = append(historyStr, str)
 	}
 
-	historyRender := strings.Join(historyStr, "\n")
+	return strings.Join(historyStr, "\n"), offsets
+}
+
+func (m model) View() string {
+	if m.err != nil {
+		return fmt.Sprintf("ERROR: %v\n", m.err)
+	}
+
+	historyRender := m.viewport.View()
 	if historyRender != "" {
 		historyRender += "\n"
 	}
 
+	status := setColor(
+		"pgup/pgdn scroll • shift+↑/↓ select • enter edit • ctrl+r rerun • ctrl+y copy",
+		darkGray,
+	)
+	if m.status != "" {
+		status = setColor(m.status, darkPink)
+	}
+
 	return "Welcome to qcal. Enter math expression. (esc to quit)\n" +
 		historyRender +
-		m.textInput.View()
+		m.textInput.View() + "\n" +
+		status
 }
 
 func setColor(str string, color lipgloss.TerminalColor) string {
@@ -49,11 +49,16 @@ func (p *Program) String() string {
 	return sb.String()
 }
 
-// LetStatement `<identifier | Name> = <expression | Value>`
+// LetStatement `<identifier | Name> = <expression | Value>`, which assigns the
+// nearest binding of the name, or the declaration `let <identifier | Name> =
+// <expression | Value>` and `const <identifier | Name> = <expression | Value>`
+// of the current scope. A compound assignment `x += 1` is `x = x + 1`.
 type LetStatement struct {
-	Token token.Token // The identifier token
-	Name  *Identifier
-	Value Expression
+	Token    token.Token // The identifier token, or the `let` or `const` token
+	Name     *Identifier
+	Value    Expression
+	Declare  bool
+	Constant bool
 }
 
 func (ls *LetStatement) statementNode()       {}
@@ -61,6 +66,11 @@ func (ls *LetStatement) TokenLiteral() string { return ls.Token.Literal }
 func (ls *LetStatement) String() string {
 	var sb strings.Builder
 
+	if ls.Constant {
+		sb.WriteString("const ")
+	} else if ls.Declare {
+		sb.WriteString("let ")
+	}
 	sb.WriteString(ls.Name.String())
 	sb.WriteString(" = ")
 
@@ -94,6 +104,36 @@ func (rs *ReturnStatement) String() string {
 	return sb.String()
 }
 
+// BreakStatement `break`
+type BreakStatement struct {
+	Token token.Token // the `break` token
+}
+
+func (bs *BreakStatement) statementNode()       {}
+func (bs *BreakStatement) TokenLiteral() string { return bs.Token.Literal }
+func (bs *BreakStatement) String() string       { return bs.TokenLiteral() + ";" }
+
+// ContinueStatement `continue`
+type ContinueStatement struct {
+	Token token.Token // the `continue` token
+}
+
+func (cs *ContinueStatement) statementNode()       {}
+func (cs *ContinueStatement) TokenLiteral() string { return cs.Token.Literal }
+func (cs *ContinueStatement) String() string       { return cs.TokenLiteral() + ";" }
+
+// ImportStatement `import "<path>"`, the module is bound to the name of the
+// file without its extension, e.g. `lib` of "lib.qcal".
+type ImportStatement struct {
+	Token token.Token // the `import` token
+	Path  string
+	Name  *Identifier
+}
+
+func (is *ImportStatement) statementNode()       {}
+func (is *ImportStatement) TokenLiteral() string { return is.Token.Literal }
+func (is *ImportStatement) String() string       { return is.TokenLiteral() + " " + Quote(is.Path) + ";" }
+
 // ExpressionStatement
 type ExpressionStatement struct {
 	Token      token.Token
@@ -121,13 +161,30 @@ func (bs *BlockStatement) TokenLiteral() string { return bs.Token.Literal }
 func (bs *BlockStatement) String() string {
 	var sb strings.Builder
 
-	for _, stmt := range bs.Statements {
-		sb.WriteString(stmt.String())
+	for i, stmt := range bs.Statements {
+		str := stmt.String()
+		sb.WriteString(str)
+
+		if i == len(bs.Statements)-1 {
+			break
+		}
+		if !strings.HasSuffix(str, ";") {
+			sb.WriteString(";")
+		}
+		sb.WriteString(" ")
 	}
 
 	return sb.String()
 }
 
+// braced returns the block wrapped in braces, e.g. `{ x = 1; x }`
+func (bs *BlockStatement) braced() string {
+	if len(bs.Statements) == 0 {
+		return "{}"
+	}
+	return "{ " + bs.String() + " }"
+}
+
 // Identifier
 type Identifier struct {
 	Token token.Token
@@ -158,6 +215,40 @@ func (il *NumberLiteral) expressionNode()      {}
 func (il *NumberLiteral) TokenLiteral() string { return il.Token.Literal }
 func (il *NumberLiteral) String() string       { return il.Token.Literal }
 
+// StringLiteral
+type StringLiteral struct {
+	Token token.Token
+	Value string
+}
+
+func (sl *StringLiteral) expressionNode()      {}
+func (sl *StringLiteral) TokenLiteral() string { return sl.Token.Literal }
+func (sl *StringLiteral) String() string       { return Quote(sl.Value) }
+
+// Quote returns the string as a double quoted string literal, e.g. `"a \"b\""`
+func Quote(s string) string {
+	var sb strings.Builder
+
+	sb.WriteString(`"`)
+	for _, ch := range s {
+		switch ch {
+		case '"':
+			sb.WriteString(`\"`)
+		case '\\':
+			sb.WriteString(`\\`)
+		case '\n':
+			sb.WriteString(`\n`)
+		case '\t':
+			sb.WriteString(`\t`)
+		default:
+			sb.WriteRune(ch)
+		}
+	}
+	sb.WriteString(`"`)
+
+	return sb.String()
+}
+
 // PrefixExpression `<prefix | Operator><expression | Right>`
 type PrefixExpression struct {
 	Token    token.Token // The prefix token, e.g. `!` from `!true`
@@ -220,6 +311,20 @@ func (ie *InfixExpression) String() string {
 	return sb.String()
 }
 
+// EquationExpression `<expression | Left> := <expression | Right>`, an
+// equation for the solve builtins
+type EquationExpression struct {
+	Token token.Token // the `:=` token
+	Left  Expression
+	Right Expression
+}
+
+func (ee *EquationExpression) expressionNode()      {}
+func (ee *EquationExpression) TokenLiteral() string { return ee.Token.Literal }
+func (ee *EquationExpression) String() string {
+	return "(" + ee.Left.String() + " := " + ee.Right.String() + ")"
+}
+
 // IfExpression `if (<condition>) { <consequence> } else { <alternative> }`
 type IfExpression struct {
 	Token       token.Token // the `if` token
@@ -233,21 +338,110 @@ func (ie *IfExpression) TokenLiteral() string { return ie.Token.Literal }
 func (ie *IfExpression) String() string {
 	var sb strings.Builder
 
-	sb.WriteString("if")
+	sb.WriteString("if ")
 	sb.WriteString(ie.Condition.String())
 	sb.WriteString(" ")
-	sb.WriteString(ie.Consequence.String())
+	sb.WriteString(ie.Consequence.braced())
 	if ie.Alternative != nil {
+		sb.WriteString(" else ")
+		sb.WriteString(ie.Alternative.braced())
+	}
+
+	return sb.String()
+}
+
+// WhileExpression `while (<condition>) { <body> }`
+type WhileExpression struct {
+	Token     token.Token // the `while` token
+	Condition Expression
+	Body      *BlockStatement
+}
+
+func (we *WhileExpression) expressionNode()      {}
+func (we *WhileExpression) TokenLiteral() string { return we.Token.Literal }
+func (we *WhileExpression) String() string {
+	return "while " + we.Condition.String() + " " + we.Body.braced()
+}
+
+// TryExpression `try { <body> } catch <variable> { <handler> }`, the variable
+// of the caught error is optional.
+type TryExpression struct {
+	Token    token.Token // the `try` token
+	Body     *BlockStatement
+	Variable *Identifier
+	Handler  *BlockStatement
+}
+
+func (te *TryExpression) expressionNode()      {}
+func (te *TryExpression) TokenLiteral() string { return te.Token.Literal }
+func (te *TryExpression) String() string {
+	var sb strings.Builder
+
+	sb.WriteString("try ")
+	sb.WriteString(te.Body.braced())
+	sb.WriteString(" catch ")
+	if te.Variable != nil {
+		sb.WriteString(te.Variable.String())
 		sb.WriteString(" ")
-		sb.WriteString(ie.Alternative.String())
 	}
+	sb.WriteString(te.Handler.braced())
+
+	return sb.String()
+}
+
+// ForExpression `for <variable> in <iterable> { <body> }`
+type ForExpression struct {
+	Token    token.Token // the `for` token
+	Variable *Identifier
+	Iterable Expression
+	Body     *BlockStatement
+}
+
+func (fe *ForExpression) expressionNode()      {}
+func (fe *ForExpression) TokenLiteral() string { return fe.Token.Literal }
+func (fe *ForExpression) String() string {
+	var sb strings.Builder
+
+	sb.WriteString("for ")
+	sb.WriteString(fe.Variable.String())
+	sb.WriteString(" in ")
+	sb.WriteString(fe.Iterable.String())
+	sb.WriteString(" ")
+	sb.WriteString(fe.Body.braced())
 
 	return sb.String()
 }
 
+// Parameter `<name>`, `<name> = <default>` or `...<name>`
+type Parameter struct {
+	Name     *Identifier
+	Default  Expression // nil if the parameter is required
+	Variadic bool       // the list of the remaining positional arguments
+}
+
+func (p *Parameter) String() string {
+	switch {
+	case p.Variadic:
+		return "..." + p.Name.String()
+	case p.Default != nil:
+		return p.Name.String() + " = " + p.Default.String()
+	default:
+		return p.Name.String()
+	}
+}
+
+// ParametersString returns the parameters in parentheses, e.g. `(x, base = 10)`
+func ParametersString(params []*Parameter) string {
+	strs := make([]string, len(params))
+	for i, p := range params {
+		strs[i] = p.String()
+	}
+	return "(" + strings.Join(strs, ", ") + ")"
+}
+
 type NormalFunctionLiteral struct {
 	Token      token.Token // the `=>` token
-	Parameters []*Identifier
+	Parameters []*Parameter
 	Body       *BlockStatement
 }
 
@@ -257,22 +451,16 @@ func (nfl *NormalFunctionLiteral) TokenLiteral() string { return nfl.Token.Liter
 func (nfl *NormalFunctionLiteral) String() string {
 	var sb strings.Builder
 
-	params := []string{}
-	for _, p := range nfl.Parameters {
-		params = append(params, p.String())
-	}
-
-	sb.WriteString("(")
-	sb.WriteString(strings.Join(params, ", "))
-	sb.WriteString(") => ")
-	sb.WriteString(nfl.Body.String())
+	sb.WriteString(ParametersString(nfl.Parameters))
+	sb.WriteString(" => ")
+	sb.WriteString(nfl.Body.braced())
 
 	return sb.String()
 }
 
 type ConciseFunctionLiteral struct {
 	Token      token.Token // the `=>` token
-	Parameters []*Identifier
+	Parameters []*Parameter
 	Body       Expression
 }
 
@@ -282,23 +470,36 @@ func (cfl *ConciseFunctionLiteral) TokenLiteral() string { return cfl.Token.Lite
 func (cfl *ConciseFunctionLiteral) String() string {
 	var sb strings.Builder
 
-	params := []string{}
-	for _, p := range cfl.Parameters {
-		params = append(params, p.String())
-	}
-
-	sb.WriteString("(")
-	sb.WriteString(strings.Join(params, ", "))
-	sb.WriteString(") => ")
+	sb.WriteString(ParametersString(cfl.Parameters))
+	sb.WriteString(" => ")
 	sb.WriteString(cfl.Body.String())
 
 	return sb.String()
 }
 
+// ListLiteral `[<expression>, <expression>, ...]`
+type ListLiteral struct {
+	Token    token.Token // the `[` token
+	Elements []Expression
+}
+
+func (ll *ListLiteral) expressionNode()      {}
+func (ll *ListLiteral) TokenLiteral() string { return ll.Token.Literal }
+func (ll *ListLiteral) String() string {
+	elements := []string{}
+	for _, e := range ll.Elements {
+		elements = append(elements, e.String())
+	}
+
+	return "[" + strings.Join(elements, ", ") + "]"
+}
+
+// CallExpression `<expression | Function>(<arguments>)`, a pipeline
+// `<argument> |> <expression | Function>` is a call of a single argument.
 type CallExpression struct {
-	Token     token.Token // the `(` token
-	Function  Expression  // *Identifier or *CallExpression
-	Arguments []Expression
+	Token     token.Token  // the `(` or `|>` token
+	Function  Expression   // any expression of a function value
+	Arguments []Expression // the positional arguments, then the *NamedArgument
 }
 
 func (ce *CallExpression) expressionNode()      {}
@@ -311,10 +512,45 @@ func (ce *CallExpression) String() string {
 		args = append(args, p.String())
 	}
 
-	sb.WriteString(ce.Function.String())
+	sb.WriteString(operandString(ce.Function))
 	sb.WriteString("(")
 	sb.WriteString(strings.Join(args, ", "))
 	sb.WriteString(")")
 
 	return sb.String()
 }
+
+// IndexExpression `<expression | Left>[<expression | Index>]`
+type IndexExpression struct {
+	Token token.Token // the `[` token
+	Left  Expression
+	Index Expression
+}
+
+func (ie *IndexExpression) expressionNode()      {}
+func (ie *IndexExpression) TokenLiteral() string { return ie.Token.Literal }
+func (ie *IndexExpression) String() string {
+	return operandString(ie.Left) + "[" + ie.Index.String() + "]"
+}
+
+// operandString returns the string of the operand of a call or an index,
+// which is grouped if it would take the call or the index into itself.
+func operandString(exp Expression) string {
+	switch exp.(type) {
+	case *NormalFunctionLiteral, *ConciseFunctionLiteral, *IfExpression, *WhileExpression, *ForExpression,
+		*TryExpression:
+		return "(" + exp.String() + ")"
+	}
+	return exp.String()
+}
+
+// NamedArgument `<name> = <expression | Value>` of a call
+type NamedArgument struct {
+	Token token.Token // the name token
+	Name  *Identifier
+	Value Expression
+}
+
+func (na *NamedArgument) expressionNode()      {}
+func (na *NamedArgument) TokenLiteral() string { return na.Token.Literal }
+func (na *NamedArgument) String() string       { return na.Name.String() + " = " + na.Value.String() }
@@ -0,0 +1,318 @@
+// Package distribution implements the probability distributions, their
+// density, cumulative distribution and quantile functions.
+package distribution
+
+import (
+	"fmt"
+	"math"
+)
+
+type Distribution interface {
+	// PDF returns the probability density at x, the probability mass of
+	// the discrete distributions.
+	PDF(x float64) float64
+	// CDF returns the probability of a value less than or equal to x.
+	CDF(x float64) float64
+	// Quantile returns the smallest x with CDF(x) >= p for p from 0 to 1.
+	Quantile(p float64) float64
+}
+
+// Normal returns the normal distribution of the mean mu and the standard
+// deviation sigma.
+func Normal(mu, sigma float64) (Distribution, error) {
+	if !(sigma > 0) {
+		return nil, fmt.Errorf("sigma should be positive, got %v", sigma)
+	}
+	return normal{mu, sigma}, nil
+}
+
+type normal struct{ mu, sigma float64 }
+
+func (d normal) PDF(x float64) float64 {
+	z := (x - d.mu) / d.sigma
+	return math.Exp(-z*z/2) / (d.sigma * math.Sqrt(2*math.Pi))
+}
+
+func (d normal) CDF(x float64) float64 {
+	return math.Erfc(-(x-d.mu)/(d.sigma*math.Sqrt2)) / 2
+}
+
+func (d normal) Quantile(p float64) float64 {
+	return d.mu + d.sigma*math.Sqrt2*math.Erfinv(2*p-1)
+}
+
+// Uniform returns the continuous uniform distribution from a to b.
+func Uniform(a, b float64) (Distribution, error) {
+	if !(a < b) {
+		return nil, fmt.Errorf("a should be less than b, got %v and %v", a, b)
+	}
+	return uniform{a, b}, nil
+}
+
+type uniform struct{ a, b float64 }
+
+func (d uniform) PDF(x float64) float64 {
+	if x < d.a || x > d.b {
+		return 0
+	}
+	return 1 / (d.b - d.a)
+}
+
+func (d uniform) CDF(x float64) float64 {
+	return math.Min(math.Max((x-d.a)/(d.b-d.a), 0), 1)
+}
+
+func (d uniform) Quantile(p float64) float64 {
+	return d.a + p*(d.b-d.a)
+}
+
+// Exponential returns the exponential distribution of the rate lambda.
+func Exponential(lambda float64) (Distribution, error) {
+	if !(lambda > 0) {
+		return nil, fmt.Errorf("lambda should be positive, got %v", lambda)
+	}
+	return exponential{lambda}, nil
+}
+
+type exponential struct{ lambda float64 }
+
+func (d exponential) PDF(x float64) float64 {
+	if x < 0 {
+		return 0
+	}
+	return d.lambda * math.Exp(-d.lambda*x)
+}
+
+func (d exponential) CDF(x float64) float64 {
+	if x < 0 {
+		return 0
+	}
+	return -math.Expm1(-d.lambda * x)
+}
+
+func (d exponential) Quantile(p float64) float64 {
+	return -math.Log1p(-p) / d.lambda
+}
+
+// Binomial returns the distribution of the number of successes of n trials
+// with the probability p.
+func Binomial(n, p float64) (Distribution, error) {
+	if n < 0 || n != math.Trunc(n) {
+		return nil, fmt.Errorf("n should be a non-negative integer, got %v", n)
+	}
+	if !(p >= 0 && p <= 1) {
+		return nil, fmt.Errorf("p should be from 0 to 1, got %v", p)
+	}
+	return binomial{n, p}, nil
+}
+
+// exactBinomialMax is the largest n of the binomial coefficients computed
+// directly, the larger ones use the logarithm of the gamma function.
+const exactBinomialMax = 1000
+
+type binomial struct{ n, p float64 }
+
+func (d binomial) PDF(x float64) float64 {
+	if x < 0 || x > d.n || x != math.Trunc(x) {
+		return 0
+	}
+	switch d.p {
+	case 0:
+		return boolFloat(x == 0)
+	case 1:
+		return boolFloat(x == d.n)
+	}
+	if d.n <= exactBinomialMax {
+		return choose(d.n, x) * math.Pow(d.p, x) * math.Pow(1-d.p, d.n-x)
+	}
+	return math.Exp(lchoose(d.n, x) + x*math.Log(d.p) + (d.n-x)*math.Log1p(-d.p))
+}
+
+func (d binomial) CDF(x float64) float64 {
+	k := math.Floor(x)
+	switch {
+	case k < 0:
+		return 0
+	case k >= d.n:
+		return 1
+	case d.p == 0:
+		return 1
+	case d.p == 1:
+		return 0
+	case d.n <= exactBinomialMax:
+		sum := 0.0
+		for i := 0.0; i <= k; i++ {
+			sum += d.PDF(i)
+		}
+		return math.Min(sum, 1)
+	}
+	return RegularizedBeta(1-d.p, d.n-k, k+1)
+}
+
+func (d binomial) Quantile(p float64) float64 {
+	return discreteQuantile(d, p, d.n)
+}
+
+// Poisson returns the distribution of the number of events of the rate lambda.
+func Poisson(lambda float64) (Distribution, error) {
+	if !(lambda > 0) {
+		return nil, fmt.Errorf("lambda should be positive, got %v", lambda)
+	}
+	return poisson{lambda}, nil
+}
+
+type poisson struct{ lambda float64 }
+
+func (d poisson) PDF(x float64) float64 {
+	if x < 0 || x != math.Trunc(x) || math.IsInf(x, 1) {
+		return 0
+	}
+	lgamma, _ := math.Lgamma(x + 1)
+	return math.Exp(x*math.Log(d.lambda) - d.lambda - lgamma)
+}
+
+func (d poisson) CDF(x float64) float64 {
+	k := math.Floor(x)
+	if k < 0 {
+		return 0
+	}
+	return 1 - RegularizedGamma(k+1, d.lambda)
+}
+
+func (d poisson) Quantile(p float64) float64 {
+	return discreteQuantile(d, p, math.Inf(1))
+}
+
+// StudentT returns Student's t-distribution of nu degrees of freedom.
+func StudentT(nu float64) (Distribution, error) {
+	if !(nu > 0) {
+		return nil, fmt.Errorf("nu should be positive, got %v", nu)
+	}
+	return studentT{nu}, nil
+}
+
+type studentT struct{ nu float64 }
+
+func (d studentT) PDF(x float64) float64 {
+	a, _ := math.Lgamma((d.nu + 1) / 2)
+	b, _ := math.Lgamma(d.nu / 2)
+	return math.Exp(a-b-(d.nu+1)/2*math.Log1p(x*x/d.nu)) / math.Sqrt(d.nu*math.Pi)
+}
+
+func (d studentT) CDF(x float64) float64 {
+	if math.IsInf(x, 0) {
+		return boolFloat(x > 0)
+	}
+	tail := RegularizedBeta(d.nu/(d.nu+x*x), d.nu/2, 0.5) / 2
+	if x > 0 {
+		return 1 - tail
+	}
+	return tail
+}
+
+func (d studentT) Quantile(p float64) float64 {
+	return continuousQuantile(d, p, math.Inf(-1))
+}
+
+// ChiSquared returns the chi-squared distribution of k degrees of freedom.
+func ChiSquared(k float64) (Distribution, error) {
+	if !(k > 0) {
+		return nil, fmt.Errorf("k should be positive, got %v", k)
+	}
+	return chiSquared{k}, nil
+}
+
+type chiSquared struct{ k float64 }
+
+func (d chiSquared) PDF(x float64) float64 {
+	switch {
+	case x < 0:
+		return 0
+	case x == 0:
+		switch {
+		case d.k < 2:
+			return math.Inf(1)
+		case d.k == 2:
+			return 0.5
+		default:
+			return 0
+		}
+	}
+	lgamma, _ := math.Lgamma(d.k / 2)
+	return math.Exp((d.k/2-1)*math.Log(x) - x/2 - d.k/2*math.Ln2 - lgamma)
+}
+
+func (d chiSquared) CDF(x float64) float64 {
+	if x <= 0 {
+		return 0
+	}
+	return RegularizedGamma(d.k/2, x/2)
+}
+
+func (d chiSquared) Quantile(p float64) float64 {
+	return continuousQuantile(d, p, 0)
+}
+
+// continuousQuantile inverts the CDF by bisection, lower is the lower bound
+// of the support.
+func continuousQuantile(d Distribution, p, lower float64) float64 {
+	switch {
+	case p <= 0:
+		return lower
+	case p >= 1:
+		return math.Inf(1)
+	}
+
+	lo, hi := -1.0, 1.0
+	if lower == 0 {
+		lo = 0
+	}
+	for d.CDF(hi) < p {
+		lo, hi = hi, 2*hi
+	}
+	for lo > lower && d.CDF(lo) > p {
+		lo, hi = 2*lo, lo
+	}
+
+	for i := 0; i < 200 && hi-lo > 1e-15*math.Max(math.Abs(lo), math.Abs(hi)); i++ {
+		mid := lo + (hi-lo)/2
+		if d.CDF(mid) < p {
+			lo = mid
+		} else {
+			hi = mid
+		}
+	}
+	return lo + (hi-lo)/2
+}
+
+// discreteQuantile returns the smallest integer k with CDF(k) >= p, from 0 to
+// upper, by binary search.
+func discreteQuantile(d Distribution, p, upper float64) float64 {
+	if p >= 1 {
+		return upper
+	}
+
+	hi := 1.0
+	for hi < upper && d.CDF(hi) < p {
+		hi *= 2
+	}
+	hi = math.Min(hi, upper)
+
+	lo := 0.0
+	for lo < hi {
+		mid := math.Floor((lo + hi) / 2)
+		if d.CDF(mid) < p {
+			lo = mid + 1
+		} else {
+			hi = mid
+		}
+	}
+	return lo
+}
+
+func boolFloat(b bool) float64 {
+	if b {
+		return 1
+	}
+	return 0
+}
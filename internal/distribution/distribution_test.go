@@ -0,0 +1,68 @@
+package distribution
+
+import (
+	"math"
+	"testing"
+)
+
+func TestDistributions(t *testing.T) {
+	must := func(d Distribution, err error) Distribution {
+		if err != nil {
+			t.Fatal(err)
+		}
+		return d
+	}
+
+	tests := []struct {
+		name   string
+		got    float64
+		expect float64
+	}{
+		{"normal pdf", must(Normal(0, 1)).PDF(0), 0.3989422804014327},
+		{"normal cdf", must(Normal(0, 1)).CDF(1.96), 0.9750021048517795},
+		{"normal quantile", must(Normal(10, 2)).Quantile(0.975), 10 + 2*1.959963984540054},
+		{"uniform pdf", must(Uniform(0, 4)).PDF(3), 0.25},
+		{"uniform cdf", must(Uniform(0, 4)).CDF(5), 1},
+		{"uniform quantile", must(Uniform(0, 4)).Quantile(0.5), 2},
+		{"exponential pdf", must(Exponential(2)).PDF(1), 0.2706705664732254},
+		{"exponential cdf", must(Exponential(2)).CDF(1), 0.8646647167633873},
+		{"exponential quantile", must(Exponential(2)).Quantile(0.5), 0.34657359027997264},
+		{"binomial pdf", must(Binomial(10, 0.5)).PDF(5), 0.24609375},
+		{"binomial pdf of non-integer", must(Binomial(10, 0.5)).PDF(4.5), 0},
+		{"binomial cdf", must(Binomial(10, 0.5)).CDF(5), 0.623046875},
+		{"binomial quantile", must(Binomial(10, 0.5)).Quantile(0.6), 5},
+		{"poisson pdf", must(Poisson(3)).PDF(2), 0.22404180765538775},
+		{"poisson cdf", must(Poisson(3)).CDF(2), 0.42319008112684353},
+		{"poisson quantile", must(Poisson(3)).Quantile(0.5), 3},
+		{"student-t pdf", must(StudentT(5)).PDF(0), 0.3796066898224944},
+		{"student-t cdf", must(StudentT(1)).CDF(1), 0.75},
+		{"student-t lower cdf", must(StudentT(1)).CDF(-1), 0.25},
+		{"student-t quantile", must(StudentT(5)).Quantile(0.975), 2.570581835636314},
+		{"chi-squared pdf", must(ChiSquared(2)).PDF(2), 0.18393972058572117},
+		{"chi-squared cdf", must(ChiSquared(2)).CDF(2), 0.6321205588285577},
+		{"chi-squared quantile", must(ChiSquared(3)).Quantile(0.95), 7.814727903251178},
+	}
+
+	for _, tt := range tests {
+		if math.Abs(tt.got-tt.expect) > 1e-9*math.Max(1, math.Abs(tt.expect)) {
+			t.Errorf("invalid %s, expect=%v, got=%v", tt.name, tt.expect, tt.got)
+		}
+	}
+}
+
+func TestInvalidParameters(t *testing.T) {
+	for i, f := range []func() (Distribution, error){
+		func() (Distribution, error) { return Normal(0, 0) },
+		func() (Distribution, error) { return Uniform(1, 1) },
+		func() (Distribution, error) { return Exponential(-1) },
+		func() (Distribution, error) { return Binomial(2.5, 0.5) },
+		func() (Distribution, error) { return Binomial(10, 1.5) },
+		func() (Distribution, error) { return Poisson(0) },
+		func() (Distribution, error) { return StudentT(math.NaN()) },
+		func() (Distribution, error) { return ChiSquared(-2) },
+	} {
+		if _, err := f(); err == nil {
+			t.Errorf("expect an error of the invalid parameters of test %d", i)
+		}
+	}
+}
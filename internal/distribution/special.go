@@ -0,0 +1,131 @@
+package distribution
+
+import "math"
+
+const (
+	maxIterations = 500
+	epsilon       = 0x1p-52
+	tiny          = 1e-300
+)
+
+// choose returns the binomial coefficient n choose k.
+func choose(n, k float64) float64 {
+	k = math.Min(k, n-k)
+	result := 1.0
+	for i := 1.0; i <= k; i++ {
+		result = result * (n - k + i) / i
+	}
+	return math.Round(result)
+}
+
+// lchoose returns the logarithm of the binomial coefficient n choose k.
+func lchoose(n, k float64) float64 {
+	a, _ := math.Lgamma(n + 1)
+	b, _ := math.Lgamma(k + 1)
+	c, _ := math.Lgamma(n - k + 1)
+	return a - b - c
+}
+
+// RegularizedGamma returns the regularized lower incomplete gamma function
+// P(a, x), using its series for x < a + 1 and its continued fraction
+// otherwise.
+func RegularizedGamma(a, x float64) float64 {
+	switch {
+	case x <= 0:
+		return 0
+	case math.IsInf(x, 1):
+		return 1
+	}
+
+	lgamma, _ := math.Lgamma(a)
+	prefix := math.Exp(a*math.Log(x) - x - lgamma)
+
+	if x < a+1 {
+		term, sum := 1/a, 1/a
+		for n := 1; n < maxIterations; n++ {
+			term *= x / (a + float64(n))
+			sum += term
+			if math.Abs(term) < math.Abs(sum)*epsilon {
+				break
+			}
+		}
+		return math.Min(sum*prefix, 1)
+	}
+
+	// the modified Lentz's method for Q(a, x)
+	b := x + 1 - a
+	c := 1 / tiny
+	d := 1 / b
+	h := d
+	for n := 1; n < maxIterations; n++ {
+		an := -float64(n) * (float64(n) - a)
+		b += 2
+		d = an*d + b
+		if math.Abs(d) < tiny {
+			d = tiny
+		}
+		c = b + an/c
+		if math.Abs(c) < tiny {
+			c = tiny
+		}
+		d = 1 / d
+		delta := d * c
+		h *= delta
+		if math.Abs(delta-1) < epsilon {
+			break
+		}
+	}
+	return math.Max(1-prefix*h, 0)
+}
+
+// RegularizedBeta returns the regularized incomplete beta function I_x(a, b),
+// using its continued fraction.
+func RegularizedBeta(x, a, b float64) float64 {
+	switch {
+	case x <= 0:
+		return 0
+	case x >= 1:
+		return 1
+	}
+
+	// the continued fraction converges quickly for x < (a + 1) / (a + b + 2)
+	if x > (a+1)/(a+b+2) {
+		return 1 - RegularizedBeta(1-x, b, a)
+	}
+
+	la, _ := math.Lgamma(a)
+	lb, _ := math.Lgamma(b)
+	lab, _ := math.Lgamma(a + b)
+	prefix := math.Exp(lab - la - lb + a*math.Log(x) + b*math.Log1p(-x))
+
+	// the modified Lentz's method
+	c := 1.0
+	d := 1 - (a+b)*x/(a+1)
+	if math.Abs(d) < tiny {
+		d = tiny
+	}
+	d = 1 / d
+	h := d
+	for m := 1; m < maxIterations; m++ {
+		m := float64(m)
+		for _, an := range []float64{
+			m * (b - m) * x / ((a + 2*m - 1) * (a + 2*m)),
+			-(a + m) * (a + b + m) * x / ((a + 2*m) * (a + 2*m + 1)),
+		} {
+			d = 1 + an*d
+			if math.Abs(d) < tiny {
+				d = tiny
+			}
+			c = 1 + an/c
+			if math.Abs(c) < tiny {
+				c = tiny
+			}
+			d = 1 / d
+			h *= d * c
+		}
+		if math.Abs(d*c-1) < epsilon {
+			break
+		}
+	}
+	return prefix * h / a
+}
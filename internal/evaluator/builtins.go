@@ -1,9 +1,18 @@
 package evaluator
 
 import (
+	"fmt"
 	"math"
+	"math/big"
+	"math/cmplx"
+	"sort"
+	"strings"
 
+	"github.com/DeepAung/qcal/internal/matrix"
+	"github.com/DeepAung/qcal/internal/numbertheory"
 	"github.com/DeepAung/qcal/internal/object"
+	"github.com/DeepAung/qcal/internal/polynomial"
+	"github.com/DeepAung/qcal/internal/symbolic"
 )
 
 var builtinValues = map[string]object.Object{
@@ -12,63 +21,664 @@ var builtinValues = map[string]object.Object{
 }
 
 type builtinFuncInfo struct {
-	name  string
-	len   int
-	types []object.ObjectType
+	name   string
+	len    int
+	types  []object.ObjectType
+	params []string
+	doc    string
+}
+
+// anyType is the type of the parameters which take any object, they have no
+// type in the signature.
+const anyType object.ObjectType = ""
+
+// signature returns the function signature, e.g. `log(x: NUMBER, y: NUMBER)`
+func (info builtinFuncInfo) signature() string {
+	params := make([]string, len(info.params))
+	for i, param := range info.params {
+		// the variadic parameters have the type of the last parameter
+		t := info.types[min(i, len(info.types)-1)]
+		if t == anyType {
+			params[i] = param
+		} else {
+			params[i] = fmt.Sprintf("%s: %s", param, t)
+		}
+	}
+	return info.name + "(" + strings.Join(params, ", ") + ")"
 }
 
 var infos = map[string]builtinFuncInfo{
 	"min": {
-		name:  "min",
-		len:   2,
-		types: []object.ObjectType{object.NUMBER_OBJ, object.NUMBER_OBJ},
+		name:   "min",
+		len:    2,
+		types:  []object.ObjectType{object.NUMBER_OBJ, object.NUMBER_OBJ},
+		params: []string{"x", "y"},
+		doc:    "returns the smaller of x and y",
 	},
 	"max": {
-		name:  "max",
-		len:   2,
-		types: []object.ObjectType{object.NUMBER_OBJ, object.NUMBER_OBJ},
+		name:   "max",
+		len:    2,
+		types:  []object.ObjectType{object.NUMBER_OBJ, object.NUMBER_OBJ},
+		params: []string{"x", "y"},
+		doc:    "returns the larger of x and y",
+	},
+	"abs": {
+		name:   "abs",
+		len:    1,
+		types:  []object.ObjectType{object.NUMBER_OBJ},
+		params: []string{"x"},
+		doc:    "returns the absolute value of x, or the modulus of the complex x",
+	},
+	"ceil": {
+		name:   "ceil",
+		len:    1,
+		types:  []object.ObjectType{object.NUMBER_OBJ},
+		params: []string{"x"},
+		doc:    "returns the least integer value greater than or equal to x",
+	},
+	"floor": {
+		name:   "floor",
+		len:    1,
+		types:  []object.ObjectType{object.NUMBER_OBJ},
+		params: []string{"x"},
+		doc:    "returns the greatest integer value less than or equal to x",
+	},
+	"round": {
+		name:   "round",
+		len:    1,
+		types:  []object.ObjectType{object.NUMBER_OBJ},
+		params: []string{"x"},
+		doc:    "returns the nearest integer, rounding half away from zero",
 	},
-	"abs":   {name: "abs", len: 1, types: []object.ObjectType{object.NUMBER_OBJ}},
-	"ceil":  {name: "ceil", len: 1, types: []object.ObjectType{object.NUMBER_OBJ}},
-	"floor": {name: "floor", len: 1, types: []object.ObjectType{object.NUMBER_OBJ}},
-	"round": {name: "round", len: 1, types: []object.ObjectType{object.NUMBER_OBJ}},
 
-	"sqrt": {name: "sqrt", len: 1, types: []object.ObjectType{object.NUMBER_OBJ}},
-	"cbrt": {name: "cbrt", len: 1, types: []object.ObjectType{object.NUMBER_OBJ}},
+	"sqrt": {
+		name:   "sqrt",
+		len:    1,
+		types:  []object.ObjectType{object.NUMBER_OBJ},
+		params: []string{"x"},
+		doc:    "returns the square root of x",
+	},
+	"cbrt": {
+		name:   "cbrt",
+		len:    1,
+		types:  []object.ObjectType{object.NUMBER_OBJ},
+		params: []string{"x"},
+		doc:    "returns the cube root of x",
+	},
 
 	"log": {
-		name:  "log",
-		len:   2,
-		types: []object.ObjectType{object.NUMBER_OBJ, object.NUMBER_OBJ},
+		name:   "log",
+		len:    2,
+		types:  []object.ObjectType{object.NUMBER_OBJ, object.NUMBER_OBJ},
+		params: []string{"x", "y"},
+		doc:    "returns the logarithm of x in base y",
+	},
+	"ln": {
+		name:   "ln",
+		len:    1,
+		types:  []object.ObjectType{object.NUMBER_OBJ},
+		params: []string{"x"},
+		doc:    "returns the natural logarithm of x",
+	},
+	"log10": {
+		name:   "log10",
+		len:    1,
+		types:  []object.ObjectType{object.NUMBER_OBJ},
+		params: []string{"x"},
+		doc:    "returns the decimal logarithm of x",
+	},
+	"log2": {
+		name:   "log2",
+		len:    1,
+		types:  []object.ObjectType{object.NUMBER_OBJ},
+		params: []string{"x"},
+		doc:    "returns the binary logarithm of x",
 	},
-	"ln":    {name: "ln", len: 1, types: []object.ObjectType{object.NUMBER_OBJ}},
-	"log10": {name: "log10", len: 1, types: []object.ObjectType{object.NUMBER_OBJ}},
-	"log2":  {name: "log2", len: 1, types: []object.ObjectType{object.NUMBER_OBJ}},
 	"pow": {
-		name:  "pow",
-		len:   2,
-		types: []object.ObjectType{object.NUMBER_OBJ, object.NUMBER_OBJ},
-	},
-	"pow10": {name: "pow10", len: 1, types: []object.ObjectType{object.NUMBER_OBJ}},
-
-	"sin":     {name: "sin", len: 1, types: []object.ObjectType{object.NUMBER_OBJ}},
-	"cos":     {name: "cos", len: 1, types: []object.ObjectType{object.NUMBER_OBJ}},
-	"tan":     {name: "tan", len: 1, types: []object.ObjectType{object.NUMBER_OBJ}},
-	"sinh":    {name: "sinh", len: 1, types: []object.ObjectType{object.NUMBER_OBJ}},
-	"cosh":    {name: "cosh", len: 1, types: []object.ObjectType{object.NUMBER_OBJ}},
-	"tanh":    {name: "tanh", len: 1, types: []object.ObjectType{object.NUMBER_OBJ}},
-	"arcsin":  {name: "arcsin", len: 1, types: []object.ObjectType{object.NUMBER_OBJ}},
-	"arccos":  {name: "arccos", len: 1, types: []object.ObjectType{object.NUMBER_OBJ}},
-	"arctan":  {name: "arctan", len: 1, types: []object.ObjectType{object.NUMBER_OBJ}},
-	"arcsinh": {name: "arcsinh", len: 1, types: []object.ObjectType{object.NUMBER_OBJ}},
-	"arccosh": {name: "arccosh", len: 1, types: []object.ObjectType{object.NUMBER_OBJ}},
-	"arctanh": {name: "arctanh", len: 1, types: []object.ObjectType{object.NUMBER_OBJ}},
-
-	"gamma": {name: "gamma", len: 1, types: []object.ObjectType{object.NUMBER_OBJ}},
+		name:   "pow",
+		len:    2,
+		types:  []object.ObjectType{object.NUMBER_OBJ, object.NUMBER_OBJ},
+		params: []string{"x", "y"},
+		doc:    "returns x to the power of y",
+	},
+	"pow10": {
+		name:   "pow10",
+		len:    1,
+		types:  []object.ObjectType{object.NUMBER_OBJ},
+		params: []string{"x"},
+		doc:    "returns 10 to the power of x",
+	},
+
+	"sin": {
+		name:   "sin",
+		len:    1,
+		types:  []object.ObjectType{object.NUMBER_OBJ},
+		params: []string{"x"},
+		doc:    "returns the sine of the radian argument x",
+	},
+	"cos": {
+		name:   "cos",
+		len:    1,
+		types:  []object.ObjectType{object.NUMBER_OBJ},
+		params: []string{"x"},
+		doc:    "returns the cosine of the radian argument x",
+	},
+	"tan": {
+		name:   "tan",
+		len:    1,
+		types:  []object.ObjectType{object.NUMBER_OBJ},
+		params: []string{"x"},
+		doc:    "returns the tangent of the radian argument x",
+	},
+	"sinh": {
+		name:   "sinh",
+		len:    1,
+		types:  []object.ObjectType{object.NUMBER_OBJ},
+		params: []string{"x"},
+		doc:    "returns the hyperbolic sine of x",
+	},
+	"cosh": {
+		name:   "cosh",
+		len:    1,
+		types:  []object.ObjectType{object.NUMBER_OBJ},
+		params: []string{"x"},
+		doc:    "returns the hyperbolic cosine of x",
+	},
+	"tanh": {
+		name:   "tanh",
+		len:    1,
+		types:  []object.ObjectType{object.NUMBER_OBJ},
+		params: []string{"x"},
+		doc:    "returns the hyperbolic tangent of x",
+	},
+	"arcsin": {
+		name:   "arcsin",
+		len:    1,
+		types:  []object.ObjectType{object.NUMBER_OBJ},
+		params: []string{"x"},
+		doc:    "returns the arcsine, in radians, of x",
+	},
+	"arccos": {
+		name:   "arccos",
+		len:    1,
+		types:  []object.ObjectType{object.NUMBER_OBJ},
+		params: []string{"x"},
+		doc:    "returns the arccosine, in radians, of x",
+	},
+	"arctan": {
+		name:   "arctan",
+		len:    1,
+		types:  []object.ObjectType{object.NUMBER_OBJ},
+		params: []string{"x"},
+		doc:    "returns the arctangent, in radians, of x",
+	},
+	"arcsinh": {
+		name:   "arcsinh",
+		len:    1,
+		types:  []object.ObjectType{object.NUMBER_OBJ},
+		params: []string{"x"},
+		doc:    "returns the inverse hyperbolic sine of x",
+	},
+	"arccosh": {
+		name:   "arccosh",
+		len:    1,
+		types:  []object.ObjectType{object.NUMBER_OBJ},
+		params: []string{"x"},
+		doc:    "returns the inverse hyperbolic cosine of x",
+	},
+	"arctanh": {
+		name:   "arctanh",
+		len:    1,
+		types:  []object.ObjectType{object.NUMBER_OBJ},
+		params: []string{"x"},
+		doc:    "returns the inverse hyperbolic tangent of x",
+	},
+
+	"gamma": {
+		name:   "gamma",
+		len:    1,
+		types:  []object.ObjectType{object.NUMBER_OBJ},
+		params: []string{"x"},
+		doc:    "returns the gamma function of x",
+	},
+	"digamma": {
+		name:   "digamma",
+		len:    1,
+		types:  []object.ObjectType{object.NUMBER_OBJ},
+		params: []string{"x"},
+		doc:    "returns the digamma function of x, the derivative of ln(gamma(x))",
+	},
 	"hypot": {
-		name:  "hypot",
-		len:   2,
-		types: []object.ObjectType{object.NUMBER_OBJ, object.NUMBER_OBJ},
+		name:   "hypot",
+		len:    2,
+		types:  []object.ObjectType{object.NUMBER_OBJ, object.NUMBER_OBJ},
+		params: []string{"x", "y"},
+		doc:    "returns sqrt(x*x + y*y)",
+	},
+	"erf": {
+		name:   "erf",
+		len:    1,
+		types:  []object.ObjectType{object.NUMBER_OBJ},
+		params: []string{"x"},
+		doc:    "returns the error function of x",
+	},
+	"ncr": {
+		name:   "ncr",
+		len:    2,
+		types:  []object.ObjectType{object.NUMBER_OBJ, object.NUMBER_OBJ},
+		params: []string{"n", "r"},
+		doc:    "returns the number of combinations of r items from n items",
+	},
+	"npr": {
+		name:   "npr",
+		len:    2,
+		types:  []object.ObjectType{object.NUMBER_OBJ, object.NUMBER_OBJ},
+		params: []string{"n", "r"},
+		doc:    "returns the number of permutations of r items from n items",
+	},
+	"rand": {
+		name:   "rand",
+		len:    0,
+		types:  []object.ObjectType{},
+		params: []string{},
+		doc:    "returns a random number from 0 up to 1, the numbers are reproducible with seed(n)",
+	},
+	"randint": {
+		name:   "randint",
+		len:    2,
+		types:  []object.ObjectType{object.NUMBER_OBJ, object.NUMBER_OBJ},
+		params: []string{"a", "b"},
+		doc:    "returns a random integer from a to b",
+	},
+	"seed": {
+		name:   "seed",
+		len:    1,
+		types:  []object.ObjectType{object.NUMBER_OBJ},
+		params: []string{"n"},
+		doc:    "seeds the random numbers of rand and randint with the integer n",
+	},
+
+	"diff": {
+		name:   "diff",
+		len:    1,
+		types:  []object.ObjectType{object.FUNCTION_OBJ},
+		params: []string{"f"},
+		doc: "returns the symbolic derivative of the single parameter function f, e.g. diff(x => x^2) is (x) => (2 * x), " +
+			"or the derivative of the polynomial f",
+	},
+
+	"poly": {
+		name:   "poly",
+		len:    1,
+		types:  []object.ObjectType{object.LIST_OBJ},
+		params: []string{"coeffs"},
+		doc:    "returns the polynomial of the coefficients from the highest power down, e.g. poly([3, -2, 1]) is 3x^2 - 2x + 1",
+	},
+	"gcd": {
+		name:   "gcd",
+		len:    -1,
+		types:  []object.ObjectType{object.NUMBER_OBJ, object.NUMBER_OBJ},
+		params: []string{"a", "b..."},
+		doc:    "returns the greatest common divisor of the integers a, b..., or the monic one of the polynomials a and b",
+	},
+	"lcm": {
+		name:   "lcm",
+		len:    -1,
+		types:  []object.ObjectType{object.NUMBER_OBJ, object.NUMBER_OBJ},
+		params: []string{"a", "b..."},
+		doc:    "returns the least common multiple of the integers a, b...",
+	},
+	"isprime": {
+		name:   "isprime",
+		len:    1,
+		types:  []object.ObjectType{object.NUMBER_OBJ},
+		params: []string{"n"},
+		doc:    "returns whether the integer n is prime, using the Miller-Rabin test",
+	},
+	"factor": {
+		name:   "factor",
+		len:    1,
+		types:  []object.ObjectType{object.NUMBER_OBJ},
+		params: []string{"n"},
+		doc:    "returns the list of the prime factors of the positive integer n, e.g. factor(12) is [2, 2, 3]",
+	},
+	"nextprime": {
+		name:   "nextprime",
+		len:    1,
+		types:  []object.ObjectType{object.NUMBER_OBJ},
+		params: []string{"n"},
+		doc:    "returns the smallest prime greater than the integer n",
+	},
+	"totient": {
+		name:   "totient",
+		len:    1,
+		types:  []object.ObjectType{object.NUMBER_OBJ},
+		params: []string{"n"},
+		doc:    "returns the number of the integers from 1 to n coprime to the positive integer n",
+	},
+	"modpow": {
+		name:   "modpow",
+		len:    3,
+		types:  []object.ObjectType{object.NUMBER_OBJ, object.NUMBER_OBJ, object.NUMBER_OBJ},
+		params: []string{"b", "e", "m"},
+		doc:    "returns b^e modulo m of the integers b, e and m, a negative e is a power of the inverse of b",
+	},
+	"modinv": {
+		name:   "modinv",
+		len:    2,
+		types:  []object.ObjectType{object.NUMBER_OBJ, object.NUMBER_OBJ},
+		params: []string{"a", "m"},
+		doc:    "returns the inverse of the integer a modulo m",
+	},
+	"fib": {
+		name:   "fib",
+		len:    1,
+		types:  []object.ObjectType{object.NUMBER_OBJ},
+		params: []string{"n"},
+		doc:    "returns the n-th Fibonacci number, fib(0) is 0 and fib(1) is 1",
+	},
+	"complex": {
+		name:   "complex",
+		len:    2,
+		types:  []object.ObjectType{object.NUMBER_OBJ, object.NUMBER_OBJ},
+		params: []string{"re", "im"},
+		doc:    "returns the complex number re + im*i",
+	},
+	"re": {
+		name:   "re",
+		len:    1,
+		types:  []object.ObjectType{object.COMPLEX_OBJ},
+		params: []string{"z"},
+		doc:    "returns the real part of z",
+	},
+	"im": {
+		name:   "im",
+		len:    1,
+		types:  []object.ObjectType{object.COMPLEX_OBJ},
+		params: []string{"z"},
+		doc:    "returns the imaginary part of z",
+	},
+	"conj": {
+		name:   "conj",
+		len:    1,
+		types:  []object.ObjectType{object.COMPLEX_OBJ},
+		params: []string{"z"},
+		doc:    "returns the complex conjugate of z",
+	},
+	"arg": {
+		name:   "arg",
+		len:    1,
+		types:  []object.ObjectType{object.COMPLEX_OBJ},
+		params: []string{"z"},
+		doc:    "returns the argument, in radians, of z",
+	},
+	"det": {
+		name:   "det",
+		len:    1,
+		types:  []object.ObjectType{object.MATRIX_OBJ},
+		params: []string{"A"},
+		doc:    "returns the determinant of the square matrix A",
+	},
+	"inv": {
+		name:   "inv",
+		len:    1,
+		types:  []object.ObjectType{object.MATRIX_OBJ},
+		params: []string{"A"},
+		doc:    "returns the inverse of the square matrix A",
+	},
+	"transpose": {
+		name:   "transpose",
+		len:    1,
+		types:  []object.ObjectType{object.MATRIX_OBJ},
+		params: []string{"A"},
+		doc:    "returns the transpose of the matrix A, the transpose of a vector is a matrix of one row",
+	},
+	"rank": {
+		name:   "rank",
+		len:    1,
+		types:  []object.ObjectType{object.MATRIX_OBJ},
+		params: []string{"A"},
+		doc:    "returns the number of linearly independent rows of the matrix A",
+	},
+	"eig": {
+		name:   "eig",
+		len:    1,
+		types:  []object.ObjectType{object.MATRIX_OBJ},
+		params: []string{"A"},
+		doc:    "returns the list of the real or complex eigenvalues of the square matrix A",
+	},
+	"lu": {
+		name:   "lu",
+		len:    1,
+		types:  []object.ObjectType{object.MATRIX_OBJ},
+		params: []string{"A"},
+		doc:    "returns the list [L, U, P] of the LU decomposition PA = LU of the square matrix A",
+	},
+	"qr": {
+		name:   "qr",
+		len:    1,
+		types:  []object.ObjectType{object.MATRIX_OBJ},
+		params: []string{"A"},
+		doc:    "returns the list [Q, R] of the QR decomposition A = QR of the matrix A",
+	},
+	"dot": {
+		name:   "dot",
+		len:    2,
+		types:  []object.ObjectType{object.LIST_OBJ, object.LIST_OBJ},
+		params: []string{"u", "v"},
+		doc:    "returns the dot product of the vectors u and v",
+	},
+	"cross": {
+		name:   "cross",
+		len:    2,
+		types:  []object.ObjectType{object.LIST_OBJ, object.LIST_OBJ},
+		params: []string{"u", "v"},
+		doc:    "returns the cross product of the vectors u and v of 3 elements",
+	},
+	"norm": {
+		name:   "norm",
+		len:    1,
+		types:  []object.ObjectType{object.LIST_OBJ},
+		params: []string{"v"},
+		doc:    "returns the Euclidean norm of the vector v, or the Frobenius norm of a matrix",
+	},
+
+	"mean": {
+		name:   "mean",
+		len:    -1,
+		types:  []object.ObjectType{object.NUMBER_OBJ},
+		params: []string{"xs..."},
+		doc:    "returns the arithmetic mean of the numbers xs..., or of a list",
+	},
+	"median": {
+		name:   "median",
+		len:    -1,
+		types:  []object.ObjectType{object.NUMBER_OBJ},
+		params: []string{"xs..."},
+		doc:    "returns the middle value of the numbers xs..., or of a list",
+	},
+	"mode": {
+		name:   "mode",
+		len:    -1,
+		types:  []object.ObjectType{object.NUMBER_OBJ},
+		params: []string{"xs..."},
+		doc:    "returns the most frequent value of the numbers xs..., or of a list, the smallest one of a tie",
+	},
+	"variance": {
+		name:   "variance",
+		len:    -1,
+		types:  []object.ObjectType{object.NUMBER_OBJ},
+		params: []string{"xs..."},
+		doc:    "returns the sample variance of the numbers xs..., or of a list",
+	},
+	"pvariance": {
+		name:   "pvariance",
+		len:    -1,
+		types:  []object.ObjectType{object.NUMBER_OBJ},
+		params: []string{"xs..."},
+		doc:    "returns the population variance of the numbers xs..., or of a list",
+	},
+	"stdev": {
+		name:   "stdev",
+		len:    -1,
+		types:  []object.ObjectType{object.NUMBER_OBJ},
+		params: []string{"xs..."},
+		doc:    "returns the sample standard deviation of the numbers xs..., or of a list",
+	},
+	"pstdev": {
+		name:   "pstdev",
+		len:    -1,
+		types:  []object.ObjectType{object.NUMBER_OBJ},
+		params: []string{"xs..."},
+		doc:    "returns the population standard deviation of the numbers xs..., or of a list",
+	},
+	"quantile": {
+		name:   "quantile",
+		len:    2,
+		types:  []object.ObjectType{object.LIST_OBJ, object.NUMBER_OBJ},
+		params: []string{"xs", "q"},
+		doc:    "returns the q-quantile of the list xs for q from 0 to 1, interpolating between the values",
+	},
+	"percentile": {
+		name:   "percentile",
+		len:    2,
+		types:  []object.ObjectType{object.LIST_OBJ, object.NUMBER_OBJ},
+		params: []string{"xs", "p"},
+		doc:    "returns the p-th percentile of the list xs for p from 0 to 100, interpolating between the values",
+	},
+	"covariance": {
+		name:   "covariance",
+		len:    2,
+		types:  []object.ObjectType{object.LIST_OBJ, object.LIST_OBJ},
+		params: []string{"xs", "ys"},
+		doc:    "returns the sample covariance of the lists xs and ys",
+	},
+	"correlation": {
+		name:   "correlation",
+		len:    2,
+		types:  []object.ObjectType{object.LIST_OBJ, object.LIST_OBJ},
+		params: []string{"xs", "ys"},
+		doc:    "returns the Pearson correlation coefficient of the lists xs and ys",
+	},
+	"zscore": {
+		name:   "zscore",
+		len:    -1,
+		types:  []object.ObjectType{object.NUMBER_OBJ, object.NUMBER_OBJ},
+		params: []string{"x", "xs..."},
+		doc:    "returns the number of sample standard deviations from x to the mean of the numbers xs..., or of a list",
+	},
+	"linreg": {
+		name:   "linreg",
+		len:    2,
+		types:  []object.ObjectType{object.LIST_OBJ, object.LIST_OBJ},
+		params: []string{"xs", "ys"},
+		doc:    "returns the list [slope, intercept] of the least squares line through the points of xs and ys",
+	},
+	"range": {
+		name:   "range",
+		len:    -1,
+		types:  []object.ObjectType{object.NUMBER_OBJ},
+		params: []string{"a", "b?", "step?"},
+		doc:    "returns the list of the numbers from 0 to a, or from a to b, by step (default 1), excluding the end, e.g. range(3) is [0, 1, 2]",
+	},
+	"integrate": {
+		name:   "integrate",
+		len:    3,
+		types:  []object.ObjectType{object.FUNCTION_OBJ, object.NUMBER_OBJ, object.NUMBER_OBJ},
+		params: []string{"f", "a", "b"},
+		doc:    "returns the integral of f from a to b with its error bound, the bounds can be infinite, e.g. e^1000, which overflows to +Inf",
+	},
+	"deriv": {
+		name:   "deriv",
+		len:    2,
+		types:  []object.ObjectType{object.FUNCTION_OBJ, object.NUMBER_OBJ},
+		params: []string{"f", "x"},
+		doc:    "returns the numerical derivative of f at x with its error bound",
+	},
+	"limit": {
+		name:   "limit",
+		len:    2,
+		types:  []object.ObjectType{object.FUNCTION_OBJ, object.NUMBER_OBJ},
+		params: []string{"f", "x0"},
+		doc:    "returns the limit of f(x) as x approaches x0 with its error bound, x0 can be infinite, e.g. e^1000, which overflows to +Inf",
+	},
+	"series": {
+		name:   "series",
+		len:    3,
+		types:  []object.ObjectType{object.FUNCTION_OBJ, object.NUMBER_OBJ, object.NUMBER_OBJ},
+		params: []string{"f", "from", "to"},
+		doc:    "returns the sum of f(n) for the integers n from from to to with its error bound, to can be infinite, e.g. e^1000, which overflows to +Inf",
+	},
+	"solve": {
+		name:   "solve",
+		len:    -1,
+		types:  []object.ObjectType{object.FUNCTION_OBJ, object.NUMBER_OBJ, object.NUMBER_OBJ},
+		params: []string{"f", "x0?", "b?"},
+		doc: "returns a solution of the equation f, e.g. x^2 := 4, x^2 == 4 or x => x^2 == 4, or a root of the function f, " +
+			"the closest one to the guess x0 (default 0), or from x0 to b, or x of the linear system solve(A, b) of Ax = b",
+	},
+	"roots": {
+		name:   "roots",
+		len:    3,
+		types:  []object.ObjectType{object.FUNCTION_OBJ, object.NUMBER_OBJ, object.NUMBER_OBJ},
+		params: []string{"f", "a?", "b?"},
+		doc: "returns the list of the solutions of the equation f, or the roots of the function f, from a to b, " +
+			"or all the complex roots of the polynomial f",
+	},
+	"memo": {
+		name:   "memo",
+		len:    1,
+		types:  []object.ObjectType{object.FUNCTION_OBJ},
+		params: []string{"f"},
+		doc: "returns f with a cache of its results keyed on the argument values, f should not read outer variables, " +
+			"e.g. fib = memo(n => if n < 2 { n } else { fib(n - 1) + fib(n - 2) })",
+	},
+	"compose": {
+		name:   "compose",
+		len:    2,
+		types:  []object.ObjectType{object.FUNCTION_OBJ, object.FUNCTION_OBJ},
+		params: []string{"f", "g"},
+		doc:    "returns the function x => f(g(x)), also written f ∘ g or f . g",
+	},
+	"partial": {
+		name:   "partial",
+		len:    -1,
+		types:  []object.ObjectType{object.FUNCTION_OBJ, anyType},
+		params: []string{"f", "args...?"},
+		doc: "returns f with its first arguments args..., which takes the other arguments, " +
+			"e.g. partial((x, y) => x - y, 10)(3) is 7",
+	},
+	"raise": {
+		name:   "raise",
+		len:    -1,
+		types:  []object.ObjectType{object.STRING_OBJ, object.STRING_OBJ},
+		params: []string{"msg", "code?"},
+		doc: `raises an error with the message msg and the code (default "error"), caught by try { ... } catch e { ... }, ` +
+			"or raises again the caught error e with raise(e)",
+	},
+	"assert": {
+		name:   "assert",
+		len:    -1,
+		types:  []object.ObjectType{object.BOOLEAN_OBJ, object.STRING_OBJ},
+		params: []string{"cond", "message?"},
+		doc:    `returns true if cond is true, or raises an error with the message and the code "assert"`,
+	},
+
+	"plot": {
+		name:   "plot",
+		len:    -1,
+		types:  []object.ObjectType{object.FUNCTION_OBJ, object.NUMBER_OBJ, object.NUMBER_OBJ},
+		params: []string{"f...", "xmin", "xmax"},
+		doc:    "draws the functions f... from xmin to xmax, marking roots (o), maxima (^) and minima (v)",
+	},
+	"plotfile": {
+		name: "plotfile",
+		len:  -1,
+		types: []object.ObjectType{
+			object.STRING_OBJ, object.FUNCTION_OBJ, object.NUMBER_OBJ, object.NUMBER_OBJ, object.STRING_OBJ,
+		},
+		params: []string{"path", "f...", "xmin", "xmax", "opts?"},
+		doc:    `writes the functions f... from xmin to xmax to an .svg or .png file in the working directory, opts is e.g. "width=800 height=600"`,
 	},
 
 	// "Exp":         {name: "Exp", len: 1, types: []object.ObjectType{object.NUMBER_OBJ}},
@@ -112,6 +722,9 @@ var builtinFuncs = map[string]object.BuiltinFunction{
 		if err := checkArgsLength(info, args); err != nil {
 			return err
 		}
+		if z, ok := args[0].(*object.Complex); ok {
+			return newNumber(cmplx.Abs(z.Value))
+		}
 		if err := checkArgsType(info, args); err != nil {
 			return err
 		}
@@ -165,6 +778,9 @@ var builtinFuncs = map[string]object.BuiltinFunction{
 		}
 
 		val0 := args[0].(*object.Number).Value
+		if val0 < 0 {
+			return domainError(info, args, 0, "non-negative")
+		}
 		return newNumber(math.Sqrt(val0))
 	},
 	"cbrt": func(args ...object.Object) object.Object {
@@ -190,6 +806,12 @@ var builtinFuncs = map[string]object.BuiltinFunction{
 
 		val0 := args[0].(*object.Number).Value
 		val1 := args[1].(*object.Number).Value
+		if val0 <= 0 {
+			return domainError(info, args, 0, "positive")
+		}
+		if val1 <= 0 || val1 == 1 {
+			return domainError(info, args, 1, "positive and not 1")
+		}
 		return newNumber(math.Log(val0) / math.Log(val1))
 	},
 	"ln": func(args ...object.Object) object.Object {
@@ -202,6 +824,9 @@ var builtinFuncs = map[string]object.BuiltinFunction{
 		}
 
 		val0 := args[0].(*object.Number).Value
+		if val0 <= 0 {
+			return domainError(info, args, 0, "positive")
+		}
 		return newNumber(math.Log(val0))
 	},
 	"log10": func(args ...object.Object) object.Object {
@@ -214,6 +839,9 @@ var builtinFuncs = map[string]object.BuiltinFunction{
 		}
 
 		val0 := args[0].(*object.Number).Value
+		if val0 <= 0 {
+			return domainError(info, args, 0, "positive")
+		}
 		return newNumber(math.Log10(val0))
 	},
 	"log2": func(args ...object.Object) object.Object {
@@ -226,6 +854,9 @@ var builtinFuncs = map[string]object.BuiltinFunction{
 		}
 
 		val0 := args[0].(*object.Number).Value
+		if val0 <= 0 {
+			return domainError(info, args, 0, "positive")
+		}
 		return newNumber(math.Log2(val0))
 	},
 	"pow": func(args ...object.Object) object.Object {
@@ -263,8 +894,7 @@ var builtinFuncs = map[string]object.BuiltinFunction{
 		}
 
 		val0 := args[0].(*object.Number).Value
-		_ = val0
-		return newNumber(math.Sin(math.Pi))
+		return newNumber(math.Sin(val0))
 	},
 	"cos": func(args ...object.Object) object.Object {
 		info := infos["cos"]
@@ -410,6 +1040,373 @@ var builtinFuncs = map[string]object.BuiltinFunction{
 		val0 := args[0].(*object.Number).Value
 		return newNumber(math.Gamma(val0))
 	},
+	"digamma": func(args ...object.Object) object.Object {
+		info := infos["digamma"]
+		if err := checkArgsLength(info, args); err != nil {
+			return err
+		}
+		if err := checkArgsType(info, args); err != nil {
+			return err
+		}
+
+		val0 := args[0].(*object.Number).Value
+		return newNumber(digamma(val0))
+	},
+	"diff": func(args ...object.Object) object.Object {
+		info := infos["diff"]
+		if err := checkArgsLength(info, args); err != nil {
+			return err
+		}
+		if p, ok := args[0].(*object.Polynomial); ok {
+			return &object.Polynomial{Coeffs: polynomial.Derivative(p.Coeffs)}
+		}
+		if err := checkArgsType(info, args); err != nil {
+			return err
+		}
+
+		fn, ok := args[0].(*object.ConciseFunction)
+		if !ok {
+			return newError("%q: only concise functions like x => x^2 can be differentiated", info.name)
+		}
+		if len(fn.Parameters) != 1 {
+			return newError("%q: function should have 1 parameter, got %d", info.name, len(fn.Parameters))
+		}
+
+		derivative, err := symbolic.Derivative(fn.Body, fn.Parameters[0].Name.Value)
+		if err != nil {
+			return newError("%q: %s", info.name, err)
+		}
+		return &object.ConciseFunction{
+			Parameters: fn.Parameters,
+			Body:       symbolic.Simplify(derivative),
+			Env:        fn.Env,
+		}
+	},
+	"poly": func(args ...object.Object) object.Object {
+		info := infos["poly"]
+		if err := checkArgsLength(info, args); err != nil {
+			return err
+		}
+		if err := checkArgsType(info, args); err != nil {
+			return err
+		}
+
+		elements := args[0].(*object.List).Elements
+		coeffs := make([]float64, len(elements))
+		for i, element := range elements {
+			number, ok := element.(*object.Number)
+			if !ok {
+				return newError("%q: coefficients should be type %s, got %s", info.name, object.NUMBER_OBJ, element.Type())
+			}
+			coeffs[len(coeffs)-1-i] = number.Value
+		}
+		return &object.Polynomial{Coeffs: polynomial.Trim(coeffs)}
+	},
+	"gcd": func(args ...object.Object) object.Object {
+		info := infos["gcd"]
+		if p, ok := firstPolynomial(args); ok {
+			if len(args) != 2 {
+				return newError("%q: expect 2 polynomials, got=%d arguments", info.name, len(args))
+			}
+			q, ok := args[1].(*object.Polynomial)
+			if !ok {
+				return newError(
+					"argument index 1 of function %q should be type %s, got %s",
+					info.name, object.POLYNOMIAL_OBJ, args[1].Type(),
+				)
+			}
+			return &object.Polynomial{Coeffs: polynomial.GCD(p.Coeffs, q.Coeffs)}
+		}
+
+		values, err := integerArgs(info, args)
+		if err != nil {
+			return err
+		}
+		result := new(big.Int)
+		for _, v := range values {
+			result.GCD(nil, nil, result, new(big.Int).Abs(v))
+		}
+		return newInteger(result)
+	},
+	"lcm": func(args ...object.Object) object.Object {
+		values, err := integerArgs(infos["lcm"], args)
+		if err != nil {
+			return err
+		}
+
+		result := big.NewInt(1)
+		for _, v := range values {
+			if v.Sign() == 0 {
+				return newNumber(0)
+			}
+			gcd := new(big.Int).GCD(nil, nil, result, new(big.Int).Abs(v))
+			result.Mul(result, new(big.Int).Quo(new(big.Int).Abs(v), gcd))
+		}
+		return newInteger(result)
+	},
+	"isprime": func(args ...object.Object) object.Object {
+		values, err := integerArgs(infos["isprime"], args)
+		if err != nil {
+			return err
+		}
+		return booleanObject(numbertheory.IsPrime(values[0]))
+	},
+	"factor": func(args ...object.Object) object.Object {
+		info := infos["factor"]
+		n, err := positiveIntegerArg(info, args)
+		if err != nil {
+			return err
+		}
+
+		factors, ok := numbertheory.Factor(n)
+		if !ok {
+			return newError("%q: the factors of %s are too large", info.name, n)
+		}
+		return newIntegerList(factors)
+	},
+	"nextprime": func(args ...object.Object) object.Object {
+		values, err := integerArgs(infos["nextprime"], args)
+		if err != nil {
+			return err
+		}
+		return newInteger(numbertheory.NextPrime(values[0]))
+	},
+	"totient": func(args ...object.Object) object.Object {
+		info := infos["totient"]
+		n, err := positiveIntegerArg(info, args)
+		if err != nil {
+			return err
+		}
+
+		result, ok := numbertheory.Totient(n)
+		if !ok {
+			return newError("%q: the factors of %s are too large", info.name, n)
+		}
+		return newInteger(result)
+	},
+	"modpow": func(args ...object.Object) object.Object {
+		info := infos["modpow"]
+		values, err := integerArgs(info, args)
+		if err != nil {
+			return err
+		}
+		if values[2].Sign() <= 0 {
+			return newError("%q: modulus should be a positive integer, got %s", info.name, values[2])
+		}
+
+		result, ok := numbertheory.ModPow(values[0], values[1], values[2])
+		if !ok {
+			return newError("%q: %s has no inverse modulo %s", info.name, values[0], values[2])
+		}
+		return newInteger(result)
+	},
+	"modinv": func(args ...object.Object) object.Object {
+		info := infos["modinv"]
+		values, err := integerArgs(info, args)
+		if err != nil {
+			return err
+		}
+		if values[1].Cmp(big.NewInt(1)) <= 0 {
+			return newError("%q: modulus should be an integer greater than 1, got %s", info.name, values[1])
+		}
+
+		result, ok := numbertheory.ModInverse(values[0], values[1])
+		if !ok {
+			return newError("%q: %s has no inverse modulo %s", info.name, values[0], values[1])
+		}
+		return newInteger(result)
+	},
+	"fib": func(args ...object.Object) object.Object {
+		info := infos["fib"]
+		values, err := integerArgs(info, args)
+		if err != nil {
+			return err
+		}
+		// fib(n) has about 0.69n bits
+		if !values[0].IsInt64() || math.Abs(float64(values[0].Int64()))*0.7 > maxExactBits {
+			return newError("%q: n is too large, got %s", info.name, values[0])
+		}
+		return newInteger(numbertheory.Fibonacci(values[0].Int64()))
+	},
+	"complex": func(args ...object.Object) object.Object {
+		info := infos["complex"]
+		if err := checkArgsLength(info, args); err != nil {
+			return err
+		}
+		if err := checkArgsType(info, args); err != nil {
+			return err
+		}
+
+		val0 := args[0].(*object.Number).Value
+		val1 := args[1].(*object.Number).Value
+		return newComplex(complex(val0, val1))
+	},
+	"re": func(args ...object.Object) object.Object {
+		z, err := complexArg(infos["re"], args)
+		if err != nil {
+			return err
+		}
+		return newNumber(real(z))
+	},
+	"im": func(args ...object.Object) object.Object {
+		z, err := complexArg(infos["im"], args)
+		if err != nil {
+			return err
+		}
+		return newNumber(imag(z))
+	},
+	"conj": func(args ...object.Object) object.Object {
+		z, err := complexArg(infos["conj"], args)
+		if err != nil {
+			return err
+		}
+		return newComplex(cmplx.Conj(z))
+	},
+	"arg": func(args ...object.Object) object.Object {
+		z, err := complexArg(infos["arg"], args)
+		if err != nil {
+			return err
+		}
+		return newNumber(cmplx.Phase(z))
+	},
+	"det": func(args ...object.Object) object.Object {
+		info := infos["det"]
+		a, err := matrixArgs(info, args)
+		if err != nil {
+			return err
+		}
+
+		det, detErr := matrix.Det(a)
+		if detErr != nil {
+			return newError("%q: %s", info.name, detErr)
+		}
+		return newNumber(det)
+	},
+	"inv": func(args ...object.Object) object.Object {
+		info := infos["inv"]
+		a, err := matrixArgs(info, args)
+		if err != nil {
+			return err
+		}
+
+		inv, invErr := matrix.Inverse(a)
+		if invErr != nil {
+			return newError("%q: %s", info.name, invErr)
+		}
+		return &object.Matrix{Rows: inv}
+	},
+	"transpose": func(args ...object.Object) object.Object {
+		info := infos["transpose"]
+		if err := checkArgsLength(info, args); err != nil {
+			return err
+		}
+		if list, ok := args[0].(*object.List); ok {
+			v, err := vectorArg(info, args, 0)
+			if err != nil {
+				return err
+			}
+			if len(list.Elements) == 0 {
+				return newError("%q: vector should not be empty", info.name)
+			}
+			return &object.Matrix{Rows: [][]float64{v}}
+		}
+
+		a, err := matrixArgs(info, args)
+		if err != nil {
+			return err
+		}
+		return &object.Matrix{Rows: matrix.Transpose(a)}
+	},
+	"rank": func(args ...object.Object) object.Object {
+		a, err := matrixArgs(infos["rank"], args)
+		if err != nil {
+			return err
+		}
+		return newNumber(float64(matrix.Rank(a)))
+	},
+	"eig": func(args ...object.Object) object.Object {
+		info := infos["eig"]
+		a, err := matrixArgs(info, args)
+		if err != nil {
+			return err
+		}
+
+		values, eigErr := matrix.Eigenvalues(a)
+		if eigErr != nil {
+			return newError("%q: %s", info.name, eigErr)
+		}
+		list := &object.List{Elements: make([]object.Object, len(values))}
+		for i, v := range values {
+			list.Elements[i] = newComplex(v)
+		}
+		return list
+	},
+	"lu": func(args ...object.Object) object.Object {
+		info := infos["lu"]
+		a, err := matrixArgs(info, args)
+		if err != nil {
+			return err
+		}
+
+		l, u, p, luErr := matrix.LU(a)
+		if luErr != nil {
+			return newError("%q: %s", info.name, luErr)
+		}
+		return newMatrixList(l, u, p)
+	},
+	"qr": func(args ...object.Object) object.Object {
+		a, err := matrixArgs(infos["qr"], args)
+		if err != nil {
+			return err
+		}
+
+		q, r := matrix.QR(a)
+		return newMatrixList(q, r)
+	},
+	"dot": func(args ...object.Object) object.Object {
+		info := infos["dot"]
+		u, v, err := vectorPairArgs(info, args)
+		if err != nil {
+			return err
+		}
+
+		sum := 0.0
+		for i := range u {
+			sum += u[i] * v[i]
+		}
+		return newNumber(sum)
+	},
+	"cross": func(args ...object.Object) object.Object {
+		info := infos["cross"]
+		u, v, err := vectorPairArgs(info, args)
+		if err != nil {
+			return err
+		}
+		if len(u) != 3 {
+			return newError("%q: vectors should have 3 elements, got %d", info.name, len(u))
+		}
+
+		return newVector([]float64{
+			u[1]*v[2] - u[2]*v[1],
+			u[2]*v[0] - u[0]*v[2],
+			u[0]*v[1] - u[1]*v[0],
+		})
+	},
+	"norm": func(args ...object.Object) object.Object {
+		info := infos["norm"]
+		if err := checkArgsLength(info, args); err != nil {
+			return err
+		}
+		if m, ok := args[0].(*object.Matrix); ok {
+			return newNumber(matrix.Norm(m.Rows))
+		}
+
+		v, err := vectorArg(info, args, 0)
+		if err != nil {
+			return err
+		}
+		return newNumber(matrix.Norm([][]float64{v}))
+	},
 	"hypot": func(args ...object.Object) object.Object {
 		info := infos["hypot"]
 		if err := checkArgsLength(info, args); err != nil {
@@ -423,13 +1420,274 @@ var builtinFuncs = map[string]object.BuiltinFunction{
 		val1 := args[1].(*object.Number).Value
 		return newNumber(math.Hypot(val0, val1))
 	},
+	"erf": func(args ...object.Object) object.Object {
+		info := infos["erf"]
+		if err := checkArgsLength(info, args); err != nil {
+			return err
+		}
+		if err := checkArgsType(info, args); err != nil {
+			return err
+		}
+
+		val0 := args[0].(*object.Number).Value
+		return newNumber(math.Erf(val0))
+	},
+	"ncr": func(args ...object.Object) object.Object {
+		info := infos["ncr"]
+		n, r, err := combinatoricsArgs(info, args)
+		if err != nil {
+			return err
+		}
+
+		r = min(r, n-r)
+		result := 1.0
+		for i := 1.0; i <= r; i++ {
+			result = result * (n - r + i) / i
+		}
+		return newNumber(math.Round(result))
+	},
+	"npr": func(args ...object.Object) object.Object {
+		info := infos["npr"]
+		n, r, err := combinatoricsArgs(info, args)
+		if err != nil {
+			return err
+		}
+
+		result := 1.0
+		for i := n - r + 1; i <= n; i++ {
+			result *= i
+		}
+		return newNumber(result)
+	},
+
+	"mean": func(args ...object.Object) object.Object {
+		xs, err := dataArgs(infos["mean"], args, 0)
+		if err != nil {
+			return err
+		}
+		return newNumber(mean(xs))
+	},
+	"median": func(args ...object.Object) object.Object {
+		xs, err := dataArgs(infos["median"], args, 0)
+		if err != nil {
+			return err
+		}
+		return newNumber(quantile(sorted(xs), 0.5))
+	},
+	"mode": func(args ...object.Object) object.Object {
+		xs, err := dataArgs(infos["mode"], args, 0)
+		if err != nil {
+			return err
+		}
+		return newNumber(mode(xs))
+	},
+	"variance": func(args ...object.Object) object.Object {
+		return varianceBuiltin(infos["variance"], args, 1, false)
+	},
+	"pvariance": func(args ...object.Object) object.Object {
+		return varianceBuiltin(infos["pvariance"], args, 0, false)
+	},
+	"stdev": func(args ...object.Object) object.Object {
+		return varianceBuiltin(infos["stdev"], args, 1, true)
+	},
+	"pstdev": func(args ...object.Object) object.Object {
+		return varianceBuiltin(infos["pstdev"], args, 0, true)
+	},
+	"quantile": func(args ...object.Object) object.Object {
+		return quantileBuiltin(infos["quantile"], args, 1)
+	},
+	"percentile": func(args ...object.Object) object.Object {
+		return quantileBuiltin(infos["percentile"], args, 100)
+	},
+	"covariance": func(args ...object.Object) object.Object {
+		xs, ys, err := pairedDataArgs(infos["covariance"], args)
+		if err != nil {
+			return err
+		}
+		return newNumber(covariance(xs, ys))
+	},
+	"correlation": func(args ...object.Object) object.Object {
+		info := infos["correlation"]
+		xs, ys, err := pairedDataArgs(info, args)
+		if err != nil {
+			return err
+		}
+
+		sx, sy := math.Sqrt(variance(xs, 1)), math.Sqrt(variance(ys, 1))
+		if sx == 0 || sy == 0 {
+			return newError("%q: the values of a list should not all be equal", info.name)
+		}
+		return newNumber(covariance(xs, ys) / (sx * sy))
+	},
+	"zscore": func(args ...object.Object) object.Object {
+		info := infos["zscore"]
+		if err := checkArgsLength(info, args); err != nil {
+			return err
+		}
+		x, ok := args[0].(*object.Number)
+		if !ok {
+			return newError(
+				"argument index 0 of function %q should be type %s, got %s",
+				info.name, object.NUMBER_OBJ, args[0].Type(),
+			)
+		}
+		xs, err := dataArgs(info, args, 1)
+		if err != nil {
+			return err
+		}
+		if len(xs) < 2 {
+			return newError("%q: expect at least 2 values, got=%d", info.name, len(xs))
+		}
+
+		sd := math.Sqrt(variance(xs, 1))
+		if sd == 0 {
+			return newError("%q: the values should not all be equal", info.name)
+		}
+		return newNumber((x.Value - mean(xs)) / sd)
+	},
+	"linreg": func(args ...object.Object) object.Object {
+		info := infos["linreg"]
+		xs, ys, err := pairedDataArgs(info, args)
+		if err != nil {
+			return err
+		}
+
+		vx := variance(xs, 1)
+		if vx == 0 {
+			return newError("%q: the values of xs should not all be equal", info.name)
+		}
+		slope := covariance(xs, ys) / vx
+		intercept := mean(ys) - slope*mean(xs)
+		return newVector([]float64{slope, intercept})
+	},
+	"range": func(args ...object.Object) object.Object {
+		info := infos["range"]
+		if err := checkArgsLength(info, args); err != nil {
+			return err
+		}
+		values, err := numberArgs(info, args, 0)
+		if err != nil {
+			return err
+		}
+
+		start, stop, step := 0.0, values[0], 1.0
+		if len(values) >= 2 {
+			start, stop = values[0], values[1]
+		}
+		if len(values) == 3 {
+			step = values[2]
+		}
+		if step == 0 || math.IsNaN(step) {
+			return newError("%q: step should not be 0", info.name)
+		}
+
+		n := math.Max(math.Ceil((stop-start)/step), 0)
+		if !(n <= maxRangeLength) {
+			return newError("%q: the list is too long, %g elements", info.name, n)
+		}
+		list := &object.List{Elements: make([]object.Object, int(n))}
+		for i := range list.Elements {
+			list.Elements[i] = newNumber(start + float64(i)*step)
+		}
+		return list
+	},
+	"raise":   raiseFunction,
+	"assert":  assertFunction,
+	"compose": composeFunction,
+	"partial": partialFunction,
+}
+
+// maxRangeLength limits the number of the elements of range.
+const maxRangeLength = 1e7
+
+// digamma returns the derivative of ln(gamma(x)) using the recurrence
+// digamma(x) = digamma(x+1) - 1/x and the asymptotic series for large x.
+func digamma(x float64) float64 {
+	if x <= 0 && x == math.Floor(x) {
+		return math.NaN()
+	}
+	if x < 0.5 {
+		// reflection formula
+		return digamma(1-x) - math.Pi/math.Tan(math.Pi*x)
+	}
+
+	result := 0.0
+	for ; x < 6; x++ {
+		result -= 1 / x
+	}
+
+	x2 := 1 / (x * x)
+	series := x2 * (1.0/12 - x2*(1.0/120-x2*(1.0/252-x2*(1.0/240-x2*(1.0/132)))))
+	return result + math.Log(x) - 0.5/x - series
+}
+
+// combinatoricsArgs returns the integers n and r of ncr and npr, r is from 0
+// to n.
+func combinatoricsArgs(info builtinFuncInfo, args []object.Object) (float64, float64, *object.Error) {
+	if err := checkArgsLength(info, args); err != nil {
+		return 0, 0, err
+	}
+	if err := checkArgsType(info, args); err != nil {
+		return 0, 0, err
+	}
+
+	n := args[0].(*object.Number).Value
+	r := args[1].(*object.Number).Value
+	if n < 0 || n != math.Trunc(n) || r < 0 || r != math.Trunc(r) {
+		return 0, 0, newError("%q: n and r should be non-negative integers, got %s and %s", info.name, args[0].Inspect(), args[1].Inspect())
+	}
+	if r > n {
+		return 0, 0, newError("%q: r should not be greater than n, got %s and %s", info.name, args[1].Inspect(), args[0].Inspect())
+	}
+	return n, r, nil
+}
+
+// BuiltinNames returns the sorted names of all builtin functions.
+func BuiltinNames() []string {
+	names := make([]string, 0, len(infos))
+	for name := range infos {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// BuiltinSignature returns the signature of the builtin function, e.g. `min(x: NUMBER, y: NUMBER)`
+func BuiltinSignature(name string) (string, bool) {
+	info, ok := infos[name]
+	if !ok {
+		return "", false
+	}
+	return info.signature(), true
+}
+
+// BuiltinDoc returns the documentation of the builtin function.
+func BuiltinDoc(name string) (string, bool) {
+	info, ok := infos[name]
+	if !ok {
+		return "", false
+	}
+	return info.doc, true
 }
 
 func checkArgsLength(info builtinFuncInfo, args []object.Object) *object.Error {
 	expect := info.len
 	got := len(args)
 
-	if got == -1 { // manually check args length
+	if expect == -1 { // variadic, the optional parameters end with "?"
+		required, rest := 0, false
+		for _, param := range info.params {
+			param, optional := strings.CutSuffix(param, "?")
+			if !optional {
+				required++
+			}
+			rest = rest || strings.HasSuffix(param, "...")
+		}
+		if got < required {
+			return newError("%q: not enough arguments, expect at least %d, got=%d", info.name, required, got)
+		} else if !rest && got > len(info.params) {
+			return newError("%q: too many arguments, expect at most %d, got=%d", info.name, len(info.params), got)
+		}
 		return nil
 	}
 
@@ -443,12 +1701,20 @@ func checkArgsLength(info builtinFuncInfo, args []object.Object) *object.Error {
 
 func checkArgsType(info builtinFuncInfo, args []object.Object) *object.Error {
 	for i, arg := range args {
-		if arg.Type() != info.types[i] {
+		// the variadic arguments have the type of the last parameter
+		expect := info.types[min(i, len(info.types)-1)]
+		if arg.Type() != expect {
 			return newError(
 				"argument index %d of function %q should be type %s, got %s",
-				i, info.name, info.types[i], arg.Type(),
+				i, info.name, expect, arg.Type(),
 			)
 		}
 	}
 	return nil
 }
+
+// domainError returns the error of the argument at index i out of the domain
+// of the function, e.g. ln(-1).
+func domainError(info builtinFuncInfo, args []object.Object, i int, domain string) *object.Error {
+	return newDomainError("argument index %d of function %q should be %s, got %s", i, info.name, domain, args[i].Inspect())
+}
@@ -0,0 +1,556 @@
+package evaluator
+
+import (
+	"math"
+
+	"github.com/DeepAung/qcal/internal/object"
+)
+
+// the calculus builtins evaluate the function at their sample points with
+// numericFunction.call, which reaches builtinFuncs through applyFunction, so
+// they cannot be in the literal of builtinFuncs.
+func init() {
+	builtinFuncs["integrate"] = integrateFunction
+	builtinFuncs["deriv"] = derivFunction
+	builtinFuncs["limit"] = limitFunction
+	builtinFuncs["series"] = seriesFunction
+}
+
+func integrateFunction(args ...object.Object) object.Object {
+	info := infos["integrate"]
+	if err := checkCalculusArgs(info, args); err != nil {
+		return err
+	}
+
+	f := newNumericFunction(info, args[0])
+	a := args[1].(*object.Number).Value
+	b := args[2].(*object.Number).Value
+	if math.IsNaN(a) || math.IsNaN(b) {
+		return newError("%q: bounds should be numbers, got %v and %v", info.name, a, b)
+	}
+
+	value, bound, converged := integrate(f.call, a, b)
+	if f.err != nil {
+		return f.err
+	}
+	if !converged || !isFinite(value) {
+		return newError("%q: the integral does not converge", info.name)
+	}
+	return &object.Estimate{Value: value, Error: bound}
+}
+
+func derivFunction(args ...object.Object) object.Object {
+	info := infos["deriv"]
+	if err := checkCalculusArgs(info, args); err != nil {
+		return err
+	}
+
+	f := newNumericFunction(info, args[0])
+	x := args[1].(*object.Number).Value
+	if !isFinite(x) {
+		return newError("%q: x should be finite, got %v", info.name, x)
+	}
+
+	value, bound := derivative(f.call, x)
+	if f.err != nil {
+		return f.err
+	}
+	if !isFinite(value) {
+		return newError("%q: the function is not differentiable at %v", info.name, x)
+	}
+	return &object.Estimate{Value: value, Error: bound}
+}
+
+func limitFunction(args ...object.Object) object.Object {
+	info := infos["limit"]
+	if err := checkCalculusArgs(info, args); err != nil {
+		return err
+	}
+
+	f := newNumericFunction(info, args[0])
+	x0 := args[1].(*object.Number).Value
+	if math.IsNaN(x0) {
+		return newError("%q: x0 should be a number, got %v", info.name, x0)
+	}
+
+	// x -> inf is t -> 0 of f(1/t)
+	g := f.call
+	if math.IsInf(x0, 0) {
+		g = func(t float64) float64 { return f.call(1 / t) }
+	}
+
+	var value, bound float64
+	switch {
+	case math.IsInf(x0, 1):
+		value, bound = sideLimit(g, 0, 1)
+	case math.IsInf(x0, -1):
+		value, bound = sideLimit(g, 0, -1)
+	default:
+		left, leftBound := sideLimit(g, x0, -1)
+		right, rightBound := sideLimit(g, x0, 1)
+		if f.err != nil {
+			return f.err
+		}
+
+		// the limit in the domain of f, e.g. ln at 0 is only defined on the right
+		if math.IsNaN(left) && !math.IsNaN(right) {
+			left, leftBound = right, rightBound
+		} else if math.IsNaN(right) && !math.IsNaN(left) {
+			right, rightBound = left, leftBound
+		}
+
+		tolerance := 1e3*(leftBound+rightBound) + 1e-8*math.Max(1, math.Abs(left))
+		isInfinite := math.IsInf(left, 0) || math.IsInf(right, 0)
+		if math.IsNaN(left) || math.IsNaN(right) || (left != right && (isInfinite || !(math.Abs(left-right) <= tolerance))) {
+			return newError(
+				"%q: the limit does not exist, the left limit is %v and the right limit is %v",
+				info.name, left, right,
+			)
+		}
+		value, bound = (left+right)/2, math.Max(leftBound, rightBound)+math.Abs(left-right)/2
+		if math.IsInf(left, 0) {
+			value, bound = left, 0
+		}
+	}
+
+	if f.err != nil {
+		return f.err
+	}
+	if math.IsNaN(value) {
+		return newError("%q: the limit does not exist", info.name)
+	}
+	return &object.Estimate{Value: value, Error: bound}
+}
+
+func seriesFunction(args ...object.Object) object.Object {
+	info := infos["series"]
+	if err := checkCalculusArgs(info, args); err != nil {
+		return err
+	}
+
+	f := newNumericFunction(info, args[0])
+	from := args[1].(*object.Number).Value
+	to := args[2].(*object.Number).Value
+	if from != math.Trunc(from) || math.IsInf(from, 0) {
+		return newError("%q: from should be an integer, got %v", info.name, from)
+	}
+	if to != math.Trunc(to) && !math.IsInf(to, 1) {
+		return newError("%q: to should be an integer or infinite, got %v", info.name, to)
+	}
+
+	var value, bound float64
+	var converged bool
+	if math.IsInf(to, 1) {
+		value, bound, converged = infiniteSeries(f.call, from)
+	} else {
+		if to-from >= maxSeriesTerms {
+			return newError("%q: too many terms, expect at most %d, got %v", info.name, maxSeriesTerms, to-from+1)
+		}
+		value, bound = finiteSeries(f.call, from, to)
+		converged = isFinite(value)
+	}
+
+	if f.err != nil {
+		return f.err
+	}
+	if !converged {
+		return newError("%q: the series does not converge", info.name)
+	}
+	return &object.Estimate{Value: value, Error: bound}
+}
+
+// checkCalculusArgs checks the `f, x...` arguments of the calculus builtins,
+// f can be a user or a builtin function.
+func checkCalculusArgs(info builtinFuncInfo, args []object.Object) *object.Error {
+	if err := checkArgsLength(info, args); err != nil {
+		return err
+	}
+	if !isFunction(args[0]) {
+		return newError(
+			"argument index 0 of function %q should be type %s, got %s",
+			info.name, object.FUNCTION_OBJ, args[0].Type(),
+		)
+	}
+	return checkArgsType(
+		builtinFuncInfo{name: info.name, types: info.types[1:]},
+		args[1:],
+	)
+}
+
+// numericFunction calls a qcal function with a number. The first error stops
+// the later calls, which return NaN, so the numerical methods need no error
+// handling.
+type numericFunction struct {
+	info builtinFuncInfo
+	fn   object.Object
+	err  *object.Error
+}
+
+func newNumericFunction(info builtinFuncInfo, fn object.Object) *numericFunction {
+	return &numericFunction{info: info, fn: fn}
+}
+
+func (f *numericFunction) call(x float64) float64 {
+	if f.err != nil {
+		return math.NaN()
+	}
+
+	result := unwrapEstimate(applyFunction(f.fn, []object.Object{newNumber(x)}))
+	if isDomainError(result) {
+		return math.NaN() // e.g. ln at the sample points left of 0
+	}
+	if err, ok := result.(*object.Error); ok {
+		f.err = err
+		return math.NaN()
+	}
+
+	number, ok := result.(*object.Number)
+	if !ok {
+		f.err = newError("%q: function should return %s, got %s", f.info.name, object.NUMBER_OBJ, typeOf(result))
+		return math.NaN()
+	}
+	return number.Value
+}
+
+// Integration --------------------------------------------------------------------- //
+
+// nodes and weights of the 15 point Kronrod rule on [-1, 1], the 7 point Gauss
+// rule uses the odd nodes.
+var (
+	kronrodNodes = [8]float64{
+		0.991455371120812639206854697526329,
+		0.949107912342758524526189684047851,
+		0.864864423359769072789712788640926,
+		0.741531185599394439863864773280788,
+		0.586087235467691130294144845693013,
+		0.405845151377397166906606412076961,
+		0.207784955007898467600689403773245,
+		0,
+	}
+	kronrodWeights = [8]float64{
+		0.022935322010529224963732008058970,
+		0.063092092629978553290700663189204,
+		0.104790010322250183839876322541518,
+		0.140653259715525918745189590510238,
+		0.169004726639267902826583426598550,
+		0.190350578064785409913256402421014,
+		0.204432940075298892414161999234649,
+		0.209482141084727828012999174891714,
+	}
+	gaussWeights = [4]float64{
+		0.129484966168869693270611432679082,
+		0.279705391489276667901467771423780,
+		0.381830050505118944950369775488975,
+		0.417959183673469387755102040816327,
+	}
+)
+
+const (
+	integrateTolerance    = 1e-10
+	integrateMaxIntervals = 2000
+)
+
+// integrate returns the integral of f from a to b and its error bound, using
+// adaptive Gauss-Kronrod quadrature. Infinite bounds are mapped to a finite
+// interval, the whole line is split at 0 so that the divergent halves of an odd
+// function do not cancel. It reports false if the error bound does not reach
+// the tolerance.
+func integrate(f func(float64) float64, a, b float64) (float64, float64, bool) {
+	switch {
+	case a == b:
+		return 0, 0, true
+	case a > b:
+		value, bound, converged := integrate(f, b, a)
+		return -value, bound, converged
+	case math.IsInf(a, -1) && math.IsInf(b, 1):
+		left, leftBound, leftConverged := integrate(f, a, 0)
+		right, rightBound, rightConverged := integrate(f, 0, b)
+		return left + right, leftBound + rightBound, leftConverged && rightConverged
+	case math.IsInf(b, 1):
+		// x = a + t / (1 - t)
+		return adaptiveIntegrate(func(t float64) float64 {
+			return f(a+t/(1-t)) / ((1 - t) * (1 - t))
+		}, 0, 1)
+	case math.IsInf(a, -1):
+		// x = b - (1 - t) / t
+		return adaptiveIntegrate(func(t float64) float64 {
+			return f(b-(1-t)/t) / (t * t)
+		}, 0, 1)
+	default:
+		return adaptiveIntegrate(f, a, b)
+	}
+}
+
+// interval is a part of the integral with its estimate and error bound.
+type interval struct {
+	a, b, value, bound float64
+}
+
+// adaptiveIntegrate splits the interval with the largest error bound until the
+// total error bound is below the tolerance. It reports false if the intervals
+// run out first, e.g. for the integral of 1/x from 0 to 1.
+func adaptiveIntegrate(f func(float64) float64, a, b float64) (float64, float64, bool) {
+	value, bound := gaussKronrod(f, a, b)
+	intervals := []interval{{a, b, value, bound}}
+
+	for {
+		if !isFinite(value) {
+			return value, bound, false
+		}
+		if bound <= math.Max(integrateTolerance*math.Abs(value), 1e-14) {
+			return value, bound, true
+		}
+		if len(intervals) == integrateMaxIntervals {
+			return value, bound, false
+		}
+
+		worst := -1
+		for i, part := range intervals {
+			if mid := part.a + (part.b-part.a)/2; mid == part.a || mid == part.b {
+				continue // too small to split
+			}
+			if worst < 0 || part.bound > intervals[worst].bound {
+				worst = i
+			}
+		}
+		if worst < 0 {
+			return value, bound, false
+		}
+
+		part := intervals[worst]
+		mid := part.a + (part.b-part.a)/2
+		left, leftBound := gaussKronrod(f, part.a, mid)
+		right, rightBound := gaussKronrod(f, mid, part.b)
+		intervals[worst] = interval{part.a, mid, left, leftBound}
+		intervals = append(intervals, interval{mid, part.b, right, rightBound})
+
+		value, bound = 0, 0
+		for _, part := range intervals {
+			value += part.value
+			bound += part.bound
+		}
+	}
+}
+
+// gaussKronrod returns the 15 point Kronrod estimate and its difference from
+// the 7 point Gauss estimate as the error bound.
+func gaussKronrod(f func(float64) float64, a, b float64) (float64, float64) {
+	center, half := (a+b)/2, (b-a)/2
+
+	fc := f(center)
+	kronrod := fc * kronrodWeights[7]
+	gauss := fc * gaussWeights[3]
+	for i := 0; i < 7; i++ {
+		dx := half * kronrodNodes[i]
+		sum := f(center-dx) + f(center+dx)
+		kronrod += kronrodWeights[i] * sum
+		if i%2 == 1 {
+			gauss += gaussWeights[i/2] * sum
+		}
+	}
+
+	return kronrod * half, math.Abs((kronrod - gauss) * half)
+}
+
+// Differentiation ----------------------------------------------------------------- //
+
+// derivative returns f'(x) and its error bound, using Ridders' method: the
+// central differences with shrinking steps are extrapolated to a zero step.
+func derivative(f func(float64) float64, x float64) (float64, float64) {
+	const (
+		shrink = 1.4
+		size   = 10
+		safe   = 2.0
+	)
+
+	h := 0.1 * math.Max(1, math.Abs(x))
+	var table [size][size]float64
+	table[0][0] = (f(x+h) - f(x-h)) / (2 * h)
+
+	result, bound := table[0][0], math.Inf(1)
+	for i := 1; i < size; i++ {
+		h /= shrink
+		table[0][i] = (f(x+h) - f(x-h)) / (2 * h)
+
+		factor := shrink * shrink
+		for j := 1; j <= i; j++ {
+			table[j][i] = (table[j-1][i]*factor - table[j-1][i-1]) / (factor - 1)
+			factor *= shrink * shrink
+
+			e := math.Max(math.Abs(table[j][i]-table[j-1][i]), math.Abs(table[j][i]-table[j-1][i-1]))
+			if e <= bound {
+				result, bound = table[j][i], e
+			}
+		}
+
+		// stop when the higher order gets worse
+		if math.Abs(table[i][i]-table[i-1][i-1]) >= safe*bound {
+			break
+		}
+	}
+
+	return result, bound
+}
+
+// Limit --------------------------------------------------------------------------- //
+
+// sideLimit returns the limit of f(x) as x approaches x0 from the side of the
+// direction, and its error bound. The values at x0 + direction * h for halving
+// steps h are extrapolated to a zero step.
+func sideLimit(f func(float64) float64, x0, direction float64) (float64, float64) {
+	const size = 12
+
+	h := 0.1 * math.Max(1, math.Abs(x0))
+	var table [size][size]float64
+
+	result, bound := math.NaN(), math.Inf(1)
+	for i := 0; i < size; i++ {
+		table[0][i] = f(x0 + direction*h)
+		h /= 2
+
+		if !isFinite(table[0][i]) {
+			return table[0][i], 0
+		}
+		if diverging, sign := isDiverging(table[0][:i+1]); diverging {
+			return math.Copysign(math.Inf(1), sign), 0
+		}
+
+		factor := 2.0
+		for j := 1; j <= i; j++ {
+			table[j][i] = table[j-1][i] + (table[j-1][i]-table[j-1][i-1])/(factor-1)
+			factor *= 2
+
+			e := math.Max(math.Abs(table[j][i]-table[j-1][i]), math.Abs(table[j][i]-table[j-1][i-1]))
+			if e <= bound {
+				result, bound = table[j][i], e
+			}
+		}
+	}
+
+	if math.IsNaN(result) {
+		return table[0][size-1], math.Inf(1)
+	}
+	return result, bound
+}
+
+// isDiverging reports whether the values at the halving steps grow without
+// bound, e.g. 1/x^2 near 0, or move by steps which do not shrink, e.g. ln(x)
+// near 0, and the sign of the infinity.
+func isDiverging(ys []float64) (bool, float64) {
+	const steps = 4
+
+	n := len(ys)
+	if n < 3 {
+		return false, 0
+	}
+	y0, y1, y2 := ys[n-3], ys[n-2], ys[n-1]
+	if math.Abs(y2) > 1e3 && math.Abs(y2) > 1.5*math.Abs(y1) && math.Abs(y1) > 1.5*math.Abs(y0) &&
+		math.Signbit(y0) == math.Signbit(y2) {
+		return true, y2
+	}
+
+	if n < steps+2 {
+		return false, 0
+	}
+	for i := n - steps; i < n; i++ {
+		step, previous := ys[i]-ys[i-1], ys[i-1]-ys[i-2]
+		if step == 0 || math.Signbit(step) != math.Signbit(previous) || math.Abs(step) < 0.99*math.Abs(previous) {
+			return false, 0
+		}
+	}
+	return true, y2 - y1
+}
+
+// Series -------------------------------------------------------------------------- //
+
+const maxSeriesTerms = 10_000_000
+
+// finiteSeries returns the sum of f(n) for the integers n from from to to, and
+// its rounding error bound. The sum is compensated (Kahan summation).
+func finiteSeries(f func(float64) float64, from, to float64) (float64, float64) {
+	sum, compensation, magnitude := 0.0, 0.0, 0.0
+	for n := from; n <= to; n++ {
+		term := f(n)
+		if math.IsNaN(term) {
+			return term, 0
+		}
+		y := term - compensation
+		t := sum + y
+		compensation = (t - sum) - y
+		sum = t
+		magnitude += math.Abs(term)
+	}
+	return sum, 2 * epsilon * magnitude
+}
+
+const epsilon = 0x1p-52
+
+// infiniteSeries returns the sum of f(n) for the integers n from from, and its
+// error bound. The partial sums of 2^k and 2^k + 1 terms are accelerated with
+// Aitken's delta-squared process, the series converges when the accelerated
+// sums agree and the terms tend to 0, so the sums of e.g. (-1)^n which are
+// only equal at even counts do not converge.
+func infiniteSeries(f func(float64) float64, from float64) (float64, float64, bool) {
+	const maxDoublings = 20
+
+	var evens, odds []float64
+	previous, largest := math.NaN(), 0.0
+	sum, compensation, n, count := 0.0, 0.0, from, 1
+
+	// term keeps the last term, which is added after its odd partial sum
+	lastN, lastTerm := math.NaN(), 0.0
+	term := func(n float64) float64 {
+		if n != lastN {
+			lastN, lastTerm = n, f(n)
+			largest = math.Max(largest, math.Abs(lastTerm))
+		}
+		return lastTerm
+	}
+
+	for k := 0; k <= maxDoublings; k++ {
+		for ; n < from+float64(count); n++ {
+			term := term(n)
+			if !isFinite(term) {
+				return math.NaN(), 0, false
+			}
+			y := term - compensation
+			t := sum + y
+			compensation = (t - sum) - y
+			sum = t
+		}
+		count *= 2
+
+		next := term(n)
+		if !isFinite(sum) || !isFinite(next) {
+			return math.NaN(), 0, false
+		}
+		evens = append(evens, sum)
+		odds = append(odds, sum+next)
+		if len(evens) < 3 {
+			continue
+		}
+
+		accelerated := aitken(evens)
+		bound := math.Max(math.Abs(accelerated-previous), math.Abs(accelerated-aitken(odds)))
+		if k >= 4 && bound <= 1e-10*math.Max(1, math.Abs(accelerated)) && math.Abs(next) <= 1e-3*largest {
+			return accelerated, bound, true
+		}
+		previous = accelerated
+	}
+
+	return math.NaN(), 0, false
+}
+
+// aitken accelerates the last three partial sums with Aitken's delta-squared
+// process, if they converge.
+func aitken(partials []float64) float64 {
+	s0, s1, s2 := partials[len(partials)-3], partials[len(partials)-2], partials[len(partials)-1]
+	if d := (s2 - s1) - (s1 - s0); d != 0 && math.Abs(s2-s1) < math.Abs(s1-s0) {
+		return s2 - (s2-s1)*(s2-s1)/d
+	}
+	return s2
+}
+
+func isFinite(value float64) bool {
+	return !math.IsNaN(value) && !math.IsInf(value, 0)
+}
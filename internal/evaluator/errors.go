@@ -0,0 +1,162 @@
+package evaluator
+
+import (
+	"github.com/DeepAung/qcal/internal/ast"
+	"github.com/DeepAung/qcal/internal/object"
+	"github.com/DeepAung/qcal/internal/token"
+)
+
+func raiseFunction(args ...object.Object) object.Object {
+	info := infos["raise"]
+	if err := checkArgsLength(info, args); err != nil {
+		return err
+	}
+	// the caught error is raised again with its own code and position
+	if caught, ok := args[0].(*object.ErrorValue); ok && len(args) == 1 {
+		return caught.Error
+	}
+	if err := checkArgsType(info, args); err != nil {
+		return err
+	}
+
+	code := "error"
+	if len(args) == 2 {
+		code = args[1].(*object.String).Value
+	}
+	return &object.Error{Message: args[0].(*object.String).Value, Code: code}
+}
+
+func assertFunction(args ...object.Object) object.Object {
+	info := infos["assert"]
+	if err := checkArgsLength(info, args); err != nil {
+		return err
+	}
+	if err := checkArgsType(info, args); err != nil {
+		return err
+	}
+
+	if args[0].(*object.Boolean).Value {
+		return TRUE
+	}
+	message := "assertion failed"
+	if len(args) == 2 {
+		message = args[1].(*object.String).Value
+	}
+	return &object.Error{Message: message, Code: "assert"}
+}
+
+// evalTryExpression evaluates the body, and the handler with the error of the
+// body bound to the variable if the body fails.
+func evalTryExpression(te *ast.TryExpression, env *object.Environment) object.Object {
+	result := Eval(te.Body, object.NewBlockEnvironment(env))
+	err, ok := result.(*object.Error)
+	if !ok {
+		return result
+	}
+
+	handlerEnv := object.NewBlockEnvironment(env)
+	if te.Variable != nil {
+		handlerEnv.Set(te.Variable.Value, &object.ErrorValue{Error: err})
+	}
+	return Eval(te.Handler, handlerEnv)
+}
+
+// evalDotExpression evaluates `e.message`, the member of a caught error,
+// `lib.fn`, the binding of a module, or `f . g`, the composition of functions.
+func evalDotExpression(ie *ast.InfixExpression, env *object.Environment) object.Object {
+	left := Eval(ie.Left, env)
+	if IsError(left) {
+		return left
+	}
+	if member, ok := ie.Right.(*ast.Identifier); ok {
+		switch left := left.(type) {
+		case *object.ErrorValue:
+			return errorMember(left.Error, member.Value)
+		case *object.Module:
+			return moduleMember(left, member.Value)
+		}
+	}
+
+	right := Eval(ie.Right, env)
+	if IsError(right) {
+		return right
+	}
+	return evalInfixExpression(ie.Operator, unwrapEstimate(left), unwrapEstimate(right))
+}
+
+// errorMember returns the message, the code or the position [line, column] of
+// the error, the position is null if it is unknown.
+func errorMember(err *object.Error, name string) object.Object {
+	switch name {
+	case "message":
+		return &object.String{Value: err.Message}
+	case "code":
+		return &object.String{Value: err.Code}
+	case "position":
+		if err.Line == 0 {
+			return NULL
+		}
+		return &object.List{Elements: []object.Object{
+			newNumber(float64(err.Line)),
+			newNumber(float64(err.Column)),
+		}}
+	default:
+		return newError("unknown member %s of %s, expect=message, code or position", name, object.ERROR_VALUE_OBJ)
+	}
+}
+
+// locateError gives the error result the position of the node if it has
+// none yet.
+func locateError(result object.Object, node ast.Node) object.Object {
+	if err, ok := result.(*object.Error); ok && err.Line == 0 {
+		tok, ok := nodeToken(node)
+		if ok {
+			err.Line, err.Column = tok.Line, tok.Column
+		}
+	}
+	return result
+}
+
+// nodeToken returns the token which the position of an error in the node is
+// reported at, a call is reported at its function.
+func nodeToken(node ast.Node) (token.Token, bool) {
+	switch node := node.(type) {
+	case *ast.LetStatement:
+		return node.Token, true
+	case *ast.ReturnStatement:
+		return node.Token, true
+	case *ast.BreakStatement:
+		return node.Token, true
+	case *ast.ContinueStatement:
+		return node.Token, true
+	case *ast.ImportStatement:
+		return node.Token, true
+	case *ast.ExpressionStatement:
+		return node.Token, true
+	case *ast.Identifier:
+		return node.Token, true
+	case *ast.NumberLiteral:
+		return node.Token, true
+	case *ast.ListLiteral:
+		return node.Token, true
+	case *ast.PrefixExpression:
+		return node.Token, true
+	case *ast.PostfixExpression:
+		return node.Token, true
+	case *ast.InfixExpression:
+		return node.Token, true
+	case *ast.IfExpression:
+		return node.Token, true
+	case *ast.WhileExpression:
+		return node.Token, true
+	case *ast.ForExpression:
+		return node.Token, true
+	case *ast.NamedArgument:
+		return node.Token, true
+	case *ast.CallExpression:
+		return nodeToken(node.Function)
+	case *ast.IndexExpression:
+		return node.Token, true
+	}
+	return token.Token{}, false
+}
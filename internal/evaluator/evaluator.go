@@ -3,18 +3,31 @@ package evaluator
 import (
 	"fmt"
 	"math"
+	"math/big"
+	"slices"
 
 	"github.com/DeepAung/qcal/internal/ast"
+	"github.com/DeepAung/qcal/internal/matrix"
 	"github.com/DeepAung/qcal/internal/object"
+	"github.com/DeepAung/qcal/internal/polynomial"
 )
 
 var (
 	NULL  = &object.Null{}
 	TRUE  = &object.Boolean{Value: true}
 	FALSE = &object.Boolean{Value: false}
+
+	BREAK    = &object.Break{}
+	CONTINUE = &object.Continue{}
 )
 
+// Eval evaluates the node, an error is given the position of the innermost
+// node which returns it.
 func Eval(node ast.Node, env *object.Environment) object.Object {
+	return locateError(evalNode(node, env), node)
+}
+
+func evalNode(node ast.Node, env *object.Environment) object.Object {
 	switch node := node.(type) {
 	case *ast.Program:
 		return evalProgram(node, env)
@@ -26,14 +39,21 @@ func Eval(node ast.Node, env *object.Environment) object.Object {
 		return Eval(node.Expression, env)
 
 	case *ast.LetStatement:
-		if _, ok := builtinValues[node.Name.Value]; ok {
-			return newError("cannot assign value to the builtin constant %q", node.Name.Value)
+		if err := checkAssignable(node.Name.Value, node.Declare, env); err != nil {
+			return err
 		}
 		val := Eval(node.Value, env)
 		if IsError(val) {
 			return val
 		}
-		env.Set(node.Name.Value, val)
+		switch {
+		case node.Constant:
+			env.SetConst(node.Name.Value, val)
+		case node.Declare:
+			env.Set(node.Name.Value, val)
+		default:
+			env.Assign(node.Name.Value, val)
+		}
 		return &object.LetValue{Value: val}
 
 	case *ast.ReturnStatement:
@@ -43,12 +63,34 @@ func Eval(node ast.Node, env *object.Environment) object.Object {
 		}
 		return &object.ReturnValue{Value: val}
 
+	case *ast.BreakStatement:
+		return BREAK
+
+	case *ast.ContinueStatement:
+		return CONTINUE
+
+	case *ast.ImportStatement:
+		return evalImportStatement(node, env)
+
 	case *ast.NumberLiteral:
-		return &object.Number{Value: node.Value}
+		return evalNumberLiteral(node)
 
 	case *ast.BooleanLiteral:
 		return booleanObject(node.Value)
 
+	case *ast.StringLiteral:
+		return &object.String{Value: node.Value}
+
+	case *ast.ListLiteral:
+		elements := evalExpressions(node.Elements, env)
+		if len(elements) == 1 && IsError(elements[0]) {
+			return elements[0]
+		}
+		if m, ok := newMatrixLiteral(elements); ok {
+			return m
+		}
+		return &object.List{Elements: elements}
+
 	case *ast.Identifier:
 		return evalIdentifier(node, env)
 
@@ -63,16 +105,19 @@ func Eval(node ast.Node, env *object.Environment) object.Object {
 		if IsError(right) {
 			return right
 		}
-		return evalPrefixExpression(node.Operator, right)
+		return evalPrefixExpression(node.Operator, unwrapEstimate(right))
 
 	case *ast.PostfixExpression:
 		left := Eval(node.Left, env)
 		if IsError(left) {
 			return left
 		}
-		return evalPostfixExpression(node.Operator, left)
+		return evalPostfixExpression(node.Operator, unwrapEstimate(left))
 
 	case *ast.InfixExpression:
+		if node.Operator == "." {
+			return evalDotExpression(node, env)
+		}
 		left := Eval(node.Left, env)
 		if IsError(left) {
 			return left
@@ -81,24 +126,62 @@ func Eval(node ast.Node, env *object.Environment) object.Object {
 		if IsError(right) {
 			return right
 		}
-		return evalInfixExpression(node.Operator, left, right)
+		return evalInfixExpression(node.Operator, unwrapEstimate(left), unwrapEstimate(right))
+
+	case *ast.EquationExpression:
+		return &object.Equation{Left: node.Left, Right: node.Right, Env: env}
 
 	case *ast.IfExpression:
 		return evalIfExpression(node, env)
 
+	case *ast.WhileExpression:
+		return evalWhileExpression(node, env)
+
+	case *ast.ForExpression:
+		return evalForExpression(node, env)
+
+	case *ast.TryExpression:
+		return evalTryExpression(node, env)
+
+	case *ast.NamedArgument:
+		val := Eval(node.Value, env)
+		if IsError(val) {
+			return val
+		}
+		return &object.NamedArgument{Name: node.Name.Value, Value: unwrapEstimate(val)}
+
 	case *ast.CallExpression:
 		fn := Eval(node.Function, env)
 		if IsError(fn) {
 			return fn
 		}
 
-		args := evalExpressions(node.Arguments, env)
-		if len(args) == 1 && IsError(args[0]) {
-			return args[0]
+		var args []object.Object
+		if eq, ok := equationArgument(node, env); ok {
+			args = append([]object.Object{eq}, evalExpressions(node.Arguments[1:], env)...)
+			if len(args) == 2 && IsError(args[1]) {
+				return args[1]
+			}
+		} else {
+			args = evalExpressions(node.Arguments, env)
+			if len(args) == 1 && IsError(args[0]) {
+				return args[0]
+			}
 		}
 
 		return applyFunction(fn, args)
 
+	case *ast.IndexExpression:
+		left := Eval(node.Left, env)
+		if IsError(left) {
+			return left
+		}
+		index := Eval(node.Index, env)
+		if IsError(index) {
+			return index
+		}
+		return evalIndexExpression(unwrapEstimate(left), unwrapEstimate(index))
+
 	}
 
 	return nil
@@ -115,6 +198,8 @@ func evalProgram(program *ast.Program, env *object.Environment) object.Object {
 			return result.Value
 		case *object.Error:
 			return result
+		case *object.Break, *object.Continue:
+			return newError("%s outside of a loop", result.Inspect())
 		}
 	}
 
@@ -128,7 +213,7 @@ func evalBlockStatement(block *ast.BlockStatement, env *object.Environment) obje
 		result = Eval(stmt, env)
 
 		switch result := result.(type) {
-		case *object.ReturnValue, *object.Error:
+		case *object.ReturnValue, *object.Error, *object.Break, *object.Continue:
 			return result
 		}
 	}
@@ -152,10 +237,26 @@ func evalBangOperatorPrefixExpression(right object.Object) object.Object {
 }
 
 func evalMinusOperatorPrefixExpression(right object.Object) object.Object {
+	switch right := right.(type) {
+	case *object.Polynomial:
+		return &object.Polynomial{Coeffs: polynomial.Scale(right.Coeffs, -1)}
+	case *object.Complex:
+		return &object.Complex{Value: -right.Value}
+	case *object.Matrix:
+		return &object.Matrix{Rows: matrix.Scale(right.Rows, -1)}
+	case *object.List:
+		if v, ok := vectorValues(right); ok {
+			return newVector(scaleVector(v, -1))
+		}
+	}
+
 	if right.Type() != object.NUMBER_OBJ {
 		return newError("unknown operator: -%s", right.Type())
 	}
 
+	if exact := right.(*object.Number).Exact; exact != nil {
+		return newInteger(new(big.Int).Neg(exact))
+	}
 	value := right.(*object.Number).Value
 	return &object.Number{Value: -value}
 }
@@ -169,27 +270,31 @@ func evalPostfixExpression(operator string, left object.Object) object.Object {
 	}
 }
 
-// TODO:
 func evalBangOperatorPostfixExpression(left object.Object) object.Object {
 	if left.Type() != object.NUMBER_OBJ {
 		return newError("unknown operator: %s!", left.Type())
 	}
-
-	var n int64 = int64(math.Round(left.(*object.Number).Value))
-	var result int64 = 1
-	for i := int64(2); i <= n; i++ {
-		result *= i
-	}
-
-	return &object.Number{Value: float64(result)}
+	return factorial(left.(*object.Number))
 }
 
 func evalInfixExpression(operator string, left, right object.Object) object.Object {
 	switch {
+	case operator == "∘" || operator == ".":
+		return evalComposeInfixExpression(operator, left, right)
 	case left.Type() == object.NUMBER_OBJ && right.Type() == object.NUMBER_OBJ:
 		return evalNumberInfixExpression(operator, left, right)
 	case left.Type() == object.BOOLEAN_OBJ && right.Type() == object.BOOLEAN_OBJ:
 		return evalBooleanInfixExpression(operator, left, right)
+	case left.Type() == object.STRING_OBJ && right.Type() == object.STRING_OBJ:
+		return evalStringInfixExpression(operator, left, right)
+	case left.Type() == object.MATRIX_OBJ || right.Type() == object.MATRIX_OBJ:
+		return evalMatrixInfixExpression(operator, left, right)
+	case left.Type() == object.LIST_OBJ || right.Type() == object.LIST_OBJ:
+		return evalVectorInfixExpression(operator, left, right)
+	case isPolynomialOperand(left) && isPolynomialOperand(right):
+		return evalPolynomialInfixExpression(operator, left, right)
+	case isComplexOperand(left) && isComplexOperand(right):
+		return evalComplexInfixExpression(operator, left, right)
 	case left.Type() != right.Type():
 		return newError("type mismatch: %s %s %s", left.Type(), operator, right.Type())
 	default:
@@ -197,7 +302,21 @@ func evalInfixExpression(operator string, left, right object.Object) object.Obje
 	}
 }
 
+// isPolynomialOperand reports whether obj is a polynomial or a number, only
+// one operand needs to be a polynomial as the numbers are handled before.
+func isPolynomialOperand(obj object.Object) bool {
+	return obj.Type() == object.POLYNOMIAL_OBJ || obj.Type() == object.NUMBER_OBJ
+}
+
+func isComplexOperand(obj object.Object) bool {
+	return obj.Type() == object.COMPLEX_OBJ || obj.Type() == object.NUMBER_OBJ
+}
+
 func evalNumberInfixExpression(operator string, left, right object.Object) object.Object {
+	if result, ok := evalExactInfixExpression(operator, left.(*object.Number), right.(*object.Number)); ok {
+		return result
+	}
+
 	leftValue := left.(*object.Number).Value
 	rightValue := right.(*object.Number).Value
 
@@ -209,10 +328,17 @@ func evalNumberInfixExpression(operator string, left, right object.Object) objec
 	case "*":
 		return &object.Number{Value: leftValue * rightValue}
 	case "/":
+		if rightValue == 0 {
+			return newDomainError("division by zero: %s / %s", left.Inspect(), right.Inspect())
+		}
 		return &object.Number{Value: leftValue / rightValue}
 	case "%":
+		divisor := int64(math.Round(rightValue))
+		if divisor == 0 {
+			return newDomainError("division by zero: %s %% %s", left.Inspect(), right.Inspect())
+		}
 		return &object.Number{
-			Value: float64(int64(math.Round(leftValue)) % int64(math.Round(rightValue))),
+			Value: float64(int64(math.Round(leftValue)) % divisor),
 		}
 	case "^":
 		return &object.Number{Value: math.Pow(leftValue, rightValue)}
@@ -220,6 +346,10 @@ func evalNumberInfixExpression(operator string, left, right object.Object) objec
 		return booleanObject(leftValue < rightValue)
 	case ">":
 		return booleanObject(leftValue > rightValue)
+	case "<=":
+		return booleanObject(leftValue <= rightValue)
+	case ">=":
+		return booleanObject(leftValue >= rightValue)
 	case "==":
 		return booleanObject(leftValue == rightValue)
 	case "!=":
@@ -240,6 +370,22 @@ func evalBooleanInfixExpression(operator string, left, right object.Object) obje
 	}
 }
 
+func evalStringInfixExpression(operator string, left, right object.Object) object.Object {
+	leftValue := left.(*object.String).Value
+	rightValue := right.(*object.String).Value
+
+	switch operator {
+	case "+":
+		return &object.String{Value: leftValue + rightValue}
+	case "==":
+		return booleanObject(leftValue == rightValue)
+	case "!=":
+		return booleanObject(leftValue != rightValue)
+	default:
+		return newError("unknown operator: %s %s %s", left.Type(), operator, right.Type())
+	}
+}
+
 func evalIfExpression(ie *ast.IfExpression, env *object.Environment) object.Object {
 	condition := Eval(ie.Condition, env)
 	if IsError(condition) {
@@ -247,14 +393,140 @@ func evalIfExpression(ie *ast.IfExpression, env *object.Environment) object.Obje
 	}
 
 	if isTruthy(condition) {
-		return Eval(ie.Consequence, env)
+		return Eval(ie.Consequence, object.NewBlockEnvironment(env))
 	} else if ie.Alternative != nil {
-		return Eval(ie.Alternative, env)
+		return Eval(ie.Alternative, object.NewBlockEnvironment(env))
 	} else {
 		return NULL
 	}
 }
 
+// evalWhileExpression evaluates the body while the condition is truthy, it
+// returns the value of the last iteration, or null.
+func evalWhileExpression(we *ast.WhileExpression, env *object.Environment) object.Object {
+	var result object.Object = NULL
+
+	for {
+		condition := Eval(we.Condition, env)
+		if IsError(condition) {
+			return condition
+		}
+		if !isTruthy(condition) {
+			return result
+		}
+
+		var stop bool
+		if result, stop = evalLoopBody(we.Body, object.NewBlockEnvironment(env), result); stop {
+			return result
+		}
+	}
+}
+
+// evalForExpression evaluates the body for each element of the iterable, a
+// list or the rows of a matrix. The variable is bound in the scope of each
+// iteration.
+func evalForExpression(fe *ast.ForExpression, env *object.Environment) object.Object {
+	name := fe.Variable.Value
+	if _, ok := builtinValues[name]; ok {
+		return newError("cannot assign value to the builtin constant %q", name)
+	}
+
+	iterable := Eval(fe.Iterable, env)
+	if IsError(iterable) {
+		return iterable
+	}
+
+	var elements []object.Object
+	switch iterable := unwrapEstimate(iterable).(type) {
+	case *object.List:
+		elements = iterable.Elements
+	case *object.Matrix:
+		for _, row := range iterable.Rows {
+			elements = append(elements, newVector(row))
+		}
+	default:
+		return newError("cannot iterate over %s", iterable.Type())
+	}
+
+	var result object.Object = NULL
+	for _, element := range elements {
+		iterationEnv := object.NewBlockEnvironment(env)
+		iterationEnv.Set(name, element)
+
+		var stop bool
+		if result, stop = evalLoopBody(fe.Body, iterationEnv, result); stop {
+			return result
+		}
+	}
+	return result
+}
+
+// checkAssignable returns an error if the binding of the name is a constant.
+// An assignment cannot change the constant of any scope, but a declaration
+// shadows the constants of the outer scopes, like the parameters.
+func checkAssignable(name string, declare bool, env *object.Environment) *object.Error {
+	if _, ok := builtinValues[name]; ok {
+		return newError("cannot assign value to the builtin constant %q", name)
+	}
+	if _, isLocal := env.GetLocal(name); (isLocal || !declare) && env.IsConst(name) {
+		return newError("cannot assign value to the constant %q", name)
+	}
+	return nil
+}
+
+// evalIndexExpression returns the element of a list or the row of a matrix as
+// a vector. The negative indices count from the end.
+func evalIndexExpression(left, index object.Object) object.Object {
+	var length int
+	switch left := left.(type) {
+	case *object.List:
+		length = len(left.Elements)
+	case *object.Matrix:
+		length = len(left.Rows)
+	default:
+		return newError("cannot index %s", left.Type())
+	}
+
+	n, ok := index.(*object.Number)
+	if !ok || n.Value != math.Trunc(n.Value) {
+		return newError("index should be an integer, got %s", index.Inspect())
+	}
+	i := n.Value
+	if i < 0 {
+		i += float64(length)
+	}
+	if i < 0 || i >= float64(length) {
+		return newError("index out of range, expect=-%d..%d, got=%s", length, length-1, n.Inspect())
+	}
+
+	if list, ok := left.(*object.List); ok {
+		return list.Elements[int(i)]
+	}
+	return newVector(left.(*object.Matrix).Rows[int(i)])
+}
+
+// evalLoopBody evaluates an iteration of the loop in its scope, and reports
+// whether the loop stops, by a `break`, a `return` or an error. The value of an empty or
+// continued iteration is the result of the previous one.
+func evalLoopBody(
+	body *ast.BlockStatement,
+	env *object.Environment,
+	result object.Object,
+) (object.Object, bool) {
+	switch evaluated := Eval(body, env).(type) {
+	case *object.ReturnValue, *object.Error:
+		return evaluated, true
+	case *object.Break:
+		return result, true
+	case *object.Continue, nil:
+		return result, false
+	case *object.LetValue:
+		return evaluated.Value, false
+	default:
+		return evaluated, false
+	}
+}
+
 func evalIdentifier(node *ast.Identifier, env *object.Environment) object.Object {
 	if val, ok := env.Get(node.Value); ok {
 		return val
@@ -268,9 +540,27 @@ func evalIdentifier(node *ast.Identifier, env *object.Environment) object.Object
 		return fn
 	}
 
+	if newFn, ok := randomFuncs[node.Value]; ok {
+		return newFn(env.Random())
+	}
+
+	if newFn, ok := fileFuncs[node.Value]; ok {
+		return newFn(!env.Sandboxed())
+	}
+
 	return newError("identifier not found: %s", node.Value)
 }
 
+// isBound reports whether the name is bound in the environment or builtin.
+func isBound(env *object.Environment, name string) bool {
+	_, isBound := env.Get(name)
+	_, isValue := builtinValues[name]
+	_, isFunc := builtinFuncs[name]
+	_, isRandom := randomFuncs[name]
+	_, isFile := fileFuncs[name]
+	return isBound || isValue || isFunc || isRandom || isFile
+}
+
 func evalExpressions(exps []ast.Expression, env *object.Environment) []object.Object {
 	var results []object.Object
 
@@ -279,60 +569,200 @@ func evalExpressions(exps []ast.Expression, env *object.Environment) []object.Ob
 		if IsError(obj) {
 			return []object.Object{obj}
 		}
-		results = append(results, obj)
+		results = append(results, unwrapEstimate(obj))
 	}
 
 	return results
 }
 
+// applyFunction calls the function, running the tail calls returned by the
+// function bodies in a loop, so tail recursion uses constant stack.
 func applyFunction(fn object.Object, args []object.Object) object.Object {
+	for {
+		result := callFunction(fn, args)
+		tailCall, ok := result.(*object.TailCall)
+		if !ok {
+			return result
+		}
+		fn, args = tailCall.Function, tailCall.Arguments
+	}
+}
+
+// callFunction calls the function once, the result of a function body may be
+// a tail call.
+func callFunction(fn object.Object, args []object.Object) object.Object {
 	switch fn := fn.(type) {
 
 	case *object.NormalFunction:
-		if len(args) < len(fn.Parameters) {
-			return newError("not enough arguments, expect=%d, got=%d", len(fn.Parameters), len(args))
-		} else if len(args) > len(fn.Parameters) {
-			return newError("too many arguments, expect=%d, got=%d", len(fn.Parameters), len(args))
-		}
-
-		extendedEnv := extendFunctionEnv(fn.Env, fn.Parameters, args)
-		evaluated := Eval(fn.Body, extendedEnv)
-		return unwrapReturnValue(evaluated)
+		return applyUserFunction(fn, fn.Parameters, fn.Body, fn.Env, args)
 
 	case *object.ConciseFunction:
-		if len(args) < len(fn.Parameters) {
-			return newError("not enough arguments, expect=%d, got=%d", len(fn.Parameters), len(args))
-		} else if len(args) > len(fn.Parameters) {
-			return newError("too many arguments, expect=%d, got=%d", len(fn.Parameters), len(args))
-		}
+		return applyUserFunction(fn, fn.Parameters, fn.Body, fn.Env, args)
+
+	case *object.MemoFunction:
+		return applyMemoFunction(fn, args)
 
-		extendedEnv := extendFunctionEnv(fn.Env, fn.Parameters, args)
-		evaluated := Eval(fn.Body, extendedEnv)
-		return unwrapReturnValue(evaluated)
+	case *object.PartialFunction:
+		return applyPartialFunction(fn, args)
+
+	case *object.ComposedFunction:
+		return applyComposedFunction(fn, args)
 
 	case object.BuiltinFunction:
+		if err := checkPositionalArgs(fn, args); err != nil {
+			return err
+		}
 		return fn(args...)
 
+	case *object.Polynomial:
+		if err := checkPositionalArgs(fn, args); err != nil {
+			return err
+		}
+		return applyPolynomial(fn, args)
+
 	default:
 		return newError("not a function: %s", fn.Type())
 	}
 }
 
-func extendFunctionEnv(
+// checkPositionalArgs returns an error if a named argument is passed to a
+// function without named parameters.
+func checkPositionalArgs(fn object.Object, args []object.Object) *object.Error {
+	for _, arg := range args {
+		if named, ok := arg.(*object.NamedArgument); ok {
+			return newError("named argument %s, %s has no named parameters", named.Name, fn.Type())
+		}
+	}
+	return nil
+}
+
+// applyUserFunction evaluates the body of a user function. A call without
+// all the parameters without a default value returns the function with the
+// given arguments, which waits for the others.
+func applyUserFunction(
+	fn object.Object,
+	params []*ast.Parameter,
+	body ast.Node,
 	env *object.Environment,
-	params []*ast.Identifier,
 	args []object.Object,
-) *object.Environment {
+) object.Object {
+	values, err := bindArguments(params, args)
+	if err != nil {
+		return err
+	}
+	if len(args) > 0 {
+		for i, param := range params {
+			if values[i] == nil && param.Default == nil && !param.Variadic {
+				return &object.PartialFunction{Function: fn, Arguments: args, Open: unboundParameters(params, values)}
+			}
+		}
+	}
+
+	extendedEnv, err := extendFunctionEnv(env, params, values)
+	if err != nil {
+		return err
+	}
+	evaluated := evalTail(body, extendedEnv)
+	return unwrapReturnValue(evaluated)
+}
+
+// bindArguments returns the values of the parameters from the positional
+// arguments, then from the named arguments, nil for the unbound parameters.
+// The variadic parameter is the list of the remaining positional arguments.
+func bindArguments(params []*ast.Parameter, args []object.Object) ([]object.Object, *object.Error) {
+	values := make([]object.Object, len(params))
+
+	positional := 0
+	for _, arg := range args {
+		if _, ok := arg.(*object.NamedArgument); ok {
+			break
+		}
+		positional++
+	}
+
+	for i, param := range params {
+		if param.Variadic {
+			if i < positional {
+				rest := append([]object.Object{}, args[i:positional]...)
+				values[i] = &object.List{Elements: rest}
+			}
+			positional = min(positional, i)
+			break
+		}
+		if i < positional {
+			values[i] = args[i]
+		}
+	}
+	if positional > len(params) {
+		return nil, newError(
+			"too many arguments, expect=%s, got=%d",
+			ast.ParametersString(params), positional,
+		)
+	}
+
+	for _, arg := range args[positional:] {
+		named, ok := arg.(*object.NamedArgument)
+		if !ok {
+			continue // the variadic arguments
+		}
+		i := slices.IndexFunc(params, func(param *ast.Parameter) bool {
+			return param.Name.Value == named.Name && !param.Variadic
+		})
+		switch {
+		case i == -1:
+			return nil, newError(
+				"unknown argument name %s, expect=%s",
+				named.Name, ast.ParametersString(params),
+			)
+		case values[i] != nil:
+			return nil, newError(
+				"multiple values for argument %s, expect=%s",
+				named.Name, ast.ParametersString(params),
+			)
+		}
+		values[i] = named.Value
+	}
+	return values, nil
+}
+
+// extendFunctionEnv binds the parameters to their values, an unbound variadic
+// parameter is an empty list and the default values of the other parameters
+// are evaluated in order in the function environment.
+func extendFunctionEnv(
+	env *object.Environment,
+	params []*ast.Parameter,
+	values []object.Object,
+) (*object.Environment, *object.Error) {
 	extendedEnv := object.NewEnclosedEnvironment(env)
 	for i, param := range params {
-		extendedEnv.Set(param.Value, args[i])
+		value := values[i]
+		switch {
+		case value != nil:
+		case param.Variadic:
+			value = &object.List{Elements: []object.Object{}}
+		case param.Default == nil:
+			return nil, newError(
+				"missing argument %s, expect=%s",
+				param.Name.Value, ast.ParametersString(params),
+			)
+		default:
+			val := Eval(param.Default, extendedEnv)
+			if IsError(val) {
+				return nil, val.(*object.Error)
+			}
+			value = unwrapEstimate(val)
+		}
+		extendedEnv.Set(param.Name.Value, value)
 	}
-	return extendedEnv
+	return extendedEnv, nil
 }
 
 func unwrapReturnValue(obj object.Object) object.Object {
-	if returnObj, ok := obj.(*object.ReturnValue); ok {
-		return returnObj.Value
+	switch obj := obj.(type) {
+	case *object.ReturnValue:
+		return obj.Value
+	case *object.Break, *object.Continue:
+		return newError("%s outside of a loop", obj.Inspect())
 	}
 
 	return obj
@@ -361,13 +791,34 @@ func isTruthy(obj object.Object) bool {
 }
 
 func newError(format string, a ...any) *object.Error {
-	return &object.Error{Message: fmt.Sprintf(format, a...)}
+	return &object.Error{Message: fmt.Sprintf(format, a...), Code: "runtime"}
+}
+
+// newDomainError returns the error of a value out of the domain of an
+// operation, e.g. ln(-1) or 1 / 0. The sampling builtins skip these values,
+// see isDomainError.
+func newDomainError(format string, a ...any) *object.Error {
+	return &object.Error{Message: fmt.Sprintf(format, a...), Code: "domain"}
+}
+
+func isDomainError(obj object.Object) bool {
+	err, ok := obj.(*object.Error)
+	return ok && err.Code == "domain"
 }
 
 func newNumber(val float64) *object.Number {
 	return &object.Number{Value: val}
 }
 
+// unwrapEstimate returns the value of an estimate as a number, the error bound
+// is not carried through the operations.
+func unwrapEstimate(obj object.Object) object.Object {
+	if estimate, ok := obj.(*object.Estimate); ok {
+		return newNumber(estimate.Value)
+	}
+	return obj
+}
+
 func IsError(obj object.Object) bool {
 	if obj == nil {
 		return false
@@ -0,0 +1,1028 @@
+package evaluator
+
+import (
+	"math"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/DeepAung/qcal/internal/lexer"
+	"github.com/DeepAung/qcal/internal/object"
+	"github.com/DeepAung/qcal/internal/parser"
+)
+
+func TestWhileExpression(t *testing.T) {
+	tests := []struct {
+		input    string
+		expected interface{}
+	}{
+		{"i = 0; while i < 5 { i = i + 1 }; i", 5},
+		{"i = 0; while (i < 5) { i = i + 1 }", 5},
+		{"while false { 1 }", nil},
+		{"i = 0; s = 0; while true { i = i + 1; if i > 4 { break }; s = s + i }; s", 10},
+		{"i = 0; s = 0; while i < 6 { i = i + 1; if i % 2 == 0 { continue }; s = s + i }; s", 9},
+		{"i = 0; while i < 3 { i = i + 1; i * 10 }", 30},
+		{"i = 0; while i < 3 { i = i + 1; if i == 3 { break }; i * 10 }", 20},
+	}
+
+	for _, tt := range tests {
+		testObject(t, tt.input, testEval(t, tt.input), tt.expected)
+	}
+}
+
+func TestForExpression(t *testing.T) {
+	tests := []struct {
+		input    string
+		expected interface{}
+	}{
+		{"s = 0; for x in [1, 2, 3] { s = s + x }; s", 6},
+		{"s = 0; for x in range(5) { s = s + x }; s", 10},
+		{"for x in [] { x }", nil},
+		{"for x in range(1, 10) { if x * x > 20 { break }; x }", 4},
+		{"s = 0; for x in range(10) { if x % 3 != 0 { continue }; s = s + x }; s", 18},
+		{"s = 0; for row in [[1, 2], [3, 4]] { s = s + dot(row, [1, 0]) }; s", 4},
+		{"n = 0; for i in range(3) { for j in range(4) { if j == 2 { break }; n = n + 1 } }; n", 6},
+	}
+
+	for _, tt := range tests {
+		testObject(t, tt.input, testEval(t, tt.input), tt.expected)
+	}
+}
+
+func TestLoopInFunction(t *testing.T) {
+	tests := []struct {
+		input    string
+		expected interface{}
+	}{
+		{"f = n => { for i in range(n) { if i * i > n { return i } }; -1 }; f(50)", 8},
+		{"f = n => { for i in range(n) { if i * i > n { return i } }; -1 }; f(0)", -1},
+		{"f = n => { t = 0; i = 0; while i < n { i = i + 1; t = t + i }; t }; f(100)", 5050},
+		{"t = 1; f = n => { for i in range(n) { t = t + i }; t }; [f(4), t]", []interface{}{7, 7}},
+		{"t = 1; f = n => { let t = 0; for i in range(n) { t += i }; t }; [f(4), t]", []interface{}{6, 1}},
+	}
+
+	for _, tt := range tests {
+		testObject(t, tt.input, testEval(t, tt.input), tt.expected)
+	}
+}
+
+func TestLoopScoping(t *testing.T) {
+	tests := []struct {
+		input    string
+		expected interface{}
+	}{
+		{"x = 7; for x in [1, 2] { x }; x", 7},
+		{"x = 7; for x in [1, 2] { x = 10 }; x", 7},
+		{"for k in [1, 2] { k }; k", "identifier not found: k"},
+		{"x = 7; f = () => { for x in [1, 2] { x }; x }; f()", 7},
+		{"s = 0; for x in [1, 2] { y = x; s = s + y }; [s, y]", []interface{}{3, 2}},
+	}
+
+	for _, tt := range tests {
+		testObject(t, tt.input, testEval(t, tt.input), tt.expected)
+	}
+}
+
+func TestLoopErrors(t *testing.T) {
+	tests := []struct {
+		input    string
+		expected string
+	}{
+		{"break", "break outside of a loop"},
+		{"if true { continue }", "continue outside of a loop"},
+		{"f = () => { break }; while true { f() }", "break outside of a loop"},
+		{"for x in 5 { x }", "cannot iterate over NUMBER"},
+		{"for pi in [1] { pi }", `cannot assign value to the builtin constant "pi"`},
+		{"i = 0; while i < 3 { i = i + 1; if i == 2 { y } }", "identifier not found: y"},
+		{"while x { 1 }", "identifier not found: x"},
+		{"for x in range(1, 2, 0) { x }", `"range": step should not be 0`},
+	}
+
+	for _, tt := range tests {
+		testObject(t, tt.input, testEval(t, tt.input), tt.expected)
+	}
+}
+
+func TestRange(t *testing.T) {
+	tests := []struct {
+		input    string
+		expected interface{}
+	}{
+		{"range(3)", []interface{}{0, 1, 2}},
+		{"range(2, 5)", []interface{}{2, 3, 4}},
+		{"range(1, 2, 0.25)", []interface{}{1, 1.25, 1.5, 1.75}},
+		{"range(5, 0, -2)", []interface{}{5, 3, 1}},
+		{"range(0)", []interface{}{}},
+		{"range(3, 1)", []interface{}{}},
+		{"range()", `"range": not enough arguments, expect at least 1, got=0`},
+		{"range(1, 2, 3, 4)", `"range": too many arguments, expect at most 3, got=4`},
+		{"range(10^9)", `"range": the list is too long, 1e+09 elements`},
+	}
+
+	for _, tt := range tests {
+		testObject(t, tt.input, testEval(t, tt.input), tt.expected)
+	}
+}
+
+func TestComparison(t *testing.T) {
+	tests := []struct {
+		input    string
+		expected interface{}
+	}{
+		{"if 2 <= 2 { 1 } else { 0 }", 1},
+		{"if 3 <= 2 { 1 } else { 0 }", 0},
+		{"if 2 >= 2 { 1 } else { 0 }", 1},
+		{"if 1.5 >= 2 { 1 } else { 0 }", 0},
+		{"if 2^64 <= 2^64 + 1 { 1 } else { 0 }", 1},
+		{"if 2^64 + 1 >= 2^64 + 2 { 1 } else { 0 }", 0},
+	}
+
+	for _, tt := range tests {
+		testObject(t, tt.input, testEval(t, tt.input), tt.expected)
+	}
+}
+
+func TestTailCall(t *testing.T) {
+	tests := []struct {
+		input    string
+		expected interface{}
+	}{
+		{"count = (n, acc) => if n == 0 { acc } else { count(n - 1, acc + 1) }; count(1000000, 0)", 1000000},
+		{"f = n => { if n == 0 { return 0 }; return f(n - 1) }; f(1000000)", 0},
+		{"f = n => { m = n - 1; if m < 0 { m } else { f(m) } }; f(1000000)", -1},
+		{"f = n => { if n > 0 { return f(n - 1) } else { return 7 } }; f(1000)", 7},
+		{
+			"even = n => if n == 0 { 1 } else { odd(n - 1) }; odd = n => if n == 0 { 0 } else { even(n - 1) }; even(1000001)",
+			0,
+		},
+		{"sum = (n, acc) => if n == 0 { acc } else { sum(n - 1, acc + n) }; sum(1000, 0)", 500500},
+		{"f = n => if n == 0 { sqrt(16) } else { f(n - 1) }; f(1000)", 4},
+		{"fact = n => if n <= 1 { 1 } else { n * fact(n - 1) }; fact(10)", 3628800},
+		{"f = n => if n == 0 { g(1, 2) } else { f(n - 1) }; g = x => x; f(1000)", "too many arguments, expect=(x), got=2"},
+		{"f = n => if n == 0 { y } else { f(n - 1) }; f(1000)", "identifier not found: y"},
+	}
+
+	for _, tt := range tests {
+		testObject(t, tt.input, testEval(t, tt.input), tt.expected)
+	}
+}
+
+func TestMemo(t *testing.T) {
+	tests := []struct {
+		input    string
+		expected interface{}
+	}{
+		{"fib = memo(n => if n < 2 { n } else { fib(n - 1) + fib(n - 2) }); fib(80)", 23416728348467685},
+		{"f = memo(x => x * 2); [f(3), f(3), f(4)]", []interface{}{6, 6, 8}},
+		{"f = memo((x) => { t = x; for i in range(3) { t = t + i }; t }); f(1)", 4},
+		{"f = memo((x, y) => x ^ y); [f(2, 3), f(3, 2)]", []interface{}{8, 9}},
+		{"f = memo(xs => mean(xs)); [f([1, 2, 3]), f([2, 3, 4])]", []interface{}{2, 3}},
+		{"f = memo((g, x) => g(x)); [f(x => x + 1, 1), f(x => x + 2, 1)]", []interface{}{2, 3}},
+		{"f = memo(x => x); g = memo(f); g(2)", 2},
+		{"f = memo(x => { g = y => x + y; g(pi) }); f(0)", 3.141592653589793},
+		{"a = 2; memo(x => a * x)", `"memo": the function reads the outer variable a`},
+		{"memo(x => { y = y + x; y })", `"memo": the function reads the outer variable y`},
+		{"memo(x => x + rand())", `"memo": the function calls rand, which has side effects`},
+		{`memo(x => plotfile("plot.svg", sin, 0, x))`, `"memo": the function calls plotfile, which has side effects`},
+		{"f = memo(x => plot(sin, 0, x)); 1", 1},
+		{`message = 2; m = memo(x => try { raise("a") } catch err { err.message }); m(1) == "a"`, true},
+		{"k = 2; g = x => x * k; memo(n => g(n))", `"memo": the function calls the outer function g, which is not a constant`},
+		{"k = 2; const g = x => x * k; memo(n => g(n))", `"memo": the function calls g, which reads the outer variable k`},
+		{"k = 2; const g = x => x + k; memo(n => n |> g)", `"memo": the function calls g, which reads the outer variable k`},
+		{"k = 2; const sq = x => x ^ 2; const h = sq ∘ (x => x + k); memo(n => h(n))", `"memo": the function calls h, which reads the outer variable k`},
+		{
+			`const g = x => plotfile("plot.svg", sin, 0, x); const h = n => g(n); memo(n => h(n))`,
+			`"memo": the function calls h, which calls g, which calls plotfile, which has side effects`,
+		},
+		{"const k = 2; const g = x => x * k; h = memo(n => g(n)); [h(2), h(2)]", []interface{}{4, 4}},
+		{"const sq = x => x ^ 2; const f = partial((a, b) => sq(a) + b, 1); h = memo(n => f(n)); h(2)", 3},
+		{
+			"const even = n => if n == 0 { true } else { odd(n - 1) }; const odd = n => if n == 0 { false } else { even(n - 1) }; " +
+				"f = memo(n => even(n)); f(10)",
+			true,
+		},
+		{"memo(sin)", `argument index 0 of function "memo" should be a user function, got BUILTIN_FUNCTION`},
+		// the names bound after memo are checked at the first call
+		{"m = memo(n => helper(n)); k = 1; helper = x => x * k; m(1)", `"memo": the function calls the outer function helper, which is not a constant`},
+		{"m = memo(n => helper(n)); const helper = x => x * 2; m(1)", 2},
+		{"m = memo(n => helper(n)); m(1)", `"memo": the function calls helper, which is not defined`},
+		// the names of a block are local to it
+		{"a = 1; memo(x => { for a in [7] { 0 }; x + a })", `"memo": the function reads the outer variable a`},
+		{"a = 1; memo(x => { if x > 0 { let a = 2 }; x + a })", `"memo": the function reads the outer variable a`},
+		{`a = 1; memo(x => { try { raise("b") } catch a { 0 }; x + a })`, `"memo": the function reads the outer variable a`},
+		{"f = memo(x => { for i in range(3) { x = x + i }; x }); f(1)", 4},
+	}
+
+	for _, tt := range tests {
+		testObject(t, tt.input, testEval(t, tt.input), tt.expected)
+	}
+}
+
+func TestFunctionArguments(t *testing.T) {
+	tests := []struct {
+		input    string
+		expected interface{}
+	}{
+		{"f = (x, base = 10) => ln(x) / ln(base); f(100)", 2},
+		{"f = (x, base = 10) => ln(x) / ln(base); f(8, 2)", 3},
+		{"f = (x, base = 10) => ln(x) / ln(base); f(base = 2, x = 8)", 3},
+		{"f = (x, base = 10) => ln(x) / ln(base); f(8, base = 2)", 3},
+		{"f = (x, y = x * 2) => x + y; f(1)", 3},
+		{"b = 5; f = (x, y = b) => x + y; b = 6; f(1)", 7},
+		{"f = (...xs) => mean(xs); f(1, 2, 6)", 3},
+		{"f = (a, ...rest) => [a, rest]; f(1, 2, 3)", []interface{}{1, []interface{}{2, 3}}},
+		{"f = (a, ...rest) => [a, rest]; f(a = 1)", []interface{}{1, []interface{}{}}},
+		{"f = (a, b = 2, ...rest) => [a, b, rest]; f(1)", []interface{}{1, 2, []interface{}{}}},
+		{"f = x => x; f(x = 3)", 3},
+		{"f = memo((x, y = 1) => x * y); [f(2), f(2, y = 3), f(y = 4, x = 2)]", []interface{}{2, 6, 8}},
+		{"f = (x, base = 10) => x; f()", "missing argument x, expect=(x, base = 10)"},
+		{"f = (x, base = 10) => x; f(1, 2, 3)", "too many arguments, expect=(x, base = 10), got=3"},
+		{"f = (x, base = 10) => x; f(y = 1)", "unknown argument name y, expect=(x, base = 10)"},
+		{"f = (x, base = 10) => x; f(1, x = 2)", "multiple values for argument x, expect=(x, base = 10)"},
+		{"f = (a, ...rest) => a; f(rest = 1)", "unknown argument name rest, expect=(a, ...rest)"},
+		{"f = (x = y) => x; f()", "identifier not found: y"},
+		{"sqrt(x = 4)", "named argument x, BUILTIN_FUNCTION has no named parameters"},
+	}
+
+	for _, tt := range tests {
+		testObject(t, tt.input, testEval(t, tt.input), tt.expected)
+	}
+}
+
+func TestCallExpression(t *testing.T) {
+	tests := []struct {
+		input    string
+		expected interface{}
+	}{
+		{"(x => x * 2)(4)", 8},
+		{"((x, y) => { x - y })(5, 2)", 3},
+		{"fs = [x => x + 1, x => x * 10]; [fs[0](1), fs[1](1), fs[-1](2)]", []interface{}{2, 10, 20}},
+		{"a = -1; (if (a < 0) { abs } else { sqrt })(a)", 1},
+		{"adder = n => x => x + n; adder(2)(3)", 5},
+		{"f = n => if (n == 0) { 0 } else { (x => f(x))(n - 1) }; f(10000)", 0},
+		{"2(3)", "not a function: NUMBER"},
+	}
+
+	for _, tt := range tests {
+		testObject(t, tt.input, testEval(t, tt.input), tt.expected)
+	}
+}
+
+func TestIndexExpression(t *testing.T) {
+	tests := []struct {
+		input    string
+		expected interface{}
+	}{
+		{"[1, 2, 3][0]", 1},
+		{"xs = [1, 2, 3]; xs[1 + 1]", 3},
+		{"xs = [1, 2, 3]; xs[-3]", 1},
+		{"[[1, 2], [3]][0][1]", 2},
+		{"[[1, 2], [3, 4]][1]", []interface{}{3, 4}},
+		{"[1, 2][2]", "index out of range, expect=-2..1, got=2"},
+		{"[1, 2][-3]", "index out of range, expect=-2..1, got=-3"},
+		{"[1, 2][0.5]", "index should be an integer, got 0.5"},
+		{"5[0]", "cannot index NUMBER"},
+	}
+
+	for _, tt := range tests {
+		testObject(t, tt.input, testEval(t, tt.input), tt.expected)
+	}
+}
+
+func TestPipeline(t *testing.T) {
+	tests := []struct {
+		input    string
+		expected interface{}
+	}{
+		{"16 |> sqrt", 4},
+		{"double = x => x * 2; 3 + 1 |> double |> sqrt", 2.8284271247461903},
+		{"3 |> x => x * 2 |> y => y + 1", 7},
+		{"[1, 2, 3] |> (xs => dot(xs, xs)) |> (x => x - 4) == 10", true},
+		{"f = (x, y = 1) => x * y; 5 |> f", 5},
+		{"5 |> 2", "not a function: NUMBER"},
+	}
+
+	for _, tt := range tests {
+		testObject(t, tt.input, testEval(t, tt.input), tt.expected)
+	}
+}
+
+func TestCompose(t *testing.T) {
+	tests := []struct {
+		input    string
+		expected interface{}
+	}{
+		{"compose(sqrt, x => x + 7)(9)", 4},
+		{"f = x => x * 2; g = x => x + 1; [(f ∘ g)(1), (g ∘ f)(1), (f . g . f)(1)]", []interface{}{4, 3, 6}},
+		{"(abs ∘ ((x, y) => x - y))(1, 3)", 2},
+		{"add = (x, y) => x + y; (sqrt ∘ add)(y = 7, x = 2)", 3},
+		{"inc = x => x + 1; f = x => x; for i in range(100000) { f = inc ∘ f }; f(0)", 100000},
+		{"compose(sqrt, 2)", "argument index 1 of function \"compose\" should be type FUNCTION, got NUMBER"},
+		{"sqrt ∘ 2", "unknown operator: BUILTIN_FUNCTION ∘ NUMBER"},
+		{"(sqrt ∘ (x => y))(1)", "identifier not found: y"},
+	}
+
+	for _, tt := range tests {
+		testObject(t, tt.input, testEval(t, tt.input), tt.expected)
+	}
+}
+
+func TestPartial(t *testing.T) {
+	tests := []struct {
+		input    string
+		expected interface{}
+	}{
+		{"partial((x, y) => x - y, 10)(3)", 7},
+		{"partial(pow, 2)(10)", 1024},
+		{"f = partial((x, y, z) => [x, y, z], 1); g = partial(f, 2); g(3)", []interface{}{1, 2, 3}},
+		{"f = partial((x, y, z) => [x, y, z], 1); g = partial(f, 2); f(4, 5)", []interface{}{1, 4, 5}},
+		{"partial((x, y) => x + y, 1, 2)()", 3},
+		{"partial((x, y) => x + y, 1, 2, 3)", "too many arguments, expect=(x, y), got=3"},
+		{"partial(1, 2)", "argument index 0 of function \"partial\" should be type FUNCTION, got NUMBER"},
+	}
+
+	for _, tt := range tests {
+		testObject(t, tt.input, testEval(t, tt.input), tt.expected)
+	}
+}
+
+func TestCurrying(t *testing.T) {
+	tests := []struct {
+		input    string
+		expected interface{}
+	}{
+		{"add = (x, y, z) => x + y + z; add(1)(2)(3)", 6},
+		{"add = (x, y, z) => x + y + z; add(1, 2)(3)", 6},
+		{"add = (x, y, z) => x + y + z; add(1)(2, 3)", 6},
+		{"add = (x, y, z) => { x + y + z }; inc = add(1, 0); [inc(1), inc(2)]", []interface{}{2, 3}},
+		{"f = (x, y, base = 10) => (x + y) * base; f(1)(2)", 30},
+		{"f = (x, y, base = 10) => (x + y) * base; f(1)(2, base = 2)", 6},
+		{"f = (x, y) => x - y; f(y = 1)(5)", 4},
+		{"f = (x, y) => x - y; f(y = 1)(x = 5)", 4},
+		{"f = (x, ...rest) => [x, rest]; f(1)", []interface{}{1, []interface{}{}}},
+		{"f = (x, y, ...rest) => [x, y, rest]; f(1)(2, 3, 4)", []interface{}{1, 2, []interface{}{3, 4}}},
+		{"f = memo((x, y) => x * y); g = f(3); [g(4), g(4), f(3, 4)]", []interface{}{12, 12, 12}},
+		{"f = (x, y) => x - y; f(y = 1)(y = 2)", "multiple values for argument y, expect=(x, y)"},
+		{"f = (x, y) => x - y; f(1)(2, 3)", "too many arguments, expect=(x, y), got=3"},
+		{"f = (x, y) => x - y; f()", "missing argument x, expect=(x, y)"},
+	}
+
+	for _, tt := range tests {
+		testObject(t, tt.input, testEval(t, tt.input), tt.expected)
+	}
+}
+
+func TestPartialInspect(t *testing.T) {
+	tests := []struct {
+		input    string
+		expected string
+	}{
+		{"((x, y, z) => x + y + z)(1)", "(y, z) => ((x, y, z) => ((x + y) + z))(1, ...)"},
+		{"((x, y, base = 10) => x)(y = 2)", "(x, base = 10) => ((x, y, base = 10) => x)(y = 2, ...)"},
+		{"partial(sqrt)", "(...) => (builtin function)(...)"},
+		{"(x => x) ∘ sqrt", "((x) => x) ∘ (builtin function)"},
+	}
+
+	for _, tt := range tests {
+		obj := testEval(t, tt.input)
+		if obj.Inspect() != tt.expected {
+			t.Fatalf("input %q: invalid Inspect(), expect=%q, got=%q", tt.input, tt.expected, obj.Inspect())
+		}
+	}
+}
+
+func TestConst(t *testing.T) {
+	tests := []struct {
+		input    string
+		expected interface{}
+	}{
+		{"const g = 9.81; g * 2", 19.62},
+		{"const g = 9.81; g = 10", "cannot assign value to the constant \"g\""},
+		{"const g = 9.81; const g = 10", "cannot assign value to the constant \"g\""},
+		{"x = 1; const x = 2; x = 3", "cannot assign value to the constant \"x\""},
+		{"const pi = 3", "cannot assign value to the builtin constant \"pi\""},
+		{"e = 222", "cannot assign value to the builtin constant \"e\""},
+		// a function scope cannot assign an outer constant, but it can shadow
+		// it by a parameter or a constant declaration
+		{"const g = 1; f = () => { g = 2 }; f()", "cannot assign value to the constant \"g\""},
+		{"const g = 1; f = g => g * 2; f(5)", 10},
+		{"const g = 1; for g in [1, 2] { g }", 2},
+		{"const g = 1; if true { let g = 2; g += 1; g }", 3},
+		{"const g = 1; f = () => { const g = 2; g }; [f(), g]", []interface{}{2, 1}},
+		{"const g = 1; f = g => { g = 3; g }; f(5)", 3},
+		{"f = () => { const k = 2; k = 3 }; f()", "cannot assign value to the constant \"k\""},
+		{"const k = 2; f = memo(x => x * k); f(3)", 6},
+		{"k = 2; f = memo(x => x * k)", "\"memo\": the function reads the outer variable k"},
+	}
+
+	for _, tt := range tests {
+		testObject(t, tt.input, testEval(t, tt.input), tt.expected)
+	}
+}
+
+func TestScoping(t *testing.T) {
+	tests := []struct {
+		input    string
+		expected interface{}
+	}{
+		{"count = 0; inc = () => { count += 1 }; inc(); inc(); count", 2},
+		{"x = 1; if true { x = 2 }; x", 2},
+		{"x = 1; y = if true { let x = 2; x += 1; x }; [x, y]", []interface{}{1, 3}},
+		{"if true { let y = 2 }; y", "identifier not found: y"},
+		{"if true { z = 5 } else { z = 6 }; z", 5},
+		{"f = () => { if true { w = 1 }; w }; f()", 1},
+		{"f = () => { w = 1 }; f(); w", "identifier not found: w"},
+		{"if false { z = 5 }; z", "identifier not found: z"},
+		{"if true { if true { z = 5 } }; z", 5},
+		{"f = () => { if true { w = 1 }; w }; f(); w", "identifier not found: w"},
+		{"if true { let z = 1; z = 2 }; z", "identifier not found: z"},
+		{"x = 1; if true { let x = 2; x = 3 }; x", 1},
+		{"let x = 1; let x = 2; x", 2},
+		{"f = 0; for i in [1, 2, 3] { if i == 2 { f = () => i } }; f()", 2},
+		{"i = 0; while i < 3 { let j = i; i += 1 }; j", "identifier not found: j"},
+		{"n = 100; make = () => { let n = 0; () => { n += 1; n } }; c = make(); [c(), c(), n]", []interface{}{1, 2, 100}},
+		{"n = 100; make = () => { n = 0; () => { n += 1; n } }; c = make(); [c(), c(), n]", []interface{}{1, 2, 2}},
+		{"x = 10; x -= 4; x *= 3; x /= 2; x", 9},
+		{"y += 1", "identifier not found: y"},
+		{"const c = 1; c += 1", "cannot assign value to the constant \"c\""},
+		{"const c = 1; f = () => { let c = 5; c += 1; c }; [f(), c]", []interface{}{6, 1}},
+		{"k = 1; f = memo(x => { k = x; x })", "\"memo\": the function assigns the outer variable k"},
+		{"f = memo(x => { let k = x; k += 1; k }); f(1)", 2},
+	}
+
+	for _, tt := range tests {
+		testObject(t, tt.input, testEval(t, tt.input), tt.expected)
+	}
+}
+
+func TestDomainErrors(t *testing.T) {
+	tests := []struct {
+		input    string
+		expected interface{}
+	}{
+		{"sqrt(-4)", `argument index 0 of function "sqrt" should be non-negative, got -4`},
+		{"ln(-1)", `argument index 0 of function "ln" should be positive, got -1`},
+		{"log(-8, 2)", `argument index 0 of function "log" should be positive, got -8`},
+		{"log(8, 1)", `argument index 1 of function "log" should be positive and not 1, got 1`},
+		{"log(8, 2)", 3},
+		{"1 / 0", "division by zero: 1 / 0"},
+		{"x = 0; -1 / x", "division by zero: -1 / 0"},
+		{"try { ln(0) } catch e { e.code == \"domain\" }", true},
+		{"try { 1 % 0 } catch e { e.code == \"domain\" }", true},
+		{"try { sqrt([1]) } catch e { e.code == \"runtime\" }", true},
+	}
+
+	for _, tt := range tests {
+		testObject(t, tt.input, testEval(t, tt.input), tt.expected)
+	}
+}
+
+func TestTryExpression(t *testing.T) {
+	tests := []struct {
+		input    string
+		expected interface{}
+	}{
+		{"try { 1 + 2 } catch { 0 }", 3},
+		{"try { 1 + true } catch { 0 }", 0},
+		{"try { 1 + true } catch e { e.message == \"type mismatch: NUMBER + BOOLEAN\" }", true},
+		{"safeln = x => try { assert(x > 0, \"negative\"); ln(x) } catch { 0 }; [safeln(1), safeln(-1)]", []interface{}{0, 0}},
+		{"try { raise(\"no data\") } catch e { [e.message == \"no data\", e.code == \"error\"] }", []interface{}{true, true}},
+		{"try { raise(\"no data\", \"input\") } catch e { e.code == \"input\" }", true},
+		{"try { assert(1 > 2) } catch e { [e.message == \"assertion failed\", e.code == \"assert\"] }", []interface{}{true, true}},
+		{"try { 1 / [1] } catch e { e.code == \"runtime\" }", true},
+		{"try {\n  x = 1\n  x + y\n} catch e { e.position }", []interface{}{3, 7}},
+		{"try { raise(\"inner\") } catch e { try { raise(e) } catch f { f.position } }", []interface{}{1, 7}},
+		{"f = () => try { return 1; 2 } catch { 3 }; f()", 1},
+		{"x = 0; for i in range(5) { x = i; try { if i == 2 { break } } catch { 0 } }; x", 2},
+		{"try { raise(\"a\") } catch e { raise(e) }", "a"},
+		{"try { raise(\"a\") } catch e { e.line }", "unknown member line of ERROR_VALUE, expect=message, code or position"},
+		{"try { raise(\"a\") } catch { err }", "identifier not found: err"},
+		{"assert(2 > 1)", true},
+		{"assert(1, \"x\")", "argument index 0 of function \"assert\" should be type BOOLEAN, got NUMBER"},
+		{"raise(1)", "argument index 0 of function \"raise\" should be type STRING, got NUMBER"},
+		{"f = memo(x => try { ln(x) } catch e { e.code }); f(1)", 0},
+		{"try { ln(-1) } catch e { 0 }", 0},
+		{"safeln = x => try { ln(x) } catch { 0 }; [safeln(e), safeln(-1)]", []interface{}{1, 0}},
+		{"try { 1 / 0 } catch e { [e.message == \"division by zero: 1 / 0\", e.code == \"domain\"] }", []interface{}{true, true}},
+		{"raise(\"a\", \"b\", \"c\")", `"raise": too many arguments, expect at most 2, got=3`},
+		{"assert(true, \"a\", \"b\")", `"assert": too many arguments, expect at most 2, got=3`},
+	}
+
+	for _, tt := range tests {
+		testObject(t, tt.input, testEval(t, tt.input), tt.expected)
+	}
+}
+
+func TestErrorPosition(t *testing.T) {
+	tests := []struct {
+		input  string
+		line   int
+		column int
+	}{
+		{"1 + true", 1, 3},
+		{"x = 1\ny = ln(x, 2)", 2, 5},
+		{"f = x => x + true\n\nf(1)", 1, 12},
+		{"xs = [1, 2]\n  xs[5]", 2, 5},
+		{"raise(\"a\")", 1, 1},
+	}
+
+	for _, tt := range tests {
+		err, ok := testEval(t, tt.input).(*object.Error)
+		if !ok {
+			t.Fatalf("input %q: expect an error", tt.input)
+		}
+		if err.Line != tt.line || err.Column != tt.column {
+			t.Fatalf("input %q: invalid position, expect=%d:%d, got=%d:%d", tt.input, tt.line, tt.column, err.Line, err.Column)
+		}
+	}
+}
+
+func TestImport(t *testing.T) {
+	dir := t.TempDir()
+	files := map[string]string{
+		"lib.qcal": `# helpers of the worksheets
+const square = x => x ^ 2 // a concise function
+/* the constants
+   and the units */
+const tau = 2 * pi
+import "sub/units.qcal"
+km = units.km
+`,
+		"sub/units.qcal": "km = 1000; double = x => 2 * x",
+		"a.qcal":         `import "b.qcal"`,
+		"b.qcal":         `import "sub/../c.qcal"`,
+		"c.qcal":         `import "a.qcal"`,
+		"self.qcal":      `import "self.qcal"`,
+		"bad.qcal":       "x = 1\ny = x + true",
+		"syntax.qcal":    "x = (",
+		"outer.qcal":     "z = y",
+		"state.qcal":     "k = 2; get = n => n * k; const cget = n => n * k; setk = v => { k = v }",
+	}
+	for name, content := range files {
+		path := filepath.Join(dir, name)
+		if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(path, []byte(content), 0o644); err != nil {
+			t.Fatal(err)
+		}
+	}
+	imp := func(name string) string {
+		return `import "` + filepath.Join(dir, name) + `"; `
+	}
+
+	tests := []struct {
+		input    string
+		expected interface{}
+	}{
+		{imp("lib.qcal") + "lib.square(3)", 9},
+		{imp("lib.qcal") + "[lib.km, lib.units.double(4)]", []interface{}{1000, 8}},
+		{imp("lib.qcal") + "lib.tau == 2 * pi", true},
+		{imp("lib.qcal") + "lib.missing", "identifier not found: lib.missing"},
+		{imp("lib.qcal") + "f = memo(x => lib.square(x) + 1); f(3)", 10},
+		{imp("lib.qcal") + "xs = [1, 2]; xs |> (lib.units.double . mean)", 3},
+		{imp("lib.qcal") + "(lib.square . lib.units.double)(3)", 36},
+		{imp("lib.qcal") + "3 |> lib.units.double . lib.square", 18},
+		{imp("state.qcal") + "m = memo(n => state.get(n))", `"memo": the function calls the outer function state.get, which is not a constant`},
+		{imp("state.qcal") + "m = memo(n => n * state.k)", `"memo": the function reads the outer variable state.k`},
+		{imp("state.qcal") + "m = memo(n => state.cget(n))", `"memo": the function calls state.cget, which reads the outer variable k`},
+		{imp("lib.qcal") + "m = memo(n => lib.units.double(n))", `"memo": the function calls the outer function lib.units.double, which is not a constant`},
+		{imp("lib.qcal") + "m = memo(n => lib.tau * n); m(1) == 2 * pi", true},
+		{imp("a.qcal"), "a.qcal:1:1: b.qcal:1:1: c.qcal:1:1: import cycle: a.qcal imports b.qcal imports c.qcal imports a.qcal"},
+		{imp("self.qcal"), "self.qcal:1:1: import cycle: self.qcal imports self.qcal"},
+		{imp("bad.qcal"), "bad.qcal:2:7: type mismatch: NUMBER + BOOLEAN"},
+		{imp("syntax.qcal"), `cannot import "` + filepath.Join(dir, "syntax.qcal") + `": no prefix parse function for EOF "" found, expect next token to be ), got EOF instead`},
+		{"y = 1; " + imp("outer.qcal"), "outer.qcal:1:5: identifier not found: y"},
+		{imp("missing.qcal"), `cannot import "` + filepath.Join(dir, "missing.qcal") +
+			`": open ` + filepath.Join(dir, "missing.qcal") + ": no such file or directory"},
+		{"const lib = 1; " + imp("lib.qcal"), `cannot assign value to the constant "lib"`},
+		{"try { " + imp("bad.qcal") + "} catch e { e.position }", []interface{}{1, 7}},
+	}
+
+	for _, tt := range tests {
+		testObject(t, tt.input, testEval(t, tt.input), tt.expected)
+	}
+}
+
+func TestPlotDomain(t *testing.T) {
+	// the values out of the domain are the gaps of the plot
+	tests := []struct {
+		input  string
+		domain func(x float64) bool
+	}{
+		{"plot(ln, -1, 4)", func(x float64) bool { return x > 0 }},
+		{"plot(sqrt, -1, 1)", func(x float64) bool { return x >= 0 }},
+		{"plot(x => 1/x, -1, 1)", func(x float64) bool { return x != 0 }},
+	}
+
+	for _, tt := range tests {
+		p, ok := testEval(t, tt.input).(*object.Plot)
+		if !ok {
+			t.Fatalf("input %q: invalid object type, expect=*object.Plot", tt.input)
+		}
+		series := p.Series[0]
+		for i, x := range series.Xs {
+			if tt.domain(x) == math.IsNaN(series.Ys[i]) {
+				t.Fatalf("input %q: invalid value at %v, got=%v", tt.input, x, series.Ys[i])
+			}
+		}
+	}
+}
+
+func TestPlotFile(t *testing.T) {
+	tests := []struct {
+		input    string
+		expected interface{}
+	}{
+		{`plotfile("/tmp/plot.svg", x => x, 0, 1)`, `"plotfile": path "/tmp/plot.svg" is outside of the working directory`},
+		{`plotfile("../plot.svg", x => x, 0, 1)`, `"plotfile": path "../plot.svg" is outside of the working directory`},
+		{`plotfile("sub/../../plot.svg", x => x, 0, 1)`, `"plotfile": path "sub/../../plot.svg" is outside of the working directory`},
+		{`plotfile("", x => x, 0, 1)`, `"plotfile": path "" is outside of the working directory`},
+		{`plotfile("plot.txt", x => x, 0, 1)`, `"plotfile": unsupported file extension ".txt", expect .svg or .png`},
+	}
+
+	for _, tt := range tests {
+		testObject(t, tt.input, testEval(t, tt.input), tt.expected)
+	}
+}
+
+func TestCalculus(t *testing.T) {
+	tests := []struct {
+		input    string
+		expected interface{}
+	}{
+		// integrate
+		{"integrate(x => x^2, 0, 3)", approx{9, 1e-12}},
+		{"integrate(sin, 0, pi)", approx{2, 1e-10}},
+		{"integrate(x => x, 1, 0)", approx{-0.5, 1e-12}},
+		{"integrate(x => 1/x^2, 1, e^1000)", approx{1, 1e-10}},
+		{"integrate(x => 1/(1 + x^2), -e^1000, e^1000)", approx{math.Pi, 1e-9}},
+		{"integrate(x => e^(-x^2), -e^1000, e^1000)", approx{math.Sqrt(math.Pi), 1e-9}},
+		{"integrate(x => x, -e^1000, e^1000)", `"integrate": the integral does not converge`},
+		{"integrate(x => x^3, -e^1000, e^1000)", `"integrate": the integral does not converge`},
+		{"integrate(sin, -e^1000, e^1000)", `"integrate": the integral does not converge`},
+		{"integrate(x => 1/sqrt(x), 0, 1)", approx{2, 1e-9}},
+		{"integrate(x => ln(x), 0, 1)", approx{-1, 1e-9}},
+		{"integrate(x => 1/x, 0, 1)", `"integrate": the integral does not converge`},
+		{"integrate(x => 1/x, 1, e^1000)", `"integrate": the integral does not converge`},
+		{"integrate(x => x > 1, 0, 1)", `"integrate": function should return NUMBER, got BOOLEAN`},
+		// deriv
+		{"deriv(x => x^3, 2)", approx{12, 1e-9}},
+		{"deriv(sin, 0)", approx{1, 1e-12}},
+		{"deriv(x => x^0.5, 0)", `"deriv": the function is not differentiable at 0`},
+		{"deriv(sqrt, 0)", `"deriv": the function is not differentiable at 0`},
+		{"deriv(ln, 0)", `"deriv": the function is not differentiable at 0`},
+		{"deriv(x => x, e^1000)", `"deriv": x should be finite, got +Inf`},
+		// limit
+		{"limit(x => sin(x) / x, 0)", approx{1, 1e-12}},
+		{"limit(x => (1 + 1/x)^x, e^1000)", approx{math.E, 1e-9}},
+		{"limit(x => 1/x, e^1000)", approx{0, 1e-12}},
+		{"limit(x => 1/x^2, 0)", approx{math.Inf(1), 0}},
+		{"limit(x => ln(x), 0)", approx{math.Inf(-1), 0}},
+		{"limit(x => -ln(abs(x)), 0)", approx{math.Inf(1), 0}},
+		{"limit(x => 1/x, 0)", `"limit": the limit does not exist, the left limit is -Inf and the right limit is +Inf`},
+		{"limit(x => abs(x) / x, 0)", `"limit": the limit does not exist, the left limit is -1 and the right limit is 1`},
+		// series
+		{"series(n => n, 1, 100)", approx{5050, 1e-9}},
+		{"series(n => 1/n^2, 1, e^1000)", approx{math.Pi * math.Pi / 6, 1e-9}},
+		{"series(n => (-1)^(n + 1) / n, 1, e^1000)", approx{math.Ln2, 1e-9}},
+		{"series(n => (-1)^n / (2*n + 1), 0, e^1000)", approx{math.Pi / 4, 1e-9}},
+		{"series(n => 1/n, 1, e^1000)", `"series": the series does not converge`},
+		{"series(n => (-1)^n, 0, e^1000)", `"series": the series does not converge`},
+		{"series(n => sin(n), 0, e^1000)", `"series": the series does not converge`},
+		{"series(n => 2^n, 0, e^1000)", `"series": the series does not converge`},
+		{"series(n => 1/n, 0, 5)", `"series": the series does not converge`},
+		{"series(n => n, 1.5, 3)", `"series": from should be an integer, got 1.5`},
+	}
+
+	for _, tt := range tests {
+		testObject(t, tt.input, testEval(t, tt.input), tt.expected)
+	}
+}
+
+func TestSolve(t *testing.T) {
+	tests := []struct {
+		input    string
+		expected interface{}
+	}{
+		// equation
+		{"solve(x^2 := 4)", -2},
+		{"solve(x^2 := 4, 1)", 2},
+		{"solve(x^2 := 4, -3, 0)", -2},
+		{"y = 3; solve(x * y := 6)", 2},
+		{"solve(x^2 == 4, 1)", 2},
+		{"solve(x^2 == 4, -3, 0)", -2},
+		{"y = 3; solve(x * y == 6)", 2},
+		{"x = 1; solve(x == 1)", `argument index 0 of function "solve" should be type EQUATION or FUNCTION, got BOOLEAN`},
+		{"solve(x := x)", `"solve": every number is a solution`},
+		{"solve(x + 1 := x)", `"solve": the equation has no solution`},
+		{"solve(x + y := 1)", `"solve": the equation has more than one unknown: x, y`},
+		{"solve(1 := 1)", `"solve": the equation has no unknown`},
+		// concise function
+		{"solve(x => x^2 == 9, 2)", 3},
+		{"solve(x => cos(x) - x)", approx{0.7390851332151607, 1e-12}},
+		{"solve(x => x^5 - x - 1, 0, 2)", approx{1.1673039782614187, 1e-12}},
+		{"solve(x => x^2 + 1)", `"solve": the equation has no real solution`},
+		{"solve((x, y) => x)", `"solve": function should have 1 parameter, got 2`},
+		{"solve(x => x^6 + 1, 0, 1)", `"solve": f(a) and f(b) should have different signs, got f(0) = 1 and f(1) = 2`},
+		// polynomial
+		{"solve(poly([-6, 1]))", approx{1.0 / 6, 1e-15}},
+		{"solve(poly([1, 0, -4]), 1)", 2},
+		// roots
+		{"roots(x^2 := 4, -5, 5)", []interface{}{-2, 2}},
+		{"roots(x => sin(x), -4, 4)", []interface{}{approx{-math.Pi, 1e-12}, 0, approx{math.Pi, 1e-12}}},
+		{"roots(x => x^2 + 1, -1, 1)", []interface{}{}},
+		{"roots(poly([1, -3, 2]))", []interface{}{1, 2}},
+		{"roots(poly([2, -3, 1]))", []interface{}{0.5, 1}},
+		{"roots(x^2 == 4, -5, 5)", []interface{}{-2, 2}},
+		{"roots(x => x)", `"roots": not enough arguments, expect=3, got=1`},
+	}
+
+	for _, tt := range tests {
+		testObject(t, tt.input, testEval(t, tt.input), tt.expected)
+	}
+}
+
+func TestSolveNegativeZero(t *testing.T) {
+	inputs := []string{
+		"solve(2 * x := 0)",
+		"solve(x => -3 * x)",
+		"solve(poly([2, 0]))",
+		"roots(x => 3 * x, -1, 1)[0]",
+	}
+
+	for _, input := range inputs {
+		number, ok := testEval(t, input).(*object.Number)
+		if !ok || number.Value != 0 || math.Signbit(number.Value) {
+			t.Errorf("input %q: invalid root, expect=0, got=%v", input, number)
+		}
+	}
+}
+
+func TestStatistics(t *testing.T) {
+	data := "2, 4, 4, 4, 5, 5, 7, 9"
+
+	tests := []struct {
+		input    string
+		expected interface{}
+	}{
+		{"mean(1, 2, 3, 4)", 2.5},
+		{"mean([2])", 2},
+		{"mean([])", `"mean": list should not be empty`},
+		{"mean()", `"mean": not enough arguments, expect at least 1, got=0`},
+		{"mean(1, true)", `argument index 1 of function "mean" should be type NUMBER, got BOOLEAN`},
+		{"median(3, 1, 2)", 2},
+		{"median([4, 1, 3, 2])", 2.5},
+		{"median([5])", 5},
+		{"median([])", `"median": list should not be empty`},
+		{"mode(1, 2, 2, 3, 3)", 2},
+		{"mode([7])", 7},
+		{"mode([])", `"mode": list should not be empty`},
+		{"variance(" + data + ")", approx{32.0 / 7, 1e-12}},
+		{"variance([1])", `"variance": expect at least 2 values, got=1`},
+		{"variance([])", `"variance": list should not be empty`},
+		{"pvariance([" + data + "])", 4},
+		{"pvariance([3])", 0},
+		{"pvariance([])", `"pvariance": list should not be empty`},
+		{"stdev(" + data + ")", approx{math.Sqrt(32.0 / 7), 1e-12}},
+		{"stdev([1])", `"stdev": expect at least 2 values, got=1`},
+		{"stdev([])", `"stdev": list should not be empty`},
+		{"pstdev(" + data + ")", 2},
+		{"pstdev([3])", 0},
+		{"pstdev([])", `"pstdev": list should not be empty`},
+		{"quantile([1, 2, 3, 4], 0.25)", 1.75},
+		{"quantile([5], 0.3)", 5},
+		{"quantile([], 0.5)", `"quantile": list should not be empty`},
+		{"quantile([1], 1.5)", `"quantile": q should be from 0 to 1, got 1.5`},
+		{"percentile([1, 2, 3, 4, 5], 90)", approx{4.6, 1e-12}},
+		{"percentile([5], 50)", 5},
+		{"percentile([], 50)", `"percentile": list should not be empty`},
+		{"percentile([1, 2], -1)", `"percentile": p should be from 0 to 100, got -1`},
+		{"covariance([1, 2, 3], [2, 4, 6])", 2},
+		{"covariance([1], [2])", `"covariance": expect at least 2 values, got=1`},
+		{"covariance([], [])", `"covariance": expect at least 2 values, got=0`},
+		{"covariance([1, 2], [1])", `"covariance": dimension mismatch: 2 and 1 elements`},
+		{"correlation([1, 2, 3], [2, 4, 6])", approx{1, 1e-12}},
+		{"correlation([1, 2, 3], [3, 2, 1])", approx{-1, 1e-12}},
+		{"correlation([1], [1])", `"correlation": expect at least 2 values, got=1`},
+		{"correlation([], [])", `"correlation": expect at least 2 values, got=0`},
+		{"correlation([1, 1], [1, 2])", `"correlation": the values of a list should not all be equal`},
+		{"zscore(9, " + data + ")", approx{4 / math.Sqrt(32.0/7), 1e-12}},
+		{"zscore(1, [1])", `"zscore": expect at least 2 values, got=1`},
+		{"zscore(1, [])", `"zscore": list should not be empty`},
+		{"zscore(1, [1, 1])", `"zscore": the values should not all be equal`},
+		{"linreg([1, 2, 3], [3, 5, 7])", []interface{}{approx{2, 1e-12}, approx{1, 1e-12}}},
+		{"linreg([1], [1])", `"linreg": expect at least 2 values, got=1`},
+		{"linreg([], [])", `"linreg": expect at least 2 values, got=0`},
+		{"linreg([1, 1], [1, 2])", `"linreg": the values of xs should not all be equal`},
+	}
+
+	for _, tt := range tests {
+		testObject(t, tt.input, testEval(t, tt.input), tt.expected)
+	}
+}
+
+func TestRandom(t *testing.T) {
+	tests := []struct {
+		input    string
+		expected interface{}
+	}{
+		{"seed(42); a = rand(); seed(42); a == rand()", true},
+		{"seed(42); a = rand(); a == rand()", false},
+		{"seed(-3); a = rand(); if a >= 0 { a < 1 } else { false }", true},
+		{"seed(7); a = randint(1, 6); seed(7); a == randint(1, 6)", true},
+		{"seed(7); a = randint(1, 6); if a >= 1 { if a <= 6 { a % 1 == 0 } else { false } } else { false }", true},
+		{"randint(3, 3)", 3},
+		{"seed(1)", nil},
+		{"rand(1)", `"rand": too many arguments, expect=0, got=1`},
+		{"randint(2, 1)", `"randint": a should not be greater than b, got 2 and 1`},
+		{"randint(1.5, 2)", `"randint": bounds should be integers, got 1.5 and 2`},
+		{"seed(0.5)", `"seed": seed should be an integer, got 0.5`},
+	}
+
+	for _, tt := range tests {
+		testObject(t, tt.input, testEval(t, tt.input), tt.expected)
+	}
+}
+
+func TestProbability(t *testing.T) {
+	tests := []struct {
+		input    string
+		expected interface{}
+	}{
+		{"ncr(5, 2)", 10},
+		{"ncr(5, 0)", 1},
+		{"ncr(50, 25)", 126410606437752},
+		{"ncr(2, 5)", `"ncr": r should not be greater than n, got 5 and 2`},
+		{"ncr(-1, 2)", `"ncr": n and r should be non-negative integers, got -1 and 2`},
+		{"ncr(4.5, 2)", `"ncr": n and r should be non-negative integers, got 4.5 and 2`},
+		{"npr(5, 2)", 20},
+		{"npr(20, 20)", 2432902008176640000},
+		{"npr(5, 6)", `"npr": r should not be greater than n, got 6 and 5`},
+		{"erf(0)", 0},
+		{"erf(1)", approx{0.8427007929497149, 1e-15}},
+		{"erf(-1)", approx{-0.8427007929497149, 1e-15}},
+		{"erf(e^1000)", 1},
+		{"binominv(0.5, 10, 0.5)", 5},
+		{"binominv(2, 10, 0.5)", `"binominv": q should be from 0 to 1, got 2`},
+		{"norminv(0.975, 0, 1)", approx{1.959963984540054, 1e-9}},
+	}
+
+	for _, tt := range tests {
+		testObject(t, tt.input, testEval(t, tt.input), tt.expected)
+	}
+}
+
+func TestExactInteger(t *testing.T) {
+	tests := []struct {
+		input    string
+		expected string
+	}{
+		{"0!", "1"},
+		{"20!", "2432902008176640000"},
+		{"25!", "15511210043330985984000000"},
+		{"25! / 24!", "25"},
+		{"30! % 31", "30"},
+		{"0.5!", "0.8862269254527579"},
+		{"(-1)!", "ERROR: factorial of a negative integer: -1!"},
+		{"1000000!", "ERROR: factorial is too large: 1000000!"},
+		{"(2^80)!", "ERROR: factorial is too large: 1208925819614629174706176!"},
+		{"200.5!", "ERROR: factorial is too large: 200.5!"},
+		{"2^53 + 1", "9007199254740993"},
+		{"2^64 + 1", "18446744073709551617"},
+		{"(2^64 + 1) * (2^64 - 1)", "340282366920938463463374607431768211455"},
+		{"(2^64 + 1) - 2^64", "1"},
+		{"-(2^64)", "-18446744073709551616"},
+		{"2^100 % 7", "2"},
+		{"2^100 / 2^98", "4"},
+		{"2^64 == 2^64 + 1", "false"},
+		{"10^21", "1000000000000000000000"},
+		{"fib(100)", "354224848179261915075"},
+		{"factor(2^64 + 1)", "[274177, 67280421310721]"},
+		{"[2^70, 1500000, 0.5]", "[1180591620717411303424, 1500000, 0.5]"},
+	}
+
+	for _, tt := range tests {
+		if got := testEval(t, tt.input).Inspect(); got != tt.expected {
+			t.Errorf("input %q: invalid result, expect=%s, got=%s", tt.input, tt.expected, got)
+		}
+	}
+}
+
+func TestBuiltinSignature(t *testing.T) {
+	tests := []struct {
+		name     string
+		expected string
+	}{
+		{"min", "min(x: NUMBER, y: NUMBER)"},
+		{"rand", "rand()"},
+		{"range", "range(a: NUMBER, b?: NUMBER, step?: NUMBER)"},
+		{"mean", "mean(xs...: NUMBER)"},
+		{"zscore", "zscore(x: NUMBER, xs...: NUMBER)"},
+		{"assert", "assert(cond: BOOLEAN, message?: STRING)"},
+		{"partial", "partial(f: FUNCTION, args...?)"},
+	}
+
+	for _, tt := range tests {
+		got, ok := BuiltinSignature(tt.name)
+		if !ok || got != tt.expected {
+			t.Errorf("invalid signature of %q, expect=%q, got=%q", tt.name, tt.expected, got)
+		}
+	}
+}
+
+// ------------------------------------------------------------------ //
+
+func testEval(t *testing.T, input string) object.Object {
+	t.Helper()
+
+	program, errors := parser.New(lexer.New(input)).ParseProgram()
+	if len(errors) > 0 {
+		t.Fatalf("input %q: parseProgram failed: %v", input, errors)
+	}
+	return Eval(program, object.NewEnvironment())
+}
+
+// approx is a number or an estimate expected to be within tolerance of value.
+type approx struct {
+	value     float64
+	tolerance float64
+}
+
+// testObject checks the result, the expected value is an int or float64 for a
+// number, an approx for a number or an estimate, a bool for a boolean, a string
+// for an error message, a slice for a list and nil for null.
+func testObject(t *testing.T, input string, obj object.Object, expected interface{}) {
+	t.Helper()
+
+	if letValue, ok := obj.(*object.LetValue); ok {
+		obj = letValue.Value
+	}
+
+	switch expected := expected.(type) {
+	case int:
+		testNumber(t, input, obj, float64(expected))
+	case float64:
+		testNumber(t, input, obj, expected)
+	case approx:
+		testApprox(t, input, obj, expected)
+	case bool:
+		if obj != booleanObject(expected) {
+			t.Fatalf("input %q: invalid object, expect=%t, got=%T (%+v)", input, expected, obj, obj)
+		}
+	case string:
+		err, ok := obj.(*object.Error)
+		if !ok {
+			t.Fatalf("input %q: invalid object type, expect=*object.Error, got=%T (%+v)", input, obj, obj)
+		}
+		if err.Message != expected {
+			t.Fatalf("input %q: invalid error message, expect=%q, got=%q", input, expected, err.Message)
+		}
+	case []interface{}:
+		list, ok := obj.(*object.List)
+		if !ok {
+			t.Fatalf("input %q: invalid object type, expect=*object.List, got=%T (%+v)", input, obj, obj)
+		}
+		if len(list.Elements) != len(expected) {
+			t.Fatalf("input %q: invalid list length, expect=%d, got=%d", input, len(expected), len(list.Elements))
+		}
+		for i, element := range list.Elements {
+			testObject(t, input, element, expected[i])
+		}
+	case nil:
+		if obj != NULL {
+			t.Fatalf("input %q: invalid object, expect=NULL, got=%T (%+v)", input, obj, obj)
+		}
+	default:
+		t.Fatalf("type of expected is not handled, expected=%T", expected)
+	}
+}
+
+func testNumber(t *testing.T, input string, obj object.Object, expected float64) {
+	t.Helper()
+
+	number, ok := obj.(*object.Number)
+	if !ok {
+		t.Fatalf("input %q: invalid object type, expect=*object.Number, got=%T (%+v)", input, obj, obj)
+	}
+	if number.Value != expected {
+		t.Fatalf("input %q: invalid number value, expect=%v, got=%v", input, expected, number.Value)
+	}
+}
+
+func testApprox(t *testing.T, input string, obj object.Object, expected approx) {
+	t.Helper()
+
+	var value float64
+	switch obj := obj.(type) {
+	case *object.Number:
+		value = obj.Value
+	case *object.Estimate:
+		value = obj.Value
+	default:
+		t.Fatalf("input %q: invalid object type, expect=*object.Number or *object.Estimate, got=%T (%+v)", input, obj, obj)
+	}
+	if value != expected.value && !(math.Abs(value-expected.value) <= expected.tolerance) {
+		t.Fatalf("input %q: invalid number value, expect=%v ± %v, got=%v", input, expected.value, expected.tolerance, value)
+	}
+}
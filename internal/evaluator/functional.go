@@ -0,0 +1,120 @@
+package evaluator
+
+import (
+	"github.com/DeepAung/qcal/internal/ast"
+	"github.com/DeepAung/qcal/internal/object"
+)
+
+func composeFunction(args ...object.Object) object.Object {
+	info := infos["compose"]
+	if err := checkArgsLength(info, args); err != nil {
+		return err
+	}
+	for i, arg := range args {
+		if !isFunction(arg) {
+			return newError(
+				"argument index %d of function %q should be type %s, got %s",
+				i, info.name, object.FUNCTION_OBJ, arg.Type(),
+			)
+		}
+	}
+	return &object.ComposedFunction{Outer: args[0], Inner: args[1]}
+}
+
+// evalComposeInfixExpression evaluates `f ∘ g`, or `f . g`, as compose(f, g).
+func evalComposeInfixExpression(operator string, left, right object.Object) object.Object {
+	if !isFunction(left) || !isFunction(right) {
+		return newError("unknown operator: %s %s %s", left.Type(), operator, right.Type())
+	}
+	return &object.ComposedFunction{Outer: left, Inner: right}
+}
+
+// applyComposedFunction calls the inner function with the arguments, then the
+// outer function with its result as a tail call.
+func applyComposedFunction(fn *object.ComposedFunction, args []object.Object) object.Object {
+	inner := applyFunction(fn.Inner, args)
+	if IsError(inner) {
+		return inner
+	}
+	return &object.TailCall{Function: fn.Outer, Arguments: []object.Object{inner}}
+}
+
+func partialFunction(args ...object.Object) object.Object {
+	info := infos["partial"]
+	if err := checkArgsLength(info, args); err != nil {
+		return err
+	}
+	if !isFunction(args[0]) {
+		return newError(
+			"argument index 0 of function %q should be type %s, got %s",
+			info.name, object.FUNCTION_OBJ, args[0].Type(),
+		)
+	}
+
+	fn, given := args[0], args[1:]
+	if partial, ok := fn.(*object.PartialFunction); ok {
+		fn, given = partial.Function, mergeArguments(partial.Arguments, given)
+	}
+
+	var open []*ast.Parameter
+	if params, ok := userParameters(fn); ok {
+		values, err := bindArguments(params, given)
+		if err != nil {
+			return err
+		}
+		open = unboundParameters(params, values)
+	}
+	return &object.PartialFunction{Function: fn, Arguments: given, Open: open}
+}
+
+// applyPartialFunction calls the function with the given arguments before
+// the new ones.
+func applyPartialFunction(fn *object.PartialFunction, args []object.Object) object.Object {
+	return callFunction(fn.Function, mergeArguments(fn.Arguments, args))
+}
+
+// mergeArguments returns the positional arguments of both calls, then their
+// named arguments.
+func mergeArguments(given, args []object.Object) []object.Object {
+	merged := make([]object.Object, 0, len(given)+len(args))
+	for _, named := range []bool{false, true} {
+		for _, arg := range given {
+			if _, ok := arg.(*object.NamedArgument); ok == named {
+				merged = append(merged, arg)
+			}
+		}
+		for _, arg := range args {
+			if _, ok := arg.(*object.NamedArgument); ok == named {
+				merged = append(merged, arg)
+			}
+		}
+	}
+	return merged
+}
+
+// userParameters returns the parameters of a user function, it reports false
+// for the builtins, whose parameters are not known.
+func userParameters(fn object.Object) ([]*ast.Parameter, bool) {
+	switch fn := fn.(type) {
+	case *object.NormalFunction:
+		return fn.Parameters, true
+	case *object.ConciseFunction:
+		return fn.Parameters, true
+	case *object.MemoFunction:
+		return userParameters(fn.Function)
+	default:
+		return nil, false
+	}
+}
+
+// unboundParameters returns the parameters without a value, which are still
+// open.
+func unboundParameters(params []*ast.Parameter, values []object.Object) []*ast.Parameter {
+	open := []*ast.Parameter{}
+	for i, param := range params {
+		if values[i] == nil {
+			open = append(open, param)
+		}
+	}
+	return open
+}
@@ -0,0 +1,219 @@
+package evaluator
+
+import (
+	"math"
+	"math/big"
+
+	"github.com/DeepAung/qcal/internal/ast"
+	"github.com/DeepAung/qcal/internal/object"
+)
+
+const (
+	// maxSafeInteger is the largest integer of a float64 whose neighbours are
+	// exact too.
+	maxSafeInteger = 1 << 53
+	// maxExactBits limits the size of the exact integers, the larger results
+	// are float64 numbers.
+	maxExactBits = 1 << 16
+)
+
+// exactInteger returns the exact value of an integer number, the float64
+// integers beyond maxSafeInteger are not exact.
+func exactInteger(n *object.Number) (*big.Int, bool) {
+	if n.Exact != nil {
+		return n.Exact, true
+	}
+	if !isSafeInteger(n.Value) {
+		return nil, false
+	}
+	return big.NewInt(int64(n.Value)), true
+}
+
+// isSafeInteger reports whether x is an integer that float64 holds exactly.
+func isSafeInteger(x float64) bool {
+	return x == math.Trunc(x) && math.Abs(x) <= maxSafeInteger
+}
+
+// newInteger returns the number of the integer, which keeps its exact value
+// if the float64 value is rounded.
+func newInteger(n *big.Int) *object.Number {
+	value, _ := new(big.Float).SetInt(n).Float64()
+	if n.BitLen() <= 53 || n.BitLen() > maxExactBits {
+		return newNumber(value)
+	}
+	return &object.Number{Value: value, Exact: n}
+}
+
+// evalNumberLiteral keeps the exact value of the integer literals too large
+// for a float64.
+func evalNumberLiteral(node *ast.NumberLiteral) *object.Number {
+	if node.Value > maxSafeInteger {
+		if n, ok := new(big.Int).SetString(node.Token.Literal, 10); ok {
+			return newInteger(n)
+		}
+	}
+	return &object.Number{Value: node.Value}
+}
+
+// evalExactInfixExpression evaluates the operators of two integers exactly if
+// one of them is an exact integer or the float64 result would be rounded. It
+// reports false for the float64 operations.
+func evalExactInfixExpression(operator string, left, right *object.Number) (object.Object, bool) {
+	if left.Exact == nil && right.Exact == nil {
+		if !isSafeInteger(left.Value) || !isSafeInteger(right.Value) {
+			return nil, false
+		}
+
+		var result float64
+		switch operator {
+		case "+":
+			result = left.Value + right.Value
+		case "-":
+			result = left.Value - right.Value
+		case "*":
+			result = left.Value * right.Value
+		case "^":
+			result = math.Pow(left.Value, right.Value)
+		case "%":
+			return evalIntegerModulo(big.NewInt(int64(left.Value)), big.NewInt(int64(right.Value))), true
+		default:
+			return nil, false
+		}
+		if math.Abs(result) < maxSafeInteger {
+			return nil, false
+		}
+	}
+
+	a, ok := exactInteger(left)
+	if !ok {
+		return nil, false
+	}
+	b, ok := exactInteger(right)
+	if !ok {
+		return nil, false
+	}
+
+	switch operator {
+	case "+":
+		return newInteger(new(big.Int).Add(a, b)), true
+	case "-":
+		return newInteger(new(big.Int).Sub(a, b)), true
+	case "*":
+		return newInteger(new(big.Int).Mul(a, b)), true
+	case "/":
+		if b.Sign() == 0 {
+			return nil, false
+		}
+		quotient, remainder := new(big.Int).QuoRem(a, b, new(big.Int))
+		if remainder.Sign() != 0 {
+			return nil, false
+		}
+		return newInteger(quotient), true
+	case "%":
+		return evalIntegerModulo(a, b), true
+	case "^":
+		if b.Sign() < 0 || !b.IsInt64() || float64(a.BitLen()-1)*float64(b.Int64()) > maxExactBits {
+			return nil, false
+		}
+		return newInteger(new(big.Int).Exp(a, b, nil)), true
+	case "<":
+		return booleanObject(a.Cmp(b) < 0), true
+	case ">":
+		return booleanObject(a.Cmp(b) > 0), true
+	case "<=":
+		return booleanObject(a.Cmp(b) <= 0), true
+	case ">=":
+		return booleanObject(a.Cmp(b) >= 0), true
+	case "==":
+		return booleanObject(a.Cmp(b) == 0), true
+	case "!=":
+		return booleanObject(a.Cmp(b) != 0), true
+	default:
+		return nil, false
+	}
+}
+
+// evalIntegerModulo returns the remainder of the truncated division, with the
+// sign of a.
+func evalIntegerModulo(a, b *big.Int) object.Object {
+	if b.Sign() == 0 {
+		return newDomainError("division by zero: %s %% 0", a)
+	}
+	return newInteger(new(big.Int).Rem(a, b))
+}
+
+// factorial returns n! exactly for the integers and gamma(n + 1) for the
+// other numbers. It errors if the result is too large for either.
+func factorial(n *object.Number) object.Object {
+	if n.Value != math.Trunc(n.Value) || math.IsNaN(n.Value) {
+		result := math.Gamma(n.Value + 1)
+		if math.IsInf(result, 0) {
+			return newError("factorial is too large: %s!", n.Inspect())
+		}
+		return newNumber(result)
+	}
+	if n.Value < 0 {
+		return newError("factorial of a negative integer: %s!", n.Inspect())
+	}
+
+	k, ok := exactInteger(n)
+	if !ok {
+		return newError("factorial is too large: %s!", n.Inspect())
+	}
+	if lgamma, _ := math.Lgamma(n.Value + 1); lgamma/math.Ln2 > maxExactBits {
+		return newError("factorial is too large: %s!", n.Inspect())
+	}
+	return newInteger(new(big.Int).MulRange(1, k.Int64()))
+}
+
+// integerArg returns the exact value of the argument at index i, which should
+// be an integer number.
+func integerArg(info builtinFuncInfo, args []object.Object, i int) (*big.Int, *object.Error) {
+	if n, ok := args[i].(*object.Number); ok {
+		if k, ok := exactInteger(n); ok {
+			return k, nil
+		}
+	}
+	return nil, newError(
+		"argument index %d of function %q should be an integer, got %s",
+		i, info.name, args[i].Inspect(),
+	)
+}
+
+// integerArgs checks the arguments of the builtins of integers and returns
+// their exact values.
+func integerArgs(info builtinFuncInfo, args []object.Object) ([]*big.Int, *object.Error) {
+	if err := checkArgsLength(info, args); err != nil {
+		return nil, err
+	}
+	values := make([]*big.Int, len(args))
+	for i := range args {
+		k, err := integerArg(info, args, i)
+		if err != nil {
+			return nil, err
+		}
+		values[i] = k
+	}
+	return values, nil
+}
+
+// positiveIntegerArg checks the single argument of the builtins of a positive
+// integer and returns its exact value.
+func positiveIntegerArg(info builtinFuncInfo, args []object.Object) (*big.Int, *object.Error) {
+	values, err := integerArgs(info, args)
+	if err != nil {
+		return nil, err
+	}
+	if values[0].Sign() <= 0 {
+		return nil, newError("%q: argument should be a positive integer, got %s", info.name, values[0])
+	}
+	return values[0], nil
+}
+
+func newIntegerList(values []*big.Int) *object.List {
+	list := &object.List{Elements: make([]object.Object, len(values))}
+	for i, v := range values {
+		list.Elements[i] = newInteger(v)
+	}
+	return list
+}
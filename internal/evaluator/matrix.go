@@ -0,0 +1,313 @@
+package evaluator
+
+import (
+	"math"
+
+	"github.com/DeepAung/qcal/internal/matrix"
+	"github.com/DeepAung/qcal/internal/object"
+)
+
+// newMatrixLiteral returns the matrix of the elements if they are lists of
+// numbers of the same non-zero length, e.g. [[1, 2], [3, 4]].
+func newMatrixLiteral(elements []object.Object) (*object.Matrix, bool) {
+	if len(elements) == 0 {
+		return nil, false
+	}
+
+	rows := make([][]float64, len(elements))
+	for i, element := range elements {
+		list, ok := element.(*object.List)
+		if !ok {
+			return nil, false
+		}
+		row, ok := vectorValues(list)
+		if !ok || len(row) == 0 || i > 0 && len(row) != len(rows[0]) {
+			return nil, false
+		}
+		rows[i] = row
+	}
+	return &object.Matrix{Rows: rows}, true
+}
+
+// vectorValues returns the values of the list if all of its elements are
+// numbers.
+func vectorValues(list *object.List) ([]float64, bool) {
+	values := make([]float64, len(list.Elements))
+	for i, element := range list.Elements {
+		number, ok := element.(*object.Number)
+		if !ok {
+			return nil, false
+		}
+		values[i] = number.Value
+	}
+	return values, true
+}
+
+func newVector(values []float64) *object.List {
+	list := &object.List{Elements: make([]object.Object, len(values))}
+	for i, v := range values {
+		list.Elements[i] = newNumber(v)
+	}
+	return list
+}
+
+// column returns the vector as a matrix of one column.
+func column(values []float64) [][]float64 {
+	m := matrix.New(len(values), 1)
+	for i, v := range values {
+		m[i][0] = v
+	}
+	return m
+}
+
+// evalMatrixInfixExpression evaluates the operators of a matrix and a matrix,
+// a number or a vector, which is a column on the right and a row on the left.
+func evalMatrixInfixExpression(operator string, left, right object.Object) object.Object {
+	switch left := left.(type) {
+	case *object.Matrix:
+		switch right := right.(type) {
+		case *object.Matrix:
+			return evalMatrixMatrixInfixExpression(operator, left.Rows, right.Rows)
+		case *object.Number:
+			switch operator {
+			case "*":
+				return &object.Matrix{Rows: matrix.Scale(left.Rows, right.Value)}
+			case "/":
+				return &object.Matrix{Rows: matrix.Scale(left.Rows, 1/right.Value)}
+			case "^":
+				n := right.Value
+				if n != math.Trunc(n) {
+					return newError("the power of a matrix should be an integer, got %s", right.Inspect())
+				}
+				return newMatrixResult(matrix.Pow(left.Rows, int(n)))
+			}
+		case *object.List:
+			if v, ok := vectorValues(right); ok && operator == "*" {
+				product, err := matrix.Mul(left.Rows, column(v))
+				if err != nil {
+					return newError("%s", err)
+				}
+				return newVector(matrix.Transpose(product)[0])
+			}
+		}
+
+	case *object.Number:
+		if m, ok := right.(*object.Matrix); ok && operator == "*" {
+			return &object.Matrix{Rows: matrix.Scale(m.Rows, left.Value)}
+		}
+
+	case *object.List:
+		v, ok := vectorValues(left)
+		if m, isMatrix := right.(*object.Matrix); ok && isMatrix && operator == "*" {
+			product, err := matrix.Mul([][]float64{v}, m.Rows)
+			if err != nil {
+				return newError("%s", err)
+			}
+			return newVector(product[0])
+		}
+	}
+
+	return newError("unknown operator: %s %s %s", left.Type(), operator, right.Type())
+}
+
+func evalMatrixMatrixInfixExpression(operator string, a, b [][]float64) object.Object {
+	switch operator {
+	case "+":
+		return newMatrixResult(matrix.Add(a, b))
+	case "-":
+		return newMatrixResult(matrix.Sub(a, b))
+	case "*":
+		return newMatrixResult(matrix.Mul(a, b))
+	case "==":
+		return booleanObject(matrixEqual(a, b))
+	case "!=":
+		return booleanObject(!matrixEqual(a, b))
+	default:
+		return newError("unknown operator: %s %s %s", object.MATRIX_OBJ, operator, object.MATRIX_OBJ)
+	}
+}
+
+func newMatrixResult(m [][]float64, err error) object.Object {
+	if err != nil {
+		return newError("%s", err)
+	}
+	return &object.Matrix{Rows: m}
+}
+
+func matrixEqual(a, b [][]float64) bool {
+	if matrix.SizeString(a) != matrix.SizeString(b) {
+		return false
+	}
+	for i := range a {
+		for j := range a[i] {
+			if a[i][j] != b[i][j] {
+				return false
+			}
+		}
+	}
+	return true
+}
+
+// evalVectorInfixExpression evaluates the operators of the lists of numbers,
+// the sum and the difference are elementwise and a number scales the vector.
+func evalVectorInfixExpression(operator string, left, right object.Object) object.Object {
+	unknown := newError("unknown operator: %s %s %s", left.Type(), operator, right.Type())
+
+	switch left := left.(type) {
+	case *object.List:
+		u, ok := vectorValues(left)
+		if !ok {
+			return unknown
+		}
+
+		switch right := right.(type) {
+		case *object.List:
+			v, ok := vectorValues(right)
+			if !ok {
+				return unknown
+			}
+			return evalVectorVectorInfixExpression(operator, u, v)
+		case *object.Number:
+			switch operator {
+			case "*":
+				return newVector(scaleVector(u, right.Value))
+			case "/":
+				return newVector(scaleVector(u, 1/right.Value))
+			}
+		}
+
+	case *object.Number:
+		if right, ok := right.(*object.List); ok && operator == "*" {
+			if v, ok := vectorValues(right); ok {
+				return newVector(scaleVector(v, left.Value))
+			}
+		}
+	}
+
+	return unknown
+}
+
+func evalVectorVectorInfixExpression(operator string, u, v []float64) object.Object {
+	switch operator {
+	case "==":
+		return booleanObject(vectorEqual(u, v))
+	case "!=":
+		return booleanObject(!vectorEqual(u, v))
+	case "+", "-":
+		if len(u) != len(v) {
+			return newError("dimension mismatch: %d and %d elements", len(u), len(v))
+		}
+		values := make([]float64, len(u))
+		for i := range u {
+			if operator == "+" {
+				values[i] = u[i] + v[i]
+			} else {
+				values[i] = u[i] - v[i]
+			}
+		}
+		return newVector(values)
+	default:
+		return newError("unknown operator: %s %s %s", object.LIST_OBJ, operator, object.LIST_OBJ)
+	}
+}
+
+func scaleVector(v []float64, factor float64) []float64 {
+	values := make([]float64, len(v))
+	for i := range v {
+		values[i] = v[i] * factor
+	}
+	return values
+}
+
+func vectorEqual(u, v []float64) bool {
+	if len(u) != len(v) {
+		return false
+	}
+	for i := range u {
+		if u[i] != v[i] {
+			return false
+		}
+	}
+	return true
+}
+
+// vectorArg returns the values of the argument at index i, which should be a
+// list of numbers.
+func vectorArg(info builtinFuncInfo, args []object.Object, i int) ([]float64, *object.Error) {
+	if list, ok := args[i].(*object.List); ok {
+		if v, ok := vectorValues(list); ok {
+			return v, nil
+		}
+	}
+	return nil, newError(
+		"argument index %d of function %q should be a %s of numbers, got %s",
+		i, info.name, object.LIST_OBJ, args[i].Inspect(),
+	)
+}
+
+// vectorPairArgs checks the arguments of the builtins of two vectors of the
+// same length and returns their values.
+func vectorPairArgs(info builtinFuncInfo, args []object.Object) ([]float64, []float64, *object.Error) {
+	if err := checkArgsLength(info, args); err != nil {
+		return nil, nil, err
+	}
+	u, err := vectorArg(info, args, 0)
+	if err != nil {
+		return nil, nil, err
+	}
+	v, err := vectorArg(info, args, 1)
+	if err != nil {
+		return nil, nil, err
+	}
+	if len(u) != len(v) {
+		return nil, nil, newError("%q: dimension mismatch: %d and %d elements", info.name, len(u), len(v))
+	}
+	return u, v, nil
+}
+
+// matrixArgs checks the arguments of the builtins of a single matrix and
+// returns its rows.
+func matrixArgs(info builtinFuncInfo, args []object.Object) ([][]float64, *object.Error) {
+	if err := checkArgsLength(info, args); err != nil {
+		return nil, err
+	}
+	if err := checkArgsType(info, args); err != nil {
+		return nil, err
+	}
+	return args[0].(*object.Matrix).Rows, nil
+}
+
+// solveLinear returns x of Ax = b, b is a vector or a matrix of the right hand
+// sides in its columns.
+func solveLinear(info builtinFuncInfo, args []object.Object) object.Object {
+	if len(args) != 2 {
+		return newError("%q: a linear system should be solve(A, b), got %d arguments", info.name, len(args))
+	}
+	a := args[0].(*object.Matrix).Rows
+
+	if b, ok := args[1].(*object.Matrix); ok {
+		x, err := matrix.Solve(a, b.Rows)
+		if err != nil {
+			return newError("%q: %s", info.name, err)
+		}
+		return &object.Matrix{Rows: x}
+	}
+
+	b, err := vectorArg(info, args, 1)
+	if err != nil {
+		return err
+	}
+	x, solveErr := matrix.Solve(a, column(b))
+	if solveErr != nil {
+		return newError("%q: %s", info.name, solveErr)
+	}
+	return newVector(matrix.Transpose(x)[0])
+}
+
+func newMatrixList(matrices ...[][]float64) *object.List {
+	list := &object.List{Elements: make([]object.Object, len(matrices))}
+	for i, m := range matrices {
+		list.Elements[i] = &object.Matrix{Rows: m}
+	}
+	return list
+}
@@ -0,0 +1,423 @@
+package evaluator
+
+import (
+	"fmt"
+	"maps"
+	"strings"
+
+	"github.com/DeepAung/qcal/internal/ast"
+	"github.com/DeepAung/qcal/internal/object"
+)
+
+// memo checks the calls of the function body against builtinFuncs, see
+// purityWalker.read, so it cannot be in the literal of builtinFuncs.
+func init() {
+	builtinFuncs["memo"] = memoFunction
+}
+
+func memoFunction(args ...object.Object) object.Object {
+	info := infos["memo"]
+	if err := checkArgsLength(info, args); err != nil {
+		return err
+	}
+
+	if fn, ok := args[0].(*object.MemoFunction); ok {
+		return fn
+	}
+	params, body, env, ok := userFunction(args[0])
+	if !ok {
+		return newError(
+			"argument index 0 of function %q should be a user function, got %s",
+			info.name, args[0].Type(),
+		)
+	}
+
+	if err := checkPurity(info, params, body, env, nil); err != nil {
+		return err
+	}
+	return &object.MemoFunction{Function: args[0], Cache: map[string]object.Object{}}
+}
+
+// applyMemoFunction returns the cached result of the arguments. A call without
+// all the arguments returns the memo function with the given ones, so the
+// later call is cached too. The function is checked again at the first call,
+// so a name which was not bound at memo is the memo function itself.
+func applyMemoFunction(fn *object.MemoFunction, args []object.Object) object.Object {
+	if !fn.Checked {
+		params, body, env, _ := userFunction(fn.Function)
+		if err := checkPurity(infos["memo"], params, body, env, fn); err != nil {
+			return err
+		}
+		fn.Checked = true
+	}
+
+	key, isKey := memoKey(args)
+	if result, ok := fn.Cache[key]; isKey && ok {
+		return result
+	}
+
+	result := applyFunction(fn.Function, args)
+	if partial, ok := result.(*object.PartialFunction); ok {
+		return &object.PartialFunction{Function: fn, Arguments: args, Open: partial.Open}
+	}
+	if isKey && !IsError(result) {
+		fn.Cache[key] = result
+	}
+	return result
+}
+
+// memoKey returns the cache key of the argument values, it reports false if
+// an argument is not a value, e.g. a function.
+func memoKey(args []object.Object) (string, bool) {
+	var sb strings.Builder
+	for _, arg := range args {
+		if !isMemoValue(arg) {
+			return "", false
+		}
+		s := arg.Inspect()
+		fmt.Fprintf(&sb, "%s%d:%s", arg.Type(), len(s), s)
+	}
+	return sb.String(), true
+}
+
+func isMemoValue(obj object.Object) bool {
+	switch obj := obj.(type) {
+	case *object.Number, *object.Boolean, *object.String, *object.Complex, *object.Matrix, *object.Polynomial:
+		return true
+	case *object.List:
+		for _, element := range obj.Elements {
+			if !isMemoValue(element) {
+				return false
+			}
+		}
+		return true
+	case *object.NamedArgument:
+		return isMemoValue(obj.Value)
+	default:
+		return false
+	}
+}
+
+// checkPurity returns an error if the function body reads a variable of the
+// outer environments, which may change, or calls a builtin with side effects.
+// The constants of the function environment can be read, and the constant
+// functions are checked the same way. The bindings of the modules are checked
+// as the outer ones. The memo function self can be called, e.g.
+// `fib = memo(n => ... fib(n - 1) ...)`. It is nil at memo, before it is
+// bound, so a called name which is not bound yet is accepted until the first
+// call. The unknowns of the equations are not variables.
+func checkPurity(
+	info builtinFuncInfo,
+	params []*ast.Parameter,
+	body ast.Node,
+	env *object.Environment,
+	self *object.MemoFunction,
+) *object.Error {
+	w := purityWalker{info, "the function", map[string]bool{}, env, map[object.Object]bool{}, self}
+	w, err := w.enclosed(params)
+	if err != nil {
+		return err
+	}
+	return w.walk(body)
+}
+
+// purityWalker walks the statements in order, the names are local after their
+// declaration, or their first assignment if they are not bound outside, until
+// the end of their block.
+type purityWalker struct {
+	info     builtinFuncInfo
+	subject  string // the start of the error messages, e.g. "the function calls g, which"
+	locals   map[string]bool
+	env      *object.Environment
+	visiting map[object.Object]bool // the constant functions being checked
+	self     *object.MemoFunction   // the memo function at its first call, nil at memo
+}
+
+func (w purityWalker) fail(format string, a ...any) *object.Error {
+	return newError("%q: %s "+format, append([]any{w.info.name, w.subject}, a...)...)
+}
+
+func (w purityWalker) walk(node ast.Node) *object.Error {
+	switch node := node.(type) {
+	case *ast.BlockStatement:
+		for _, stmt := range node.Statements {
+			if err := w.walk(stmt); err != nil {
+				return err
+			}
+		}
+	case *ast.ExpressionStatement:
+		return w.walk(node.Expression)
+	case *ast.LetStatement:
+		if err := w.walk(node.Value); err != nil {
+			return err
+		}
+		name := node.Name.Value
+		if _, isOuter := w.env.Get(name); isOuter && !node.Declare && !w.locals[name] {
+			return w.fail("assigns the outer variable %s", name)
+		}
+		w.locals[name] = true
+	case *ast.ReturnStatement:
+		return w.walk(node.Value)
+	case *ast.ImportStatement:
+		w.locals[node.Name.Value] = true
+
+	case *ast.Identifier:
+		return w.read(node.Value)
+	case *ast.ListLiteral:
+		return w.walkAll(node.Elements...)
+	case *ast.PrefixExpression:
+		return w.walk(node.Right)
+	case *ast.PostfixExpression:
+		return w.walk(node.Left)
+	case *ast.InfixExpression:
+		// the right of `lib.fn`, `e.message` or `f . g` is the binding of a
+		// module, a member of an error or a function
+		if ident, ok := node.Right.(*ast.Identifier); ok && node.Operator == "." {
+			if module, name, ok := w.module(node.Left); ok {
+				return w.member(module, name+"."+ident.Value, ident.Value)
+			}
+			if err := w.walk(node.Left); err != nil {
+				return err
+			}
+			if obj, ok := w.env.Get(ident.Value); ok && !w.locals[ident.Value] && !isFunction(obj) {
+				return nil
+			}
+			if !w.locals[ident.Value] && !isBound(w.env, ident.Value) {
+				return nil // a member of an error
+			}
+			return w.call(ident.Value)
+		}
+		return w.walkAll(node.Left, node.Right)
+	case *ast.IfExpression:
+		if err := w.walk(node.Condition); err != nil {
+			return err
+		}
+		if err := w.block().walk(node.Consequence); err != nil {
+			return err
+		}
+		if node.Alternative != nil {
+			return w.block().walk(node.Alternative)
+		}
+	case *ast.WhileExpression:
+		if err := w.walk(node.Condition); err != nil {
+			return err
+		}
+		return w.block().walk(node.Body)
+	case *ast.ForExpression:
+		if err := w.walk(node.Iterable); err != nil {
+			return err
+		}
+		body := w.block()
+		body.locals[node.Variable.Value] = true
+		return body.walk(node.Body)
+	case *ast.TryExpression:
+		if err := w.block().walk(node.Body); err != nil {
+			return err
+		}
+		handler := w.block()
+		if node.Variable != nil {
+			handler.locals[node.Variable.Value] = true
+		}
+		return handler.walk(node.Handler)
+	case *ast.NormalFunctionLiteral:
+		enclosed, err := w.enclosed(node.Parameters)
+		if err != nil {
+			return err
+		}
+		return enclosed.walk(node.Body)
+	case *ast.ConciseFunctionLiteral:
+		enclosed, err := w.enclosed(node.Parameters)
+		if err != nil {
+			return err
+		}
+		return enclosed.walk(node.Body)
+
+	case *ast.CallExpression:
+		if ident, ok := node.Function.(*ast.Identifier); ok {
+			if err := w.call(ident.Value); err != nil {
+				return err
+			}
+		} else if err := w.walk(node.Function); err != nil {
+			return err
+		}
+		return w.walkAll(node.Arguments...)
+	case *ast.IndexExpression:
+		return w.walkAll(node.Left, node.Index)
+	case *ast.NamedArgument:
+		return w.walk(node.Value)
+	}
+	return nil
+}
+
+func (w purityWalker) walkAll(exps ...ast.Expression) *object.Error {
+	for _, exp := range exps {
+		if err := w.walk(exp); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (w purityWalker) read(name string) *object.Error {
+	if w.locals[name] {
+		return nil
+	}
+	if obj, ok := w.env.Get(name); ok {
+		if w.isSelf(obj) {
+			return nil
+		}
+		if !w.env.IsConst(name) {
+			return w.fail("reads the outer variable %s", name)
+		}
+		return w.function(name, obj)
+	}
+	if _, ok := builtinValues[name]; ok {
+		return nil
+	}
+	if _, ok := builtinFuncs[name]; ok {
+		return w.call(name)
+	}
+	if _, ok := randomFuncs[name]; ok {
+		return w.call(name)
+	}
+	if _, ok := fileFuncs[name]; ok {
+		return w.call(name)
+	}
+	return w.fail("reads the outer variable %s", name)
+}
+
+func (w purityWalker) call(name string) *object.Error {
+	if w.locals[name] {
+		return nil
+	}
+	if obj, ok := w.env.Get(name); ok {
+		if w.isSelf(obj) {
+			return nil
+		}
+		if !w.env.IsConst(name) {
+			return w.fail("calls the outer function %s, which is not a constant", name)
+		}
+		return w.function(name, obj)
+	}
+	_, isRandom := randomFuncs[name]
+	if _, isFile := fileFuncs[name]; isRandom || isFile {
+		return w.fail("calls %s, which has side effects", name)
+	}
+	if w.self != nil && !isBound(w.env, name) {
+		return w.fail("calls %s, which is not defined", name)
+	}
+	return nil
+}
+
+// isSelf reports whether the object is the memo function being called.
+func (w purityWalker) isSelf(obj object.Object) bool {
+	return w.self != nil && obj == object.Object(w.self)
+}
+
+// function checks the body of the constant function of the name, in the
+// environment of the function.
+func (w purityWalker) function(name string, obj object.Object) *object.Error {
+	if w.visiting[obj] {
+		return nil
+	}
+	w.visiting[obj] = true
+	defer delete(w.visiting, obj)
+
+	switch fn := obj.(type) {
+	case *object.PartialFunction:
+		return w.function(name, fn.Function)
+	case *object.ComposedFunction:
+		if err := w.function(name, fn.Outer); err != nil {
+			return err
+		}
+		return w.function(name, fn.Inner)
+	}
+	params, body, env, ok := userFunction(obj)
+	if !ok {
+		return nil // a memo function is checked by memo, the other objects are values
+	}
+
+	called := purityWalker{w.info, w.subject + " calls " + name + ", which", map[string]bool{}, env, w.visiting, w.self}
+	called, err := called.enclosed(params)
+	if err != nil {
+		return err
+	}
+	return called.walk(body)
+}
+
+// module returns the outer module of the expression, e.g. `lib` or
+// `lib.units`, and its name in the messages.
+func (w purityWalker) module(exp ast.Expression) (*object.Module, string, bool) {
+	switch exp := exp.(type) {
+	case *ast.Identifier:
+		if w.locals[exp.Value] {
+			return nil, "", false
+		}
+		obj, _ := w.env.Get(exp.Value)
+		module, ok := obj.(*object.Module)
+		return module, exp.Value, ok
+	case *ast.InfixExpression:
+		ident, ok := exp.Right.(*ast.Identifier)
+		if !ok || exp.Operator != "." {
+			return nil, "", false
+		}
+		outer, name, ok := w.module(exp.Left)
+		if !ok {
+			return nil, "", false
+		}
+		obj, _ := outer.Env.Get(ident.Value)
+		module, ok := obj.(*object.Module)
+		return module, name + "." + ident.Value, ok
+	}
+	return nil, "", false
+}
+
+// member checks the binding of the module as the outer bindings, a variable
+// of the module may be assigned by its functions.
+func (w purityWalker) member(module *object.Module, qualified, name string) *object.Error {
+	obj, ok := module.Env.Get(name)
+	if !ok {
+		return nil // an error of the evaluation
+	}
+	if !module.Env.IsConst(name) {
+		if isFunction(obj) {
+			return w.fail("calls the outer function %s, which is not a constant", qualified)
+		}
+		return w.fail("reads the outer variable %s", qualified)
+	}
+	return w.function(qualified, obj)
+}
+
+// enclosed returns the walker of a function, whose parameters and assignments
+// are local to it. The default values of the parameters are walked in order.
+func (w purityWalker) enclosed(params []*ast.Parameter) (purityWalker, *object.Error) {
+	enclosed := w.block()
+	for _, param := range params {
+		if param.Default != nil {
+			if err := enclosed.walk(param.Default); err != nil {
+				return enclosed, err
+			}
+		}
+		enclosed.locals[param.Name.Value] = true
+	}
+	return enclosed, nil
+}
+
+// block returns the walker of a block, whose declarations and assignments are
+// local to it.
+func (w purityWalker) block() purityWalker {
+	w.locals = maps.Clone(w.locals)
+	return w
+}
+
+// userFunction returns the parts of a normal or concise function.
+func userFunction(obj object.Object) ([]*ast.Parameter, ast.Node, *object.Environment, bool) {
+	switch fn := obj.(type) {
+	case *object.NormalFunction:
+		return fn.Parameters, fn.Body, fn.Env, true
+	case *object.ConciseFunction:
+		return fn.Parameters, fn.Body, fn.Env, true
+	default:
+		return nil, nil, nil, false
+	}
+}
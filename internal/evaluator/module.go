@@ -0,0 +1,80 @@
+package evaluator
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+
+	"github.com/DeepAung/qcal/internal/ast"
+	"github.com/DeepAung/qcal/internal/lexer"
+	"github.com/DeepAung/qcal/internal/object"
+	"github.com/DeepAung/qcal/internal/parser"
+)
+
+// evalImportStatement evaluates the file into a module bound to its name. A
+// relative path is resolved from the directory of the importing file, or from
+// the working directory outside of a file.
+func evalImportStatement(is *ast.ImportStatement, env *object.Environment) object.Object {
+	if err := checkAssignable(is.Name.Value, true, env); err != nil {
+		return err
+	}
+
+	file := is.Path
+	if !filepath.IsAbs(file) {
+		if importing := env.File(); importing != "" {
+			file = filepath.Join(filepath.Dir(importing), file)
+		}
+	}
+	file, err := filepath.Abs(file)
+	if err != nil {
+		return newError("cannot import %q: %s", is.Path, err)
+	}
+
+	imports := env.Imports()
+	if i := slices.Index(imports, file); i >= 0 {
+		cycle := []string{filepath.Base(file)}
+		for j := i - 1; j >= 0; j-- {
+			cycle = append(cycle, filepath.Base(imports[j]))
+		}
+		cycle = append(cycle, filepath.Base(file))
+		return newError("import cycle: %s", strings.Join(cycle, " imports "))
+	}
+
+	input, err := os.ReadFile(file)
+	if err != nil {
+		return newError("cannot import %q: %s", is.Path, err)
+	}
+	program, errMessages := parser.New(lexer.New(string(input))).ParseProgram()
+	if len(errMessages) > 0 {
+		return newError("cannot import %q: %s", is.Path, strings.Join(errMessages, ", "))
+	}
+
+	moduleEnv := object.NewModuleEnvironment(file, env)
+	if err, ok := Eval(program, moduleEnv).(*object.Error); ok {
+		return moduleError(file, err)
+	}
+
+	module := &object.Module{Name: is.Name.Value, File: file, Env: moduleEnv}
+	env.Set(is.Name.Value, module)
+	return &object.LetValue{Value: module}
+}
+
+// moduleError returns the error of the module prefixed with its file and
+// position, as its position is not in the importing input.
+func moduleError(file string, err *object.Error) *object.Error {
+	prefix := filepath.Base(file)
+	if err.Line > 0 {
+		prefix = fmt.Sprintf("%s:%d:%d", prefix, err.Line, err.Column)
+	}
+	return &object.Error{Message: prefix + ": " + err.Message, Code: err.Code}
+}
+
+// moduleMember returns the binding of the module, e.g. `lib.fn`.
+func moduleMember(module *object.Module, name string) object.Object {
+	if val, ok := module.Env.Get(name); ok {
+		return val
+	}
+	return newError("identifier not found: %s.%s", module.Name, name)
+}
@@ -0,0 +1,260 @@
+package evaluator
+
+import (
+	"errors"
+	"math"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+
+	"github.com/DeepAung/qcal/internal/object"
+	"github.com/DeepAung/qcal/internal/plot"
+)
+
+// plot and plotfile sample the functions with applyFunction, which evaluates
+// the identifiers of the function bodies in builtinFuncs and fileFuncs, so
+// they cannot be in the literals of those maps.
+func init() {
+	builtinFuncs["plot"] = plotFunction
+	fileFuncs["plotfile"] = newPlotFileFunction
+}
+
+// fileFuncs are the builtins which write files, they do not write them in a
+// sandbox environment, see object.Environment.Sandbox.
+var fileFuncs = map[string]func(write bool) object.BuiltinFunction{}
+
+func plotFunction(args ...object.Object) object.Object {
+	info := infos["plot"]
+	fns, xmin, xmax, err := plotArgs(info, args, 0)
+	if err != nil {
+		return err
+	}
+
+	p := &object.Plot{}
+	for _, fn := range fns {
+		series, err := sampleFunction(info, fn, xmin, xmax, plot.Samples(plot.Width))
+		if err != nil {
+			return err
+		}
+		p.Series = append(p.Series, series)
+	}
+
+	return p
+}
+
+func newPlotFileFunction(write bool) object.BuiltinFunction {
+	return func(args ...object.Object) object.Object {
+		return plotFile(write, args)
+	}
+}
+
+func plotFile(write bool, args []object.Object) object.Object {
+	info := infos["plotfile"]
+	if len(args) == 0 || args[0].Type() != object.STRING_OBJ {
+		if len(args) == 0 {
+			return newError("%q: not enough arguments, expect at least 4, got=0", info.name)
+		}
+		return newError(
+			"argument index 0 of function %q should be type %s, got %s",
+			info.name, object.STRING_OBJ, args[0].Type(),
+		)
+	}
+	path := args[0].(*object.String).Value
+	if !filepath.IsLocal(path) {
+		return newError("%q: path %q is outside of the working directory", info.name, path)
+	}
+
+	opts := plot.DefaultOptions
+	rest := args[1:]
+	if len(rest) > 0 && rest[len(rest)-1].Type() == object.STRING_OBJ {
+		var err *object.Error
+		opts, err = parsePlotOptions(info, rest[len(rest)-1].(*object.String).Value)
+		if err != nil {
+			return err
+		}
+		rest = rest[:len(rest)-1]
+	}
+
+	fns, xmin, xmax, err := plotArgs(info, rest, 1)
+	if err != nil {
+		return err
+	}
+
+	data, err := renderPlotFile(info, path, fns, xmin, xmax, opts)
+	if err != nil {
+		return err
+	}
+
+	if !write {
+		return &object.String{Value: path}
+	}
+	if err := os.WriteFile(path, data, 0o644); err != nil {
+		return newError("%q: %s", info.name, err)
+	}
+	return &object.String{Value: path}
+}
+
+// WritePlotFile writes the plot of the functions to the .svg or .png file at
+// the path, which unlike the plotfile path may be outside of the working
+// directory.
+func WritePlotFile(path string, fns []object.Object, xmin, xmax float64) error {
+	info := infos["plotfile"]
+	args := append(fns[:len(fns):len(fns)], newNumber(xmin), newNumber(xmax))
+	fns, xmin, xmax, err := plotArgs(info, args, 1)
+	if err != nil {
+		return errors.New(err.Inspect())
+	}
+
+	data, err := renderPlotFile(info, path, fns, xmin, xmax, plot.DefaultOptions)
+	if err != nil {
+		return errors.New(err.Inspect())
+	}
+	return os.WriteFile(path, data, 0o644)
+}
+
+// renderPlotFile returns the content of the plot file, in the format of the
+// path extension.
+func renderPlotFile(
+	info builtinFuncInfo,
+	path string,
+	fns []object.Object,
+	xmin, xmax float64,
+	opts plot.Options,
+) ([]byte, *object.Error) {
+	var series []plot.Series
+	for _, fn := range fns {
+		s, err := sampleFunction(info, fn, xmin, xmax, opts.Width)
+		if err != nil {
+			return nil, err
+		}
+		series = append(series, s)
+	}
+
+	switch strings.ToLower(filepath.Ext(path)) {
+	case ".svg":
+		return plot.SVG(series, opts), nil
+	case ".png":
+		data, err := plot.PNG(series, opts)
+		if err != nil {
+			return nil, newError("%q: %s", info.name, err)
+		}
+		return data, nil
+	default:
+		return nil, newError("%q: unsupported file extension %q, expect .svg or .png", info.name, filepath.Ext(path))
+	}
+}
+
+// parsePlotOptions parses the plot file options, e.g. "width=800 height=600"
+func parsePlotOptions(info builtinFuncInfo, input string) (plot.Options, *object.Error) {
+	opts := plot.DefaultOptions
+
+	for _, field := range strings.FieldsFunc(input, func(r rune) bool { return r == ' ' || r == ',' }) {
+		key, value, _ := strings.Cut(field, "=")
+		if key != "width" && key != "height" {
+			return opts, newError("%q: unknown option %q, expect width or height", info.name, key)
+		}
+
+		n, err := strconv.Atoi(value)
+		if err != nil || n < 200 || n > 10000 {
+			return opts, newError("%q: invalid option %q, expect a size from 200 to 10000", info.name, field)
+		}
+
+		if key == "width" {
+			opts.Width = n
+		} else {
+			opts.Height = n
+		}
+	}
+
+	return opts, nil
+}
+
+// plotArgs checks the `f..., xmin, xmax` arguments of the plot builtins, offset
+// is the index of the first function in the builtin arguments.
+func plotArgs(
+	info builtinFuncInfo,
+	args []object.Object,
+	offset int,
+) ([]object.Object, float64, float64, *object.Error) {
+	if len(args) < 3 {
+		return nil, 0, 0, newError(
+			"%q: not enough arguments, expect at least %d, got=%d",
+			info.name, offset+3, offset+len(args),
+		)
+	}
+
+	fns, bounds := args[:len(args)-2], args[len(args)-2:]
+	for i, fn := range fns {
+		if !isFunction(fn) {
+			return nil, 0, 0, newError(
+				"argument index %d of function %q should be type %s, got %s",
+				offset+i, info.name, object.FUNCTION_OBJ, fn.Type(),
+			)
+		}
+	}
+	for i, bound := range bounds {
+		if bound.Type() != object.NUMBER_OBJ {
+			return nil, 0, 0, newError(
+				"argument index %d of function %q should be type %s, got %s",
+				offset+len(fns)+i, info.name, object.NUMBER_OBJ, bound.Type(),
+			)
+		}
+	}
+
+	xmin := bounds[0].(*object.Number).Value
+	xmax := bounds[1].(*object.Number).Value
+	if !(xmin < xmax) {
+		return nil, 0, 0, newError("%q: xmin should be less than xmax, got %v and %v", info.name, xmin, xmax)
+	}
+
+	return fns, xmin, xmax, nil
+}
+
+// sampleFunction calls the function at n evenly spaced x values, the values
+// out of its domain are NaN.
+func sampleFunction(
+	info builtinFuncInfo,
+	fn object.Object,
+	xmin, xmax float64,
+	n int,
+) (plot.Series, *object.Error) {
+	series := plot.Series{Label: functionLabel(fn), Xs: plot.Xs(xmin, xmax, n)}
+	series.Ys = make([]float64, n)
+
+	for i, x := range series.Xs {
+		result := unwrapEstimate(applyFunction(fn, []object.Object{newNumber(x)}))
+		if isDomainError(result) {
+			series.Ys[i] = math.NaN() // a gap of the plot, e.g. ln left of 0
+			continue
+		}
+		if err, ok := result.(*object.Error); ok {
+			return series, err
+		}
+
+		number, ok := result.(*object.Number)
+		if !ok {
+			return series, newError("%q: function should return %s, got %s", info.name, object.NUMBER_OBJ, typeOf(result))
+		}
+		series.Ys[i] = number.Value
+	}
+
+	return series, nil
+}
+
+func isFunction(obj object.Object) bool {
+	return obj.Type() == object.FUNCTION_OBJ || obj.Type() == object.BUILTIN_FUNCTION_OBJ ||
+		obj.Type() == object.POLYNOMIAL_OBJ
+}
+
+// functionLabel returns the function source on a single line.
+func functionLabel(fn object.Object) string {
+	return strings.Join(strings.Fields(fn.Inspect()), " ")
+}
+
+func typeOf(obj object.Object) object.ObjectType {
+	if obj == nil {
+		return object.NULL_OBJ
+	}
+	return obj.Type()
+}
@@ -0,0 +1,161 @@
+package evaluator
+
+import (
+	"math"
+	"math/cmplx"
+	"slices"
+
+	"github.com/DeepAung/qcal/internal/object"
+	"github.com/DeepAung/qcal/internal/polynomial"
+)
+
+// evalPolynomialInfixExpression evaluates the operators of a polynomial and a
+// polynomial or a number, the number is a constant polynomial.
+func evalPolynomialInfixExpression(operator string, left, right object.Object) object.Object {
+	p, q := toCoeffs(left), toCoeffs(right)
+
+	switch operator {
+	case "+":
+		return &object.Polynomial{Coeffs: polynomial.Add(p, q)}
+	case "-":
+		return &object.Polynomial{Coeffs: polynomial.Sub(p, q)}
+	case "*":
+		return &object.Polynomial{Coeffs: polynomial.Mul(p, q)}
+	case "/", "%":
+		if len(q) == 0 {
+			return newError("division by the zero polynomial")
+		}
+		quotient, remainder := polynomial.DivMod(p, q)
+		if operator == "%" {
+			return &object.Polynomial{Coeffs: remainder}
+		}
+		return &object.Polynomial{Coeffs: quotient}
+	case "^":
+		n, ok := right.(*object.Number)
+		if !ok || n.Value < 0 || n.Value != math.Trunc(n.Value) {
+			return newError("the power of a polynomial should be a non-negative integer, got %s", right.Inspect())
+		}
+		return &object.Polynomial{Coeffs: polynomial.Pow(p, int(n.Value))}
+	case "==":
+		return booleanObject(slices.Equal(p, q))
+	case "!=":
+		return booleanObject(!slices.Equal(p, q))
+	default:
+		return newError("unknown operator: %s %s %s", left.Type(), operator, right.Type())
+	}
+}
+
+func toCoeffs(obj object.Object) []float64 {
+	if p, ok := obj.(*object.Polynomial); ok {
+		return p.Coeffs
+	}
+	return polynomial.Trim([]float64{obj.(*object.Number).Value})
+}
+
+// evalComplexInfixExpression evaluates the operators of a complex number and
+// a complex number or a real number.
+func evalComplexInfixExpression(operator string, left, right object.Object) object.Object {
+	a, b := toComplex(left), toComplex(right)
+
+	switch operator {
+	case "+":
+		return newComplex(a + b)
+	case "-":
+		return newComplex(a - b)
+	case "*":
+		return newComplex(a * b)
+	case "/":
+		return newComplex(a / b)
+	case "^":
+		if n := real(b); imag(b) == 0 && n == math.Trunc(n) && math.Abs(n) <= 64 {
+			return newComplex(complexPowInt(a, int(n)))
+		}
+		return newComplex(cmplx.Pow(a, b))
+	case "==":
+		return booleanObject(a == b)
+	case "!=":
+		return booleanObject(a != b)
+	default:
+		return newError("unknown operator: %s %s %s", left.Type(), operator, right.Type())
+	}
+}
+
+// complexPowInt returns z^n by repeated squaring, which is exact for the small
+// integer parts, unlike cmplx.Pow.
+func complexPowInt(z complex128, n int) complex128 {
+	if n < 0 {
+		return 1 / complexPowInt(z, -n)
+	}
+	result := complex(1, 0)
+	for ; n > 0; n /= 2 {
+		if n%2 == 1 {
+			result *= z
+		}
+		z *= z
+	}
+	return result
+}
+
+func toComplex(obj object.Object) complex128 {
+	if c, ok := obj.(*object.Complex); ok {
+		return c.Value
+	}
+	return complex(obj.(*object.Number).Value, 0)
+}
+
+// newComplex returns a number if the imaginary part is zero.
+func newComplex(value complex128) object.Object {
+	if imag(value) == 0 {
+		return newNumber(real(value))
+	}
+	return &object.Complex{Value: value}
+}
+
+// complexArg checks that the single argument of the builtin is a complex or a
+// real number and returns its value.
+func complexArg(info builtinFuncInfo, args []object.Object) (complex128, *object.Error) {
+	if err := checkArgsLength(info, args); err != nil {
+		return 0, err
+	}
+	switch arg := args[0].(type) {
+	case *object.Complex:
+		return arg.Value, nil
+	case *object.Number:
+		return complex(arg.Value, 0), nil
+	default:
+		return 0, newError(
+			"argument index 0 of function %q should be type %s or %s, got %s",
+			info.name, object.COMPLEX_OBJ, object.NUMBER_OBJ, arg.Type(),
+		)
+	}
+}
+
+// applyPolynomial evaluates the polynomial at a real or complex number.
+func applyPolynomial(p *object.Polynomial, args []object.Object) object.Object {
+	if len(args) != 1 {
+		return newError("polynomial should be called with 1 argument, got=%d", len(args))
+	}
+
+	switch arg := args[0].(type) {
+	case *object.Number:
+		return newNumber(polynomial.Evaluate(p.Coeffs, arg.Value))
+	case *object.Complex:
+		return newComplex(polynomial.EvaluateComplex(p.Coeffs, arg.Value))
+	default:
+		return newError("polynomial should be called with %s, got %s", object.NUMBER_OBJ, arg.Type())
+	}
+}
+
+// polynomialRoots returns the list of all the complex roots of the polynomial.
+func polynomialRoots(info builtinFuncInfo, p *object.Polynomial) object.Object {
+	if polynomial.Degree(p.Coeffs) < 1 {
+		return newError("%q: polynomial should have a degree of 1 or more, got %s", info.name, p.Inspect())
+	}
+
+	roots := polynomial.Roots(p.Coeffs)
+	list := &object.List{Elements: make([]object.Object, len(roots))}
+	for i, root := range roots {
+		list.Elements[i] = newComplex(root)
+	}
+	return list
+}
@@ -0,0 +1,114 @@
+package evaluator
+
+import (
+	"strings"
+
+	"github.com/DeepAung/qcal/internal/distribution"
+	"github.com/DeepAung/qcal/internal/object"
+)
+
+// distributions are the probability distributions of the builtins prefix+pdf,
+// prefix+cdf and prefix+inv, e.g. normpdf(x, mu, sigma), normcdf(x, mu, sigma)
+// and norminv(q, mu, sigma).
+var distributions = []struct {
+	prefix   string
+	name     string
+	discrete bool
+	params   []string
+	new      func(params []float64) (distribution.Distribution, error)
+}{
+	{"norm", "normal", false, []string{"mu", "sigma"}, func(p []float64) (distribution.Distribution, error) {
+		return distribution.Normal(p[0], p[1])
+	}},
+	{"binom", "binomial", true, []string{"n", "p"}, func(p []float64) (distribution.Distribution, error) {
+		return distribution.Binomial(p[0], p[1])
+	}},
+	{"poiss", "poisson", true, []string{"lambda"}, func(p []float64) (distribution.Distribution, error) {
+		return distribution.Poisson(p[0])
+	}},
+	{"unif", "uniform", false, []string{"a", "b"}, func(p []float64) (distribution.Distribution, error) {
+		return distribution.Uniform(p[0], p[1])
+	}},
+	{"exp", "exponential", false, []string{"lambda"}, func(p []float64) (distribution.Distribution, error) {
+		return distribution.Exponential(p[0])
+	}},
+	{"t", "student-t", false, []string{"nu"}, func(p []float64) (distribution.Distribution, error) {
+		return distribution.StudentT(p[0])
+	}},
+	{"chisq", "chi-square", false, []string{"k"}, func(p []float64) (distribution.Distribution, error) {
+		return distribution.ChiSquared(p[0])
+	}},
+}
+
+func init() {
+	for _, d := range distributions {
+		types := make([]object.ObjectType, len(d.params)+1)
+		for i := range types {
+			types[i] = object.NUMBER_OBJ
+		}
+		params := strings.Join(d.params, ", ")
+
+		density := "density"
+		if d.discrete {
+			density = "mass"
+		}
+		docs := map[string]string{
+			"pdf": "returns the probability " + density + " at x of the " + d.name + " distribution of " + params,
+			"cdf": "returns the probability of a value less than or equal to x of the " + d.name +
+				" distribution of " + params,
+			"inv": "returns the quantile, the inverse of the cumulative distribution function, at q of the " +
+				d.name + " distribution of " + params,
+		}
+
+		for _, kind := range []string{"pdf", "cdf", "inv"} {
+			name := d.prefix + kind
+			x := "x"
+			if kind == "inv" {
+				x = "q" // not p, which is a parameter of binominv
+			}
+			infos[name] = builtinFuncInfo{
+				name:   name,
+				len:    len(types),
+				types:  types,
+				params: append([]string{x}, d.params...),
+				doc:    docs[kind],
+			}
+			builtinFuncs[name] = distributionFunction(name, kind, d.new)
+		}
+	}
+}
+
+func distributionFunction(
+	name string,
+	kind string,
+	newDistribution func(params []float64) (distribution.Distribution, error),
+) object.BuiltinFunction {
+	return func(args ...object.Object) object.Object {
+		info := infos[name]
+		if err := checkArgsLength(info, args); err != nil {
+			return err
+		}
+		if err := checkArgsType(info, args); err != nil {
+			return err
+		}
+		values, _ := numberArgs(info, args, 0)
+
+		d, err := newDistribution(values[1:])
+		if err != nil {
+			return newError("%q: %s", info.name, err)
+		}
+
+		x := values[0]
+		switch kind {
+		case "pdf":
+			return newNumber(d.PDF(x))
+		case "cdf":
+			return newNumber(d.CDF(x))
+		default:
+			if !(x >= 0 && x <= 1) {
+				return newError("%q: q should be from 0 to 1, got %s", info.name, args[0].Inspect())
+			}
+			return newNumber(d.Quantile(x))
+		}
+	}
+}
@@ -0,0 +1,58 @@
+package evaluator
+
+import (
+	"math"
+
+	"github.com/DeepAung/qcal/internal/object"
+)
+
+// randomFuncs are the builtins of the random number generator of the
+// environment, so every calculator has its own seeded numbers.
+var randomFuncs = map[string]func(random *object.Random) object.BuiltinFunction{
+	"rand": func(random *object.Random) object.BuiltinFunction {
+		return func(args ...object.Object) object.Object {
+			if err := checkArgsLength(infos["rand"], args); err != nil {
+				return err
+			}
+			return newNumber(random.Float64())
+		}
+	},
+	"randint": func(random *object.Random) object.BuiltinFunction {
+		return func(args ...object.Object) object.Object {
+			info := infos["randint"]
+			if err := checkArgsLength(info, args); err != nil {
+				return err
+			}
+			if err := checkArgsType(info, args); err != nil {
+				return err
+			}
+
+			a, b := args[0].(*object.Number).Value, args[1].(*object.Number).Value
+			if a != math.Trunc(a) || b != math.Trunc(b) || math.Abs(a) > 1<<53 || math.Abs(b) > 1<<53 {
+				return newError("%q: bounds should be integers, got %s and %s", info.name, args[0].Inspect(), args[1].Inspect())
+			}
+			if a > b {
+				return newError("%q: a should not be greater than b, got %s and %s", info.name, args[0].Inspect(), args[1].Inspect())
+			}
+			return newNumber(a + float64(random.Int64N(int64(b-a)+1)))
+		}
+	},
+	"seed": func(random *object.Random) object.BuiltinFunction {
+		return func(args ...object.Object) object.Object {
+			info := infos["seed"]
+			if err := checkArgsLength(info, args); err != nil {
+				return err
+			}
+			if err := checkArgsType(info, args); err != nil {
+				return err
+			}
+
+			n := args[0].(*object.Number).Value
+			if n != math.Trunc(n) || math.Abs(n) >= 1<<63 {
+				return newError("%q: seed should be an integer, got %s", info.name, args[0].Inspect())
+			}
+			random.Seed(uint64(int64(n)))
+			return NULL
+		}
+	},
+}
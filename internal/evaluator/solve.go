@@ -0,0 +1,556 @@
+package evaluator
+
+import (
+	"math"
+	"sort"
+	"strings"
+
+	"github.com/DeepAung/qcal/internal/ast"
+	"github.com/DeepAung/qcal/internal/object"
+	"github.com/DeepAung/qcal/internal/polynomial"
+	"github.com/DeepAung/qcal/internal/symbolic"
+	"github.com/DeepAung/qcal/internal/token"
+)
+
+// solve and roots look up the unknowns of the equations in builtinFuncs, see
+// freeIdentifiers, and evaluate the function with numericFunction.call, so
+// they cannot be in the literal of builtinFuncs.
+func init() {
+	builtinFuncs["solve"] = solveFunction
+	builtinFuncs["roots"] = rootsFunction
+}
+
+func solveFunction(args ...object.Object) object.Object {
+	info := infos["solve"]
+	if len(args) == 0 || len(args) > 3 {
+		return newError("%q: expect 1 to 3 arguments, got=%d", info.name, len(args))
+	}
+	if _, ok := args[0].(*object.Matrix); ok {
+		return solveLinear(info, args)
+	}
+	bounds, err := numberArgs(info, args, 1)
+	if err != nil {
+		return err
+	}
+
+	target, err := newSolveTarget(info, args[0])
+	if err != nil {
+		return err
+	}
+
+	guess := 0.0
+	if len(bounds) >= 1 {
+		guess = bounds[0]
+	}
+
+	if target.coeffs != nil {
+		switch degree := polynomial.Degree(target.coeffs); {
+		case degree == -1:
+			return newError("%q: every number is a solution", info.name)
+		case degree == 0:
+			return newError("%q: the equation has no solution", info.name)
+		case degree <= 3:
+			roots := polynomial.RealRoots(target.coeffs)
+			if len(bounds) == 2 {
+				roots = rootsInInterval(roots, bounds[0], bounds[1])
+			}
+			if len(roots) == 0 {
+				return newError("%q: the equation has no real solution", info.name)
+			}
+			return newNumber(closestTo(roots, guess))
+		}
+	}
+
+	f := newNumericFunction(info, target.fn)
+	var root float64
+	var solveErr *object.Error
+	if len(bounds) == 2 {
+		root, solveErr = solveInterval(info, f, bounds[0], bounds[1])
+	} else {
+		root, solveErr = solveNear(info, f, guess)
+	}
+
+	if f.err != nil {
+		return f.err
+	}
+	if solveErr != nil {
+		return solveErr
+	}
+	return newNumber(root)
+}
+
+func rootsFunction(args ...object.Object) object.Object {
+	info := infos["roots"]
+	if p, ok := firstPolynomial(args); ok && len(args) == 1 {
+		return polynomialRoots(info, p)
+	}
+	if err := checkArgsLength(info, args); err != nil {
+		return err
+	}
+	bounds, err := numberArgs(info, args, 1)
+	if err != nil {
+		return err
+	}
+	a, b := bounds[0], bounds[1]
+	if !(a < b) || !isFinite(a) || !isFinite(b) {
+		return newError("%q: a should be less than b and both finite, got %v and %v", info.name, a, b)
+	}
+
+	target, err := newSolveTarget(info, args[0])
+	if err != nil {
+		return err
+	}
+
+	var roots []float64
+	if degree := polynomial.Degree(target.coeffs); target.coeffs != nil && degree <= 3 {
+		if degree == -1 {
+			return newError("%q: every number is a solution", info.name)
+		}
+		roots = rootsInInterval(polynomial.RealRoots(target.coeffs), a, b)
+	} else {
+		f := newNumericFunction(info, target.fn)
+		roots = findRoots(f.call, a, b)
+		if f.err != nil {
+			return f.err
+		}
+	}
+
+	list := &object.List{Elements: make([]object.Object, len(roots))}
+	for i, root := range roots {
+		list.Elements[i] = newNumber(root)
+	}
+	return list
+}
+
+func firstPolynomial(args []object.Object) (*object.Polynomial, bool) {
+	if len(args) == 0 {
+		return nil, false
+	}
+	p, ok := args[0].(*object.Polynomial)
+	return p, ok
+}
+
+// numberArgs checks that the arguments from the offset are numbers and
+// returns their values.
+func numberArgs(info builtinFuncInfo, args []object.Object, offset int) ([]float64, *object.Error) {
+	var values []float64
+	for i := offset; i < len(args); i++ {
+		number, ok := args[i].(*object.Number)
+		if !ok {
+			return nil, newError(
+				"argument index %d of function %q should be type %s, got %s",
+				i, info.name, object.NUMBER_OBJ, args[i].Type(),
+			)
+		}
+		values = append(values, number.Value)
+	}
+	return values, nil
+}
+
+// solveTarget is the function whose roots are the solutions. coeffs are its
+// polynomial coefficients, nil if it is not a polynomial.
+type solveTarget struct {
+	fn     object.Object
+	coeffs []float64
+}
+
+// newSolveTarget returns the target of an equation `left := right` or
+// `left == right`, a function `x => left == right`, or any other function
+// f(x) = 0.
+func newSolveTarget(info builtinFuncInfo, obj object.Object) (solveTarget, *object.Error) {
+	switch obj := obj.(type) {
+	case *object.Equation:
+		unknowns := freeIdentifiers(obj.Env, obj.Left, obj.Right)
+		switch {
+		case len(unknowns) == 0:
+			return solveTarget{}, newError("%q: the equation has no unknown", info.name)
+		case len(unknowns) > 1:
+			return solveTarget{}, newError(
+				"%q: the equation has more than one unknown: %s",
+				info.name, strings.Join(unknowns, ", "),
+			)
+		}
+
+		x := &ast.Identifier{Token: token.Token{Type: token.IDENT, Literal: unknowns[0]}, Value: unknowns[0]}
+		fn := &object.ConciseFunction{
+			Parameters: []*ast.Parameter{{Name: x}},
+			Body:       difference(obj.Left, obj.Right),
+			Env:        obj.Env,
+		}
+		return solveTarget{fn: fn, coeffs: polynomialCoefficients(fn)}, nil
+
+	case *object.ConciseFunction:
+		if len(obj.Parameters) != 1 {
+			return solveTarget{}, newError(
+				"%q: function should have 1 parameter, got %d",
+				info.name, len(obj.Parameters),
+			)
+		}
+		if eq, ok := obj.Body.(*ast.InfixExpression); ok && eq.Operator == "==" {
+			obj = &object.ConciseFunction{
+				Parameters: obj.Parameters,
+				Body:       difference(eq.Left, eq.Right),
+				Env:        obj.Env,
+			}
+		}
+		return solveTarget{fn: obj, coeffs: polynomialCoefficients(obj)}, nil
+
+	case *object.Polynomial:
+		return solveTarget{fn: obj, coeffs: obj.Coeffs}, nil
+
+	default:
+		if !isFunction(obj) {
+			return solveTarget{}, newError(
+				"argument index 0 of function %q should be type %s or %s, got %s",
+				info.name, object.EQUATION_OBJ, object.FUNCTION_OBJ, obj.Type(),
+			)
+		}
+		return solveTarget{fn: obj}, nil
+	}
+}
+
+// equationArgument returns the first argument of a solve or roots call as an
+// equation if it is a `left == right` comparison with an unknown, e.g.
+// `solve(x^2 == 4, 1)`, which cannot be evaluated as a comparison.
+func equationArgument(node *ast.CallExpression, env *object.Environment) (*object.Equation, bool) {
+	ident, ok := node.Function.(*ast.Identifier)
+	if !ok || (ident.Value != "solve" && ident.Value != "roots") || len(node.Arguments) == 0 {
+		return nil, false
+	}
+	if _, shadowed := env.Get(ident.Value); shadowed {
+		return nil, false
+	}
+
+	eq, ok := node.Arguments[0].(*ast.InfixExpression)
+	if !ok || eq.Operator != "==" || len(freeIdentifiers(env, eq.Left, eq.Right)) == 0 {
+		return nil, false
+	}
+	return &object.Equation{Left: eq.Left, Right: eq.Right, Env: env}, true
+}
+
+func difference(left, right ast.Expression) ast.Expression {
+	return &ast.InfixExpression{
+		Token:    token.Token{Type: token.MINUS, Literal: "-"},
+		Operator: "-",
+		Left:     left,
+		Right:    right,
+	}
+}
+
+// polynomialCoefficients returns the coefficients of the function body if it
+// is a polynomial of its parameter, resolving the other identifiers in the
+// function environment.
+func polynomialCoefficients(fn *object.ConciseFunction) []float64 {
+	value := func(name string) (float64, bool) {
+		obj, ok := fn.Env.Get(name)
+		if !ok {
+			obj, ok = builtinValues[name]
+		}
+		if !ok {
+			return 0, false
+		}
+		number, ok := unwrapEstimate(obj).(*object.Number)
+		if !ok {
+			return 0, false
+		}
+		return number.Value, true
+	}
+
+	coeffs, ok := symbolic.Coefficients(fn.Body, fn.Parameters[0].Name.Value, value)
+	if !ok {
+		return nil
+	}
+	return coeffs
+}
+
+// freeIdentifiers returns the sorted identifiers of the expressions that are
+// not bound in the environment or builtin.
+func freeIdentifiers(env *object.Environment, exps ...ast.Expression) []string {
+	found := map[string]bool{}
+
+	var walk func(exp ast.Expression)
+	walk = func(exp ast.Expression) {
+		switch exp := exp.(type) {
+		case *ast.Identifier:
+			if !isBound(env, exp.Value) {
+				found[exp.Value] = true
+			}
+		case *ast.PrefixExpression:
+			walk(exp.Right)
+		case *ast.PostfixExpression:
+			walk(exp.Left)
+		case *ast.InfixExpression:
+			walk(exp.Left)
+			// the member of `e.message` is not a variable
+			if _, isMember := exp.Right.(*ast.Identifier); !isMember || exp.Operator != "." {
+				walk(exp.Right)
+			}
+		case *ast.CallExpression:
+			walk(exp.Function)
+			for _, arg := range exp.Arguments {
+				walk(arg)
+			}
+		case *ast.IndexExpression:
+			walk(exp.Left)
+			walk(exp.Index)
+		case *ast.NamedArgument:
+			walk(exp.Value)
+		}
+	}
+	for _, exp := range exps {
+		walk(exp)
+	}
+
+	names := make([]string, 0, len(found))
+	for name := range found {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// solveNear returns a root near the guess, using Newton's method and falling
+// back to Brent's method on an interval around the guess.
+func solveNear(info builtinFuncInfo, f *numericFunction, guess float64) (float64, *object.Error) {
+	if !isFinite(guess) {
+		return 0, newError("%q: the guess should be finite, got %v", info.name, guess)
+	}
+
+	if root, ok := newton(f.call, guess); ok {
+		return root, nil
+	}
+
+	a, b, ok := bracket(f.call, guess)
+	if !ok {
+		return 0, newError(
+			"%q: Newton's method did not converge from %v and no sign change was found around it, "+
+				"try another guess or an interval solve(f, a, b)",
+			info.name, guess,
+		)
+	}
+	return solveInterval(info, f, a, b)
+}
+
+// solveInterval returns a root from a to b, f(a) and f(b) should have
+// different signs.
+func solveInterval(info builtinFuncInfo, f *numericFunction, a, b float64) (float64, *object.Error) {
+	if !isFinite(a) || !isFinite(b) {
+		return 0, newError("%q: the interval should be finite, got %v and %v", info.name, a, b)
+	}
+
+	fa, fb := f.call(a), f.call(b)
+	if fa == 0 {
+		return a, nil
+	}
+	if fb == 0 {
+		return b, nil
+	}
+	if math.Signbit(fa) == math.Signbit(fb) || math.IsNaN(fa) || math.IsNaN(fb) {
+		return 0, newError(
+			"%q: f(a) and f(b) should have different signs, got f(%v) = %v and f(%v) = %v",
+			info.name, a, fa, b, fb,
+		)
+	}
+
+	root := brent(f.call, a, b, fa, fb)
+	if !isRoot(f.call, root, fa, fb) {
+		return 0, newError("%q: the sign of f changes at %v but it is a discontinuity, not a root", info.name, root)
+	}
+	return root, nil
+}
+
+// findRoots returns the sorted roots from a to b. The interval is sampled for
+// the sign changes, and for the local minima of |f| that touch zero.
+func findRoots(f func(float64) float64, a, b float64) []float64 {
+	const n = 1000
+
+	xs := make([]float64, n+1)
+	ys := make([]float64, n+1)
+	for i := range xs {
+		xs[i] = a + (b-a)*float64(i)/n
+		ys[i] = f(xs[i])
+	}
+
+	var roots []float64
+	for i := 0; i <= n; i++ {
+		switch {
+		case ys[i] == 0:
+			roots = append(roots, xs[i])
+		case i < n && ys[i+1] != 0 && isFinite(ys[i]) && isFinite(ys[i+1]) && math.Signbit(ys[i]) != math.Signbit(ys[i+1]):
+			if root := brent(f, xs[i], xs[i+1], ys[i], ys[i+1]); isRoot(f, root, ys[i], ys[i+1]) {
+				roots = append(roots, root)
+			}
+		case 0 < i && i < n && math.Abs(ys[i]) < math.Abs(ys[i-1]) && math.Abs(ys[i]) < math.Abs(ys[i+1]) &&
+			math.Signbit(ys[i-1]) == math.Signbit(ys[i+1]):
+			// a root of even multiplicity does not change the sign
+			if root, ok := newton(f, xs[i]); ok && xs[i-1] < root && root < xs[i+1] {
+				roots = append(roots, root)
+			}
+		}
+	}
+
+	sort.Float64s(roots)
+	unique := roots[:0]
+	for _, root := range roots {
+		if len(unique) == 0 || root-unique[len(unique)-1] > 1e-9*math.Max(1, math.Abs(root)) {
+			unique = append(unique, root)
+		}
+	}
+	return unique
+}
+
+// newton returns the root found by Newton's method from x0, the derivative is
+// the central difference. It reports false if it does not converge.
+func newton(f func(float64) float64, x0 float64) (float64, bool) {
+	const maxIterations = 100
+
+	x := x0
+	f0 := f(x0)
+	for i := 0; i < maxIterations; i++ {
+		fx := f(x)
+		if fx == 0 {
+			return x, true
+		}
+		if !isFinite(fx) {
+			return 0, false
+		}
+
+		h := 1e-6 * math.Max(1, math.Abs(x))
+		d := (f(x+h) - f(x-h)) / (2 * h)
+		if d == 0 || !isFinite(d) {
+			return 0, false
+		}
+
+		step := fx / d
+		x -= step
+		if math.Abs(step) <= 1e-14*math.Max(1, math.Abs(x)) {
+			fx = f(x)
+			return x, math.Abs(fx) <= 1e-9*math.Max(1, math.Abs(f0))
+		}
+	}
+
+	return 0, false
+}
+
+// bracket searches for a sign change around x0 with growing steps.
+func bracket(f func(float64) float64, x0 float64) (float64, float64, bool) {
+	const maxExpansions = 60
+
+	f0 := f(x0)
+	step := 0.1 * math.Max(1, math.Abs(x0))
+	for i := 0; i < maxExpansions; i++ {
+		if b := x0 + step; hasSignChange(f0, f(b)) {
+			return x0, b, true
+		}
+		if a := x0 - step; hasSignChange(f(a), f0) {
+			return a, x0, true
+		}
+		step *= 1.6
+	}
+	return 0, 0, false
+}
+
+func hasSignChange(fa, fb float64) bool {
+	return isFinite(fa) && isFinite(fb) && (fa == 0 || fb == 0 || math.Signbit(fa) != math.Signbit(fb))
+}
+
+// isRoot reports whether the result of brent is a root and not a
+// discontinuity, e.g. tan at pi/2, where f is large compared to fa and fb.
+func isRoot(f func(float64) float64, x, fa, fb float64) bool {
+	fx := f(x)
+	return isFinite(fx) && math.Abs(fx) <= 1e-6*math.Max(1, math.Min(math.Abs(fa), math.Abs(fb)))
+}
+
+// brent returns a root from a to b with Brent's method, f(a) = fa and f(b) = fb
+// should have different signs.
+func brent(f func(float64) float64, a, b, fa, fb float64) float64 {
+	const maxIterations = 200
+
+	if fa == 0 {
+		return a
+	}
+	if fb == 0 {
+		return b
+	}
+
+	c, fc := b, fb
+	var d, e float64
+	for i := 0; i < maxIterations; i++ {
+		if math.Signbit(fb) == math.Signbit(fc) {
+			c, fc = a, fa
+			d = b - a
+			e = d
+		}
+		if math.Abs(fc) < math.Abs(fb) {
+			a, b, c = b, c, b
+			fa, fb, fc = fb, fc, fb
+		}
+
+		tolerance := 2*epsilon*math.Abs(b) + 0.5e-15
+		middle := (c - b) / 2
+		if math.Abs(middle) <= tolerance || fb == 0 {
+			return b
+		}
+
+		if math.Abs(e) >= tolerance && math.Abs(fa) > math.Abs(fb) {
+			// inverse quadratic interpolation, or the secant method
+			var p, q float64
+			s := fb / fa
+			if a == c {
+				p = 2 * middle * s
+				q = 1 - s
+			} else {
+				q = fa / fc
+				r := fb / fc
+				p = s * (2*middle*q*(q-r) - (b-a)*(r-1))
+				q = (q - 1) * (r - 1) * (s - 1)
+			}
+			if p > 0 {
+				q = -q
+			}
+			p = math.Abs(p)
+
+			if 2*p < math.Min(3*middle*q-math.Abs(tolerance*q), math.Abs(e*q)) {
+				e = d
+				d = p / q
+			} else {
+				d = middle
+				e = d
+			}
+		} else {
+			// bisection
+			d = middle
+			e = d
+		}
+
+		a, fa = b, fb
+		if math.Abs(d) > tolerance {
+			b += d
+		} else {
+			b += math.Copysign(tolerance, middle)
+		}
+		fb = f(b)
+	}
+
+	return b
+}
+
+func rootsInInterval(roots []float64, a, b float64) []float64 {
+	var result []float64
+	for _, root := range roots {
+		if math.Min(a, b) <= root && root <= math.Max(a, b) {
+			result = append(result, root)
+		}
+	}
+	return result
+}
+
+func closestTo(values []float64, x float64) float64 {
+	closest := values[0]
+	for _, value := range values[1:] {
+		if math.Abs(value-x) < math.Abs(closest-x) {
+			closest = value
+		}
+	}
+	return closest
+}
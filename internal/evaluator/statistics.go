@@ -0,0 +1,150 @@
+package evaluator
+
+import (
+	"math"
+	"slices"
+
+	"github.com/DeepAung/qcal/internal/object"
+)
+
+// dataArgs returns the numbers of the arguments from the offset, or the
+// numbers of a single list argument, e.g. mean(1, 2, 3) or mean([1, 2, 3]).
+func dataArgs(info builtinFuncInfo, args []object.Object, offset int) ([]float64, *object.Error) {
+	if err := checkArgsLength(info, args); err != nil {
+		return nil, err
+	}
+	if len(args) == offset+1 {
+		if _, ok := args[offset].(*object.List); ok {
+			xs, err := vectorArg(info, args, offset)
+			if err != nil {
+				return nil, err
+			}
+			if len(xs) == 0 {
+				return nil, newError("%q: list should not be empty", info.name)
+			}
+			return xs, nil
+		}
+	}
+	return numberArgs(info, args, offset)
+}
+
+// pairedDataArgs returns the values of two lists of the same length, with at
+// least 2 values each.
+func pairedDataArgs(info builtinFuncInfo, args []object.Object) ([]float64, []float64, *object.Error) {
+	xs, ys, err := vectorPairArgs(info, args)
+	if err != nil {
+		return nil, nil, err
+	}
+	if len(xs) < 2 {
+		return nil, nil, newError("%q: expect at least 2 values, got=%d", info.name, len(xs))
+	}
+	return xs, ys, nil
+}
+
+// varianceBuiltin returns the variance with the delta degrees of freedom ddof,
+// 1 for a sample and 0 for a population, or its square root.
+func varianceBuiltin(info builtinFuncInfo, args []object.Object, ddof int, sqrt bool) object.Object {
+	xs, err := dataArgs(info, args, 0)
+	if err != nil {
+		return err
+	}
+	if len(xs) <= ddof {
+		return newError("%q: expect at least %d values, got=%d", info.name, ddof+1, len(xs))
+	}
+
+	v := variance(xs, ddof)
+	if sqrt {
+		return newNumber(math.Sqrt(v))
+	}
+	return newNumber(v)
+}
+
+// quantileBuiltin returns the quantile of the list, the second argument is
+// divided by scale, which is 100 for the percentiles.
+func quantileBuiltin(info builtinFuncInfo, args []object.Object, scale float64) object.Object {
+	if err := checkArgsLength(info, args); err != nil {
+		return err
+	}
+	xs, err := vectorArg(info, args, 0)
+	if err != nil {
+		return err
+	}
+	if len(xs) == 0 {
+		return newError("%q: list should not be empty", info.name)
+	}
+	p, ok := args[1].(*object.Number)
+	if !ok {
+		return newError(
+			"argument index 1 of function %q should be type %s, got %s",
+			info.name, object.NUMBER_OBJ, args[1].Type(),
+		)
+	}
+
+	q := p.Value / scale
+	if q < 0 || q > 1 {
+		return newError("%q: %s should be from 0 to %g, got %s", info.name, info.params[1], scale, p.Inspect())
+	}
+	return newNumber(quantile(sorted(xs), q))
+}
+
+func mean(xs []float64) float64 {
+	sum := 0.0
+	for _, x := range xs {
+		sum += x
+	}
+	return sum / float64(len(xs))
+}
+
+// variance returns the sum of the squared deviations divided by n - ddof.
+func variance(xs []float64, ddof int) float64 {
+	m := mean(xs)
+	sum := 0.0
+	for _, x := range xs {
+		sum += (x - m) * (x - m)
+	}
+	return sum / float64(len(xs)-ddof)
+}
+
+// covariance returns the sample covariance of xs and ys of the same length.
+func covariance(xs, ys []float64) float64 {
+	mx, my := mean(xs), mean(ys)
+	sum := 0.0
+	for i := range xs {
+		sum += (xs[i] - mx) * (ys[i] - my)
+	}
+	return sum / float64(len(xs)-1)
+}
+
+func sorted(xs []float64) []float64 {
+	s := slices.Clone(xs)
+	slices.Sort(s)
+	return s
+}
+
+// quantile returns the q-quantile of the sorted values, interpolating linearly
+// between the closest ranks.
+func quantile(sorted []float64, q float64) float64 {
+	h := q * float64(len(sorted)-1)
+	lo := math.Floor(h)
+	if int(lo)+1 >= len(sorted) {
+		return sorted[len(sorted)-1]
+	}
+	return sorted[int(lo)] + (h-lo)*(sorted[int(lo)+1]-sorted[int(lo)])
+}
+
+// mode returns the most frequent value, the smallest one of a tie.
+func mode(xs []float64) float64 {
+	s := sorted(xs)
+	result, best := s[0], 0
+	for i := 0; i < len(s); {
+		j := i
+		for j < len(s) && s[j] == s[i] {
+			j++
+		}
+		if j-i > best {
+			result, best = s[i], j-i
+		}
+		i = j
+	}
+	return result
+}
@@ -0,0 +1,79 @@
+package evaluator
+
+import (
+	"github.com/DeepAung/qcal/internal/ast"
+	"github.com/DeepAung/qcal/internal/object"
+)
+
+// evalTail evaluates the node in the tail position of a function body. A call
+// of a function there is returned as a tail call for applyFunction to run, the
+// tail positions are the last statement of a block, the value of a `return`
+// and the branches of an if expression in a tail position.
+func evalTail(node ast.Node, env *object.Environment) object.Object {
+	switch node := node.(type) {
+	case *ast.BlockStatement:
+		return evalTailBlockStatement(node, env)
+
+	case *ast.ExpressionStatement:
+		return evalTail(node.Expression, env)
+
+	case *ast.ReturnStatement:
+		val := evalTail(node.Value, env)
+		switch val.(type) {
+		case *object.Error, *object.TailCall:
+			return val
+		}
+		return &object.ReturnValue{Value: val}
+
+	case *ast.IfExpression:
+		condition := Eval(node.Condition, env)
+		if IsError(condition) {
+			return condition
+		}
+
+		if isTruthy(condition) {
+			return evalTail(node.Consequence, object.NewBlockEnvironment(env))
+		} else if node.Alternative != nil {
+			return evalTail(node.Alternative, object.NewBlockEnvironment(env))
+		} else {
+			return NULL
+		}
+
+	case *ast.CallExpression:
+		fn := Eval(node.Function, env)
+		if IsError(fn) {
+			return fn
+		}
+
+		args := evalExpressions(node.Arguments, env)
+		if len(args) == 1 && IsError(args[0]) {
+			return args[0]
+		}
+
+		switch fn.(type) {
+		case *object.NormalFunction, *object.ConciseFunction:
+			return &object.TailCall{Function: fn, Arguments: args}
+		}
+		return locateError(applyFunction(fn, args), node)
+	}
+
+	return Eval(node, env)
+}
+
+func evalTailBlockStatement(block *ast.BlockStatement, env *object.Environment) object.Object {
+	if len(block.Statements) == 0 {
+		return nil
+	}
+
+	last := len(block.Statements) - 1
+	for _, stmt := range block.Statements[:last] {
+		result := Eval(stmt, env)
+
+		switch result := result.(type) {
+		case *object.ReturnValue, *object.Error, *object.Break, *object.Continue:
+			return result
+		}
+	}
+
+	return evalTail(block.Statements[last], env)
+}
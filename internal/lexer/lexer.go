@@ -1,25 +1,39 @@
 package lexer
 
-import "github.com/DeepAung/qcal/internal/token"
+import (
+	"strings"
+
+	"github.com/DeepAung/qcal/internal/token"
+)
 
 type Lexer struct {
 	input        string
 	position     int  // current position pointing to current char
 	readPosition int  // current reading position (after current char)
 	ch           byte // current char
+	line         int  // line of the current char, from 1
+	lineStart    int  // position of the first char of the line
 }
 
 func New(input string) *Lexer {
-	l := &Lexer{input: input}
+	l := &Lexer{input: input, line: 1}
 	l.readChar()
 
 	return l
 }
 
 func (l *Lexer) NextToken() token.Token {
-	var tok token.Token
 	l.skipWhitespace()
 
+	line, column := l.line, l.position-l.lineStart+1
+	tok := l.readToken()
+	tok.Line, tok.Column = line, column
+	return tok
+}
+
+func (l *Lexer) readToken() token.Token {
+	var tok token.Token
+
 	switch l.ch {
 	case '=':
 		peekCh := l.peekChar()
@@ -35,13 +49,13 @@ func (l *Lexer) NextToken() token.Token {
 			tok = newToken(token.ASSIGN, l.ch)
 		}
 	case '+':
-		tok = newToken(token.PLUS, l.ch)
+		tok = l.newAssignToken(token.PLUS, token.PLUS_ASSIGN)
 	case '-':
-		tok = newToken(token.MINUS, l.ch)
+		tok = l.newAssignToken(token.MINUS, token.MINUS_ASSIGN)
 	case '*':
-		tok = newToken(token.ASTERISK, l.ch)
+		tok = l.newAssignToken(token.ASTERISK, token.ASTERISK_ASSIGN)
 	case '/':
-		tok = newToken(token.SLASH, l.ch)
+		tok = l.newAssignToken(token.SLASH, token.SLASH_ASSIGN)
 	case '%':
 		tok = newToken(token.PERCENT, l.ch)
 	case '^':
@@ -70,6 +84,22 @@ func (l *Lexer) NextToken() token.Token {
 		} else {
 			tok = newToken(token.GT, l.ch)
 		}
+	case ':':
+		if l.peekChar() == '=' {
+			l.readChar()
+			tok.Literal = ":="
+			tok.Type = token.EQUATION
+		} else {
+			tok = newToken(token.ILLEGAL, l.ch)
+		}
+	case '|':
+		if l.peekChar() == '>' {
+			l.readChar()
+			tok.Literal = "|>"
+			tok.Type = token.PIPE
+		} else {
+			tok = newToken(token.ILLEGAL, l.ch)
+		}
 	case ',':
 		tok = newToken(token.COMMA, l.ch)
 	case ';':
@@ -82,12 +112,40 @@ func (l *Lexer) NextToken() token.Token {
 		tok = newToken(token.LBRACE, l.ch)
 	case '}':
 		tok = newToken(token.RBRACE, l.ch)
+	case '[':
+		tok = newToken(token.LBRACKET, l.ch)
+	case ']':
+		tok = newToken(token.RBRACKET, l.ch)
 	case '.':
+		if strings.HasPrefix(l.input[l.position:], "...") {
+			l.readChar()
+			l.readChar()
+			tok.Literal = "..."
+			tok.Type = token.ELLIPSIS
+		} else if isDigit(l.peekChar()) {
+			tok.Literal = l.readNumber()
+			tok.Type = token.NUMBER
+			return tok
+		} else if l.isSpaced() {
+			tok = newToken(token.COMPOSE, l.ch)
+		} else {
+			tok = newToken(token.DOT, l.ch)
+		}
+	case '"':
+		literal, ok := l.readString()
+		if !ok {
+			tok.Literal = literal
+			tok.Type = token.ILLEGAL
+			return tok
+		}
+		tok.Literal = literal
+		tok.Type = token.STRING
+	case '$':
 		if !isDigit(l.peekChar()) {
 			tok = newToken(token.ILLEGAL, l.ch)
 		} else {
-			tok.Literal = l.readNumber()
-			tok.Type = token.NUMBER
+			tok.Literal = l.readRef()
+			tok.Type = token.REF
 			return tok
 		}
 	case 0:
@@ -102,6 +160,11 @@ func (l *Lexer) NextToken() token.Token {
 			tok.Literal = l.readNumber()
 			tok.Type = token.NUMBER
 			return tok
+		} else if strings.HasPrefix(l.input[l.position:], "∘") {
+			l.readChar()
+			l.readChar()
+			tok.Literal = "∘"
+			tok.Type = token.COMPOSE
 		} else {
 			tok = newToken(token.ILLEGAL, l.ch)
 		}
@@ -111,13 +174,57 @@ func (l *Lexer) NextToken() token.Token {
 	return tok
 }
 
-func (l *Lexer) skipWhitespace() {
-	for l.ch == ' ' || l.ch == '\t' || l.ch == '\n' || l.ch == '\r' {
+// newAssignToken returns the token of the operator, or of its compound
+// assignment if the operator is followed by `=`, e.g. `+=`.
+func (l *Lexer) newAssignToken(operator, assign token.TokenType) token.Token {
+	if l.peekChar() == '=' {
+		ch := l.ch
 		l.readChar()
+		return token.Token{Type: assign, Literal: string(ch) + "="}
 	}
+	return newToken(operator, l.ch)
+}
+
+// skipWhitespace skips the whitespace and the comments, `#` and `//` line
+// comments and `/* */` block comments. An unterminated block comment ends at
+// the end of the input.
+func (l *Lexer) skipWhitespace() {
+	for {
+		switch {
+		case isWhitespace(l.ch):
+			l.readChar()
+		case l.ch == '#' || l.ch == '/' && l.peekChar() == '/':
+			for l.ch != '\n' && l.ch != 0 {
+				l.readChar()
+			}
+		case l.ch == '/' && l.peekChar() == '*':
+			l.readChar()
+			l.readChar()
+			for !(l.ch == '*' && l.peekChar() == '/') && l.ch != 0 {
+				l.readChar()
+			}
+			if l.ch != 0 {
+				l.readChar()
+				l.readChar()
+			}
+		default:
+			return
+		}
+	}
+}
+
+// isSpaced reports whether the current char is between whitespace, a `.`
+// between spaces is the composition `f . g`, e.g. of `lib.f . lib.g`.
+func (l *Lexer) isSpaced() bool {
+	return l.position > 0 && isWhitespace(l.input[l.position-1]) && isWhitespace(l.peekChar())
 }
 
 func (l *Lexer) readChar() {
+	if l.ch == '\n' {
+		l.line++
+		l.lineStart = l.readPosition
+	}
+
 	if l.readPosition < len(l.input) {
 		l.ch = l.input[l.readPosition]
 	} else {
@@ -162,6 +269,47 @@ func (l *Lexer) readNumber() string {
 	return l.input[position:l.position]
 }
 
+// readString reads a double quoted string and returns its unescaped content,
+// e.g. `"a \"b\""` returns `a "b"`. It returns false if the string is not terminated.
+func (l *Lexer) readString() (string, bool) {
+	var sb strings.Builder
+	position := l.position
+
+	for {
+		l.readChar()
+		switch l.ch {
+		case '"':
+			return sb.String(), true
+		case 0:
+			return l.input[position:l.position], false
+		case '\\':
+			l.readChar()
+			switch l.ch {
+			case 'n':
+				sb.WriteByte('\n')
+			case 't':
+				sb.WriteByte('\t')
+			case 0:
+				return l.input[position:l.position], false
+			default:
+				sb.WriteByte(l.ch)
+			}
+		default:
+			sb.WriteByte(l.ch)
+		}
+	}
+}
+
+// e.g. "$1", "$20"
+func (l *Lexer) readRef() string {
+	position := l.position
+	l.readChar()
+	for isDigit(l.ch) {
+		l.readChar()
+	}
+	return l.input[position:l.position]
+}
+
 func newToken(tokenType token.TokenType, ch byte) token.Token {
 	return token.Token{Type: tokenType, Literal: string(ch)}
 }
@@ -170,6 +318,10 @@ func isLetter(ch byte) bool {
 	return ('a' <= ch && ch <= 'z') || ('A' <= ch && ch <= 'Z') || (ch == '_')
 }
 
+func isWhitespace(ch byte) bool {
+	return ch == ' ' || ch == '\t' || ch == '\n' || ch == '\r'
+}
+
 func isDigit(ch byte) bool {
 	return '0' <= ch && ch <= '9'
 }
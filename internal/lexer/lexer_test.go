@@ -18,7 +18,15 @@ f = (a, b) => a + b;
 f(e, pi)
 x = if (1 < 2 or false and true) { !false } else { true }
 < <= > >= == !=
-return
+x^2 := 4 :
+x |> f | g
+[1, [2]]
+return const let
+x += 1 -= *= /=
+while for in break continue try catch import
+(...xs) .. . f ∘ g
+ans + $1 * $20 $
+"plot.svg" "a \"b\" \\ \n" "unterminated
 `
 	expects := []token.Token{
 		{Type: token.IDENT, Literal: "x"},
@@ -34,7 +42,7 @@ return
 		{Type: token.SEMICOLON, Literal: ";"},
 		{Type: token.IDENT, Literal: "y"},
 		{Type: token.ASSIGN, Literal: "="},
-		{Type: token.ILLEGAL, Literal: "."},
+		{Type: token.DOT, Literal: "."},
 		{Type: token.SEMICOLON, Literal: ";"},
 		{Type: token.IDENT, Literal: "y"},
 		{Type: token.ASSIGN, Literal: "="},
@@ -98,7 +106,60 @@ return
 		{Type: token.GT_EQ, Literal: ">="},
 		{Type: token.EQ, Literal: "=="},
 		{Type: token.NOT_EQ, Literal: "!="},
+		{Type: token.IDENT, Literal: "x"},
+		{Type: token.CARET, Literal: "^"},
+		{Type: token.NUMBER, Literal: "2"},
+		{Type: token.EQUATION, Literal: ":="},
+		{Type: token.NUMBER, Literal: "4"},
+		{Type: token.ILLEGAL, Literal: ":"},
+		{Type: token.IDENT, Literal: "x"},
+		{Type: token.PIPE, Literal: "|>"},
+		{Type: token.IDENT, Literal: "f"},
+		{Type: token.ILLEGAL, Literal: "|"},
+		{Type: token.IDENT, Literal: "g"},
+		{Type: token.LBRACKET, Literal: "["},
+		{Type: token.NUMBER, Literal: "1"},
+		{Type: token.COMMA, Literal: ","},
+		{Type: token.LBRACKET, Literal: "["},
+		{Type: token.NUMBER, Literal: "2"},
+		{Type: token.RBRACKET, Literal: "]"},
+		{Type: token.RBRACKET, Literal: "]"},
 		{Type: token.RETURN, Literal: "return"},
+		{Type: token.CONST, Literal: "const"},
+		{Type: token.LET, Literal: "let"},
+		{Type: token.IDENT, Literal: "x"},
+		{Type: token.PLUS_ASSIGN, Literal: "+="},
+		{Type: token.NUMBER, Literal: "1"},
+		{Type: token.MINUS_ASSIGN, Literal: "-="},
+		{Type: token.ASTERISK_ASSIGN, Literal: "*="},
+		{Type: token.SLASH_ASSIGN, Literal: "/="},
+		{Type: token.WHILE, Literal: "while"},
+		{Type: token.FOR, Literal: "for"},
+		{Type: token.IN, Literal: "in"},
+		{Type: token.BREAK, Literal: "break"},
+		{Type: token.CONTINUE, Literal: "continue"},
+		{Type: token.TRY, Literal: "try"},
+		{Type: token.CATCH, Literal: "catch"},
+		{Type: token.IMPORT, Literal: "import"},
+		{Type: token.LPAREN, Literal: "("},
+		{Type: token.ELLIPSIS, Literal: "..."},
+		{Type: token.IDENT, Literal: "xs"},
+		{Type: token.RPAREN, Literal: ")"},
+		{Type: token.DOT, Literal: "."},
+		{Type: token.DOT, Literal: "."},
+		{Type: token.COMPOSE, Literal: "."},
+		{Type: token.IDENT, Literal: "f"},
+		{Type: token.COMPOSE, Literal: "∘"},
+		{Type: token.IDENT, Literal: "g"},
+		{Type: token.IDENT, Literal: "ans"},
+		{Type: token.PLUS, Literal: "+"},
+		{Type: token.REF, Literal: "$1"},
+		{Type: token.ASTERISK, Literal: "*"},
+		{Type: token.REF, Literal: "$20"},
+		{Type: token.ILLEGAL, Literal: "$"},
+		{Type: token.STRING, Literal: "plot.svg"},
+		{Type: token.STRING, Literal: "a \"b\" \\ \n"},
+		{Type: token.ILLEGAL, Literal: "\"unterminated\n"},
 		{Type: token.EOF, Literal: ""},
 	}
 
@@ -119,3 +180,61 @@ return
 		}
 	}
 }
+
+func TestComments(t *testing.T) {
+	input := `# a line comment
+x = 1 // the rest of the line
+/* a block
+   comment */ y /= x /**/ /* ** */
+z # last`
+	expects := []token.Token{
+		{Type: token.IDENT, Literal: "x", Line: 2, Column: 1},
+		{Type: token.ASSIGN, Literal: "=", Line: 2, Column: 3},
+		{Type: token.NUMBER, Literal: "1", Line: 2, Column: 5},
+		{Type: token.IDENT, Literal: "y", Line: 4, Column: 15},
+		{Type: token.SLASH_ASSIGN, Literal: "/=", Line: 4, Column: 17},
+		{Type: token.IDENT, Literal: "x", Line: 4, Column: 20},
+		{Type: token.IDENT, Literal: "z", Line: 5, Column: 1},
+		{Type: token.EOF, Literal: "", Line: 5, Column: 9},
+	}
+
+	l := New(input)
+	for i, expect := range expects {
+		tok := l.NextToken()
+		if tok != expect {
+			t.Fatalf("expects[%d] - invalid token, expect=%+v, got=%+v", i, expect, tok)
+		}
+	}
+
+	l = New("x /* unterminated")
+	for _, expect := range []token.TokenType{token.IDENT, token.EOF} {
+		if tok := l.NextToken(); tok.Type != expect {
+			t.Fatalf("unterminated comment - invalid token type, expect=%q, got=%q", expect, tok.Type)
+		}
+	}
+}
+
+func TestTokenPosition(t *testing.T) {
+	input := "x = 1\n  ln(-x)\n\"a\nb\" y"
+	expects := []token.Token{
+		{Type: token.IDENT, Literal: "x", Line: 1, Column: 1},
+		{Type: token.ASSIGN, Literal: "=", Line: 1, Column: 3},
+		{Type: token.NUMBER, Literal: "1", Line: 1, Column: 5},
+		{Type: token.IDENT, Literal: "ln", Line: 2, Column: 3},
+		{Type: token.LPAREN, Literal: "(", Line: 2, Column: 5},
+		{Type: token.MINUS, Literal: "-", Line: 2, Column: 6},
+		{Type: token.IDENT, Literal: "x", Line: 2, Column: 7},
+		{Type: token.RPAREN, Literal: ")", Line: 2, Column: 8},
+		{Type: token.STRING, Literal: "a\nb", Line: 3, Column: 1},
+		{Type: token.IDENT, Literal: "y", Line: 4, Column: 4},
+		{Type: token.EOF, Literal: "", Line: 4, Column: 5},
+	}
+
+	l := New(input)
+	for i, expect := range expects {
+		tok := l.NextToken()
+		if tok != expect {
+			t.Fatalf("expects[%d] - invalid token, expect=%+v, got=%+v", i, expect, tok)
+		}
+	}
+}
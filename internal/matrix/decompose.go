@@ -0,0 +1,205 @@
+package matrix
+
+import (
+	"errors"
+	"fmt"
+	"math"
+)
+
+// LU returns the decomposition PA = LU of the square matrix, L is unit lower
+// triangular, U is upper triangular and P is a permutation matrix.
+func LU(a [][]float64) (l, u, p [][]float64, err error) {
+	if !IsSquare(a) {
+		return nil, nil, nil, fmt.Errorf("matrix should be square, got %s", SizeString(a))
+	}
+
+	l, u, perm, _ := decompose(a)
+	p = New(len(a), len(a))
+	for i, j := range perm {
+		p[i][j] = 1
+	}
+	return l, u, p, nil
+}
+
+// decompose returns the LU decomposition with partial pivoting, the row i of
+// LU is the row perm[i] of a, sign is the sign of the permutation.
+func decompose(a [][]float64) (l, u [][]float64, perm []int, sign float64) {
+	n := len(a)
+	u = Copy(a)
+	l = Identity(n)
+	perm = make([]int, n)
+	for i := range perm {
+		perm[i] = i
+	}
+	sign = 1
+
+	for k := 0; k < n; k++ {
+		pivot := k
+		for i := k + 1; i < n; i++ {
+			if math.Abs(u[i][k]) > math.Abs(u[pivot][k]) {
+				pivot = i
+			}
+		}
+		if pivot != k {
+			u[k], u[pivot] = u[pivot], u[k]
+			perm[k], perm[pivot] = perm[pivot], perm[k]
+			for j := 0; j < k; j++ {
+				l[k][j], l[pivot][j] = l[pivot][j], l[k][j]
+			}
+			sign = -sign
+		}
+		if u[k][k] == 0 {
+			continue
+		}
+
+		for i := k + 1; i < n; i++ {
+			factor := u[i][k] / u[k][k]
+			l[i][k] = factor
+			for j := k; j < n; j++ {
+				u[i][j] -= factor * u[k][j]
+			}
+			u[i][k] = 0
+		}
+	}
+
+	return l, u, perm, sign
+}
+
+// QR returns the decomposition A = QR of the m x n matrix, using Householder
+// reflections. Q is m x m orthogonal and R is m x n upper triangular.
+func QR(a [][]float64) (q, r [][]float64) {
+	m, n := Size(a)
+	q = Identity(m)
+	r = Copy(a)
+
+	for k := 0; k < min(m-1, n); k++ {
+		// v = x - alpha e1 reflects x onto alpha e1
+		norm := 0.0
+		for i := k; i < m; i++ {
+			norm += r[i][k] * r[i][k]
+		}
+		norm = math.Sqrt(norm)
+		if norm == 0 {
+			continue
+		}
+		alpha := -math.Copysign(norm, r[k][k])
+
+		v := make([]float64, m)
+		for i := k; i < m; i++ {
+			v[i] = r[i][k]
+		}
+		v[k] -= alpha
+		vNorm := 0.0
+		for i := k; i < m; i++ {
+			vNorm += v[i] * v[i]
+		}
+		if vNorm == 0 {
+			continue
+		}
+
+		// R = HR and Q = QH with H = I - 2vv^T / v^Tv
+		for j := 0; j < n; j++ {
+			dot := 0.0
+			for i := k; i < m; i++ {
+				dot += v[i] * r[i][j]
+			}
+			for i := k; i < m; i++ {
+				r[i][j] -= 2 * dot / vNorm * v[i]
+			}
+		}
+		for i := 0; i < m; i++ {
+			dot := 0.0
+			for j := k; j < m; j++ {
+				dot += q[i][j] * v[j]
+			}
+			for j := k; j < m; j++ {
+				q[i][j] -= 2 * dot / vNorm * v[j]
+			}
+		}
+
+		for i := k + 1; i < m; i++ {
+			r[i][k] = 0
+		}
+	}
+
+	return q, r
+}
+
+// Solve returns x of ax = b, b can have many columns. A square matrix is
+// solved by the LU decomposition, a matrix with more rows than columns by the
+// least squares with the QR decomposition.
+func Solve(a, b [][]float64) ([][]float64, error) {
+	m, n := Size(a)
+	if br, _ := Size(b); br != m {
+		return nil, fmt.Errorf("dimension mismatch: %s and %s", SizeString(a), SizeString(b))
+	}
+
+	switch {
+	case m == n:
+		return solveLU(a, b)
+	case m > n:
+		return solveLeastSquares(a, b)
+	default:
+		return nil, fmt.Errorf("system is underdetermined, %s has more columns than rows", SizeString(a))
+	}
+}
+
+func solveLU(a, b [][]float64) ([][]float64, error) {
+	n := len(a)
+	l, u, perm, _ := decompose(a)
+
+	tolerance := float64(n) * epsilon * maxAbs(a)
+	for i := range u {
+		if math.Abs(u[i][i]) <= tolerance {
+			return nil, ErrSingular
+		}
+	}
+
+	_, c := Size(b)
+	x := New(n, c)
+	for col := 0; col < c; col++ {
+		// Ly = Pb, then Ux = y
+		y := make([]float64, n)
+		for i := 0; i < n; i++ {
+			y[i] = b[perm[i]][col]
+			for j := 0; j < i; j++ {
+				y[i] -= l[i][j] * y[j]
+			}
+		}
+		for i := n - 1; i >= 0; i-- {
+			sum := y[i]
+			for j := i + 1; j < n; j++ {
+				sum -= u[i][j] * x[j][col]
+			}
+			x[i][col] = sum / u[i][i]
+		}
+	}
+	return x, nil
+}
+
+func solveLeastSquares(a, b [][]float64) ([][]float64, error) {
+	_, n := Size(a)
+	q, r := QR(a)
+
+	// Rx = Q^T b for the first n rows
+	qtb, _ := Mul(Transpose(q), b)
+	tolerance := float64(len(a)) * epsilon * maxAbs(a)
+	for i := 0; i < n; i++ {
+		if math.Abs(r[i][i]) <= tolerance {
+			return nil, errors.New("matrix does not have full column rank")
+		}
+	}
+
+	_, c := Size(b)
+	x := New(n, c)
+	for col := 0; col < c; col++ {
+		for i := n - 1; i >= 0; i-- {
+			sum := qtb[i][col]
+			for j := i + 1; j < n; j++ {
+				sum -= r[i][j] * x[j][col]
+			}
+			x[i][col] = sum / r[i][i]
+		}
+	}
+	return x, nil
+}
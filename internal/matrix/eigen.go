@@ -0,0 +1,200 @@
+package matrix
+
+import (
+	"cmp"
+	"errors"
+	"fmt"
+	"math"
+	"math/cmplx"
+	"slices"
+)
+
+// Eigenvalues returns the eigenvalues of the square matrix, sorted by the real
+// part, then the imaginary part. The matrix is reduced to the Hessenberg form,
+// then the shifted QR algorithm converges to its Schur form.
+func Eigenvalues(a [][]float64) ([]complex128, error) {
+	if !IsSquare(a) {
+		return nil, fmt.Errorf("matrix should be square, got %s", SizeString(a))
+	}
+
+	n := len(a)
+	h := make([][]complex128, n)
+	for i, row := range hessenberg(a) {
+		h[i] = make([]complex128, n)
+		for j, v := range row {
+			h[i][j] = complex(v, 0)
+		}
+	}
+
+	values := make([]complex128, 0, n)
+	maxIterations := 30 * max(n, 1)
+	iterations := 0
+	for hi := n - 1; hi >= 0; {
+		if hi == 0 {
+			values = append(values, h[0][0])
+			break
+		}
+
+		// deflate at a negligible subdiagonal entry
+		lo := hi
+		for lo > 0 {
+			scale := cmplx.Abs(h[lo][lo]) + cmplx.Abs(h[lo-1][lo-1])
+			if cmplx.Abs(h[lo][lo-1]) <= epsilon*scale {
+				h[lo][lo-1] = 0
+				break
+			}
+			lo--
+		}
+		if lo == hi {
+			values = append(values, h[hi][hi])
+			hi--
+			iterations = 0
+			continue
+		}
+
+		iterations++
+		if iterations > maxIterations {
+			return nil, errors.New("eigenvalues did not converge")
+		}
+
+		shift := wilkinsonShift(h[hi-1][hi-1], h[hi-1][hi], h[hi][hi-1], h[hi][hi])
+		if iterations%10 == 0 {
+			// an exceptional shift breaks the cycles
+			shift = h[hi][hi] + complex(math.Abs(real(h[hi][hi-1])), 0)
+		}
+		qrStep(h, lo, hi, shift)
+	}
+
+	// the eigenvalues of real matrices close to the real axis are real
+	norm := Norm(a)
+	for i, v := range values {
+		if math.Abs(imag(v)) <= 1e-10*math.Max(1, norm) {
+			values[i] = complex(real(v), 0)
+		}
+	}
+
+	slices.SortFunc(values, func(a, b complex128) int {
+		if c := cmp.Compare(real(a), real(b)); c != 0 {
+			return c
+		}
+		return cmp.Compare(imag(a), imag(b))
+	})
+	return values, nil
+}
+
+// hessenberg returns the upper Hessenberg matrix similar to a, using
+// Householder reflections.
+func hessenberg(a [][]float64) [][]float64 {
+	h := Copy(a)
+	n := len(h)
+
+	for k := 0; k < n-2; k++ {
+		norm := 0.0
+		for i := k + 1; i < n; i++ {
+			norm += h[i][k] * h[i][k]
+		}
+		norm = math.Sqrt(norm)
+		if norm == 0 {
+			continue
+		}
+		alpha := -math.Copysign(norm, h[k+1][k])
+
+		v := make([]float64, n)
+		for i := k + 1; i < n; i++ {
+			v[i] = h[i][k]
+		}
+		v[k+1] -= alpha
+		vNorm := 0.0
+		for i := k + 1; i < n; i++ {
+			vNorm += v[i] * v[i]
+		}
+		if vNorm == 0 {
+			continue
+		}
+
+		// H = PHP with P = I - 2vv^T / v^Tv
+		for j := 0; j < n; j++ {
+			dot := 0.0
+			for i := k + 1; i < n; i++ {
+				dot += v[i] * h[i][j]
+			}
+			for i := k + 1; i < n; i++ {
+				h[i][j] -= 2 * dot / vNorm * v[i]
+			}
+		}
+		for i := 0; i < n; i++ {
+			dot := 0.0
+			for j := k + 1; j < n; j++ {
+				dot += h[i][j] * v[j]
+			}
+			for j := k + 1; j < n; j++ {
+				h[i][j] -= 2 * dot / vNorm * v[j]
+			}
+		}
+	}
+
+	return h
+}
+
+// wilkinsonShift returns the eigenvalue of [[a, b], [c, d]] closer to d.
+func wilkinsonShift(a, b, c, d complex128) complex128 {
+	trace, det := a+d, a*d-b*c
+	root := cmplx.Sqrt(trace*trace/4 - det)
+	first, second := trace/2+root, trace/2-root
+	if cmplx.Abs(first-d) < cmplx.Abs(second-d) {
+		return first
+	}
+	return second
+}
+
+// qrStep replaces the block lo..hi of h by RQ + shift I, where QR = h - shift I,
+// using Givens rotations.
+func qrStep(h [][]complex128, lo, hi int, shift complex128) {
+	for i := lo; i <= hi; i++ {
+		h[i][i] -= shift
+	}
+
+	type rotation struct {
+		c float64
+		s complex128
+	}
+	rotations := make([]rotation, 0, hi-lo)
+
+	// QR: the rotation G = [[c, s], [-conj(s), c]] zeroes h[i+1][i]
+	for i := lo; i < hi; i++ {
+		x, y := h[i][i], h[i+1][i]
+		r := math.Hypot(cmplx.Abs(x), cmplx.Abs(y))
+		var c float64
+		var s complex128
+		switch {
+		case r == 0:
+			c, s = 1, 0
+		case x == 0:
+			c, s = 0, cmplx.Conj(y)/complex(cmplx.Abs(y), 0)
+		default:
+			c = cmplx.Abs(x) / r
+			s = x / complex(cmplx.Abs(x), 0) * cmplx.Conj(y) / complex(r, 0)
+		}
+		rotations = append(rotations, rotation{c, s})
+
+		for j := i; j <= hi; j++ {
+			top, bottom := h[i][j], h[i+1][j]
+			h[i][j] = complex(c, 0)*top + s*bottom
+			h[i+1][j] = -cmplx.Conj(s)*top + complex(c, 0)*bottom
+		}
+	}
+
+	// RQ: the columns are multiplied by G^H = [[c, -s], [conj(s), c]]
+	for k, rot := range rotations {
+		i := lo + k
+		for j := lo; j <= min(i+1, hi); j++ {
+			left, right := h[j][i], h[j][i+1]
+			h[j][i] = left*complex(rot.c, 0) + right*cmplx.Conj(rot.s)
+			h[j][i+1] = -left*rot.s + right*complex(rot.c, 0)
+		}
+	}
+
+	for i := lo; i <= hi; i++ {
+		h[i][i] += shift
+	}
+}
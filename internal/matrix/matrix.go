@@ -0,0 +1,218 @@
+// Package matrix implements the linear algebra of real matrices. A matrix is
+// its rows, all of the same length.
+package matrix
+
+import (
+	"errors"
+	"fmt"
+	"math"
+)
+
+const epsilon = 0x1p-52
+
+var ErrSingular = errors.New("matrix is singular")
+
+// New returns the zero matrix of r rows and c columns.
+func New(r, c int) [][]float64 {
+	m := make([][]float64, r)
+	for i := range m {
+		m[i] = make([]float64, c)
+	}
+	return m
+}
+
+func Identity(n int) [][]float64 {
+	m := New(n, n)
+	for i := range m {
+		m[i][i] = 1
+	}
+	return m
+}
+
+func Copy(a [][]float64) [][]float64 {
+	m := make([][]float64, len(a))
+	for i, row := range a {
+		m[i] = append([]float64{}, row...)
+	}
+	return m
+}
+
+// Size returns the number of rows and columns.
+func Size(a [][]float64) (int, int) {
+	if len(a) == 0 {
+		return 0, 0
+	}
+	return len(a), len(a[0])
+}
+
+// SizeString returns the size as `rows x columns`, e.g. `2x3`.
+func SizeString(a [][]float64) string {
+	r, c := Size(a)
+	return fmt.Sprintf("%dx%d", r, c)
+}
+
+func IsSquare(a [][]float64) bool {
+	r, c := Size(a)
+	return r == c
+}
+
+func Add(a, b [][]float64) ([][]float64, error) {
+	return elementwise(a, b, func(x, y float64) float64 { return x + y })
+}
+
+func Sub(a, b [][]float64) ([][]float64, error) {
+	return elementwise(a, b, func(x, y float64) float64 { return x - y })
+}
+
+func elementwise(a, b [][]float64, op func(x, y float64) float64) ([][]float64, error) {
+	ar, ac := Size(a)
+	br, bc := Size(b)
+	if ar != br || ac != bc {
+		return nil, fmt.Errorf("dimension mismatch: %s and %s", SizeString(a), SizeString(b))
+	}
+
+	m := New(ar, ac)
+	for i := range m {
+		for j := range m[i] {
+			m[i][j] = op(a[i][j], b[i][j])
+		}
+	}
+	return m, nil
+}
+
+func Scale(a [][]float64, factor float64) [][]float64 {
+	m := Copy(a)
+	for _, row := range m {
+		for j := range row {
+			row[j] *= factor
+		}
+	}
+	return m
+}
+
+// Mul returns the matrix product ab.
+func Mul(a, b [][]float64) ([][]float64, error) {
+	ar, ac := Size(a)
+	br, bc := Size(b)
+	if ac != br {
+		return nil, fmt.Errorf("dimension mismatch: %s * %s", SizeString(a), SizeString(b))
+	}
+
+	m := New(ar, bc)
+	for i := 0; i < ar; i++ {
+		for k := 0; k < ac; k++ {
+			for j := 0; j < bc; j++ {
+				m[i][j] += a[i][k] * b[k][j]
+			}
+		}
+	}
+	return m, nil
+}
+
+// Pow returns a^n, the negative powers are the powers of the inverse.
+func Pow(a [][]float64, n int) ([][]float64, error) {
+	if !IsSquare(a) {
+		return nil, fmt.Errorf("matrix should be square, got %s", SizeString(a))
+	}
+	if n < 0 {
+		inv, err := Inverse(a)
+		if err != nil {
+			return nil, err
+		}
+		a, n = inv, -n
+	}
+
+	result := Identity(len(a))
+	for ; n > 0; n /= 2 {
+		if n%2 == 1 {
+			result, _ = Mul(result, a)
+		}
+		a, _ = Mul(a, a)
+	}
+	return result, nil
+}
+
+func Transpose(a [][]float64) [][]float64 {
+	r, c := Size(a)
+	m := New(c, r)
+	for i := 0; i < r; i++ {
+		for j := 0; j < c; j++ {
+			m[j][i] = a[i][j]
+		}
+	}
+	return m
+}
+
+// Det returns the determinant, using the LU decomposition.
+func Det(a [][]float64) (float64, error) {
+	if !IsSquare(a) {
+		return 0, fmt.Errorf("matrix should be square, got %s", SizeString(a))
+	}
+
+	_, u, _, sign := decompose(a)
+	det := sign
+	for i := range u {
+		det *= u[i][i]
+	}
+	return det, nil
+}
+
+// Inverse returns the inverse of the square matrix.
+func Inverse(a [][]float64) ([][]float64, error) {
+	if !IsSquare(a) {
+		return nil, fmt.Errorf("matrix should be square, got %s", SizeString(a))
+	}
+	return Solve(a, Identity(len(a)))
+}
+
+// Rank returns the number of linearly independent rows, using Gaussian
+// elimination with a tolerance for the rounding errors.
+func Rank(a [][]float64) int {
+	m := Copy(a)
+	r, c := Size(m)
+	tolerance := float64(max(r, c)) * epsilon * maxAbs(m)
+
+	rank := 0
+	for col := 0; col < c && rank < r; col++ {
+		pivot := rank
+		for i := rank + 1; i < r; i++ {
+			if math.Abs(m[i][col]) > math.Abs(m[pivot][col]) {
+				pivot = i
+			}
+		}
+		if math.Abs(m[pivot][col]) <= tolerance {
+			continue
+		}
+
+		m[rank], m[pivot] = m[pivot], m[rank]
+		for i := rank + 1; i < r; i++ {
+			factor := m[i][col] / m[rank][col]
+			for j := col; j < c; j++ {
+				m[i][j] -= factor * m[rank][j]
+			}
+		}
+		rank++
+	}
+	return rank
+}
+
+// Norm returns the Frobenius norm, the Euclidean norm of a vector.
+func Norm(a [][]float64) float64 {
+	sum := 0.0
+	for _, row := range a {
+		for _, v := range row {
+			sum += v * v
+		}
+	}
+	return math.Sqrt(sum)
+}
+
+func maxAbs(a [][]float64) float64 {
+	result := 0.0
+	for _, row := range a {
+		for _, v := range row {
+			result = math.Max(result, math.Abs(v))
+		}
+	}
+	return result
+}
@@ -0,0 +1,163 @@
+package matrix
+
+import (
+	"math"
+	"math/cmplx"
+	"testing"
+)
+
+func TestArithmetic(t *testing.T) {
+	a := [][]float64{{1, 2}, {3, 4}}
+	b := [][]float64{{0, 1}, {1, 0}}
+
+	sum, _ := Add(a, b)
+	expectEqual(t, "add", sum, [][]float64{{1, 3}, {4, 4}})
+	product, _ := Mul(a, b)
+	expectEqual(t, "mul", product, [][]float64{{2, 1}, {4, 3}})
+	power, _ := Pow(a, 3)
+	expectEqual(t, "pow", power, [][]float64{{37, 54}, {81, 118}})
+	expectEqual(t, "transpose", Transpose([][]float64{{1, 2, 3}}), [][]float64{{1}, {2}, {3}})
+
+	if _, err := Mul(a, [][]float64{{1, 2, 3}, {4, 5, 6}}); err != nil {
+		t.Errorf("mul of 2x2 and 2x3 should not fail: %v", err)
+	} else if _, err := Mul([][]float64{{1, 2, 3}}, a); err == nil {
+		t.Errorf("mul of 1x3 and 2x2 should fail")
+	}
+}
+
+func TestDetInverseRank(t *testing.T) {
+	a := [][]float64{{2, 1, 1}, {1, 3, 2}, {1, 0, 0}}
+
+	det, _ := Det(a)
+	if math.Abs(det-(-1)) > 1e-12 {
+		t.Errorf("invalid det, expect=-1, got=%v", det)
+	}
+
+	inv, err := Inverse(a)
+	if err != nil {
+		t.Fatalf("inverse failed: %v", err)
+	}
+	product, _ := Mul(a, inv)
+	expectEqual(t, "a * inv(a)", product, Identity(3))
+
+	singular := [][]float64{{1, 2}, {2, 4}}
+	if _, err := Inverse(singular); err != ErrSingular {
+		t.Errorf("inverse of singular matrix should fail with ErrSingular, got=%v", err)
+	}
+
+	tests := []struct {
+		a    [][]float64
+		rank int
+	}{
+		{a, 3},
+		{singular, 1},
+		{[][]float64{{1, 2, 3}, {4, 5, 6}, {7, 8, 9}}, 2},
+		{[][]float64{{0, 0}, {0, 0}}, 0},
+		{[][]float64{{1, 2, 3}}, 1},
+	}
+	for _, tt := range tests {
+		if got := Rank(tt.a); got != tt.rank {
+			t.Errorf("invalid rank of %v, expect=%d, got=%d", tt.a, tt.rank, got)
+		}
+	}
+}
+
+func TestDecompositions(t *testing.T) {
+	a := [][]float64{{0, 2, 1}, {1, 1, 0}, {4, 3, 2}}
+
+	l, u, p, err := LU(a)
+	if err != nil {
+		t.Fatalf("lu failed: %v", err)
+	}
+	pa, _ := Mul(p, a)
+	lu, _ := Mul(l, u)
+	expectEqual(t, "pa = lu", pa, lu)
+
+	b := [][]float64{{1, 2}, {3, 4}, {5, 6}}
+	q, r := QR(b)
+	qr, _ := Mul(q, r)
+	expectEqual(t, "a = qr", qr, b)
+	qtq, _ := Mul(Transpose(q), q)
+	expectEqual(t, "q^T q = I", qtq, Identity(3))
+	if math.Abs(r[1][0])+math.Abs(r[2][0])+math.Abs(r[2][1]) > 1e-12 {
+		t.Errorf("r should be upper triangular, got %v", r)
+	}
+
+	x, err := Solve(a, [][]float64{{5}, {3}, {12}})
+	if err != nil {
+		t.Fatalf("solve failed: %v", err)
+	}
+	expectEqual(t, "solve", x, [][]float64{{1}, {2}, {1}})
+
+	// the least squares line through (0, 1), (1, 3), (2, 5) is y = 1 + 2x
+	line, err := Solve([][]float64{{1, 0}, {1, 1}, {1, 2}}, [][]float64{{1}, {3}, {5}})
+	if err != nil {
+		t.Fatalf("least squares failed: %v", err)
+	}
+	expectEqual(t, "least squares", line, [][]float64{{1}, {2}})
+}
+
+func TestEigenvalues(t *testing.T) {
+	tests := []struct {
+		a      [][]float64
+		expect []complex128
+	}{
+		{[][]float64{{2, 0}, {0, 3}}, []complex128{2, 3}},
+		{[][]float64{{2, 1}, {1, 2}}, []complex128{1, 3}},
+		{[][]float64{{0, -1}, {1, 0}}, []complex128{-1i, 1i}},
+		{[][]float64{{4, 1, 2}, {0, 3, 1}, {1, 0, 2}}, nil},
+		{[][]float64{{1, 2, 3}, {4, 5, 6}, {7, 8, 9}}, []complex128{
+			complex((15-math.Sqrt(297))/2, 0), 0, complex((15+math.Sqrt(297))/2, 0),
+		}},
+		{[][]float64{{5}}, []complex128{5}},
+	}
+
+	for _, tt := range tests {
+		got, err := Eigenvalues(tt.a)
+		if err != nil {
+			t.Fatalf("eigenvalues of %v failed: %v", tt.a, err)
+		}
+		if tt.expect == nil {
+			// check the trace and the determinant instead
+			var sum, product complex128 = 0, 1
+			for _, v := range got {
+				sum += v
+				product *= v
+			}
+			det, _ := Det(tt.a)
+			if cmplx.Abs(sum-complex(tt.a[0][0]+tt.a[1][1]+tt.a[2][2], 0)) > 1e-9 ||
+				cmplx.Abs(product-complex(det, 0)) > 1e-9 {
+				t.Errorf("invalid eigenvalues of %v, got=%v", tt.a, got)
+			}
+			continue
+		}
+		if len(got) != len(tt.expect) {
+			t.Errorf("invalid eigenvalues of %v, expect=%v, got=%v", tt.a, tt.expect, got)
+			continue
+		}
+		for i := range got {
+			if cmplx.Abs(got[i]-tt.expect[i]) > 1e-9 {
+				t.Errorf("invalid eigenvalues of %v, expect=%v, got=%v", tt.a, tt.expect, got)
+				break
+			}
+		}
+	}
+}
+
+func expectEqual(t *testing.T, name string, got, expect [][]float64) {
+	t.Helper()
+	gr, gc := Size(got)
+	er, ec := Size(expect)
+	if gr != er || gc != ec {
+		t.Errorf("invalid %s, expect=%v, got=%v", name, expect, got)
+		return
+	}
+	for i := range got {
+		for j := range got[i] {
+			if math.Abs(got[i][j]-expect[i][j]) > 1e-9 {
+				t.Errorf("invalid %s, expect=%v, got=%v", name, expect, got)
+				return
+			}
+		}
+	}
+}
@@ -0,0 +1,229 @@
+// Package numbertheory implements the number theory functions of exact big
+// integers.
+package numbertheory
+
+import (
+	"math/big"
+	"slices"
+)
+
+// millerRabinRounds is the number of the Miller-Rabin rounds of IsPrime, with
+// the Baillie-PSW test of math/big no composite number is known to pass.
+const millerRabinRounds = 20
+
+// trialDivisionLimit is the largest factor found by trial division.
+const trialDivisionLimit = 1000
+
+var (
+	one = big.NewInt(1)
+	two = big.NewInt(2)
+)
+
+// IsPrime reports whether n is prime, using the Miller-Rabin test.
+func IsPrime(n *big.Int) bool {
+	return n.ProbablyPrime(millerRabinRounds)
+}
+
+// NextPrime returns the smallest prime greater than n.
+func NextPrime(n *big.Int) *big.Int {
+	if n.Cmp(two) < 0 {
+		return big.NewInt(2)
+	}
+
+	p := new(big.Int).Add(n, one)
+	if p.Bit(0) == 0 && p.Cmp(two) != 0 {
+		p.Add(p, one)
+	}
+	for !IsPrime(p) {
+		p.Add(p, two)
+	}
+	return p
+}
+
+// Factor returns the prime factors of n > 0 in ascending order, with their
+// multiplicities, e.g. 12 is [2, 2, 3]. The small factors are found by trial
+// division, the large ones by Pollard's rho algorithm. It reports false if a
+// factor is too large to be found in maxRhoSteps.
+func Factor(n *big.Int) ([]*big.Int, bool) {
+	var factors []*big.Int
+	n = new(big.Int).Set(n)
+
+	d := new(big.Int)
+	for _, p := range []int64{2, 3, 5} {
+		d.SetInt64(p)
+		for new(big.Int).Rem(n, d).Sign() == 0 {
+			factors = append(factors, big.NewInt(p))
+			n.Quo(n, d)
+		}
+	}
+	// the wheel of 30 skips the multiples of 2, 3 and 5
+	for p, i := int64(7), 0; p <= trialDivisionLimit && n.Cmp(one) > 0; i++ {
+		d.SetInt64(p)
+		if new(big.Int).Mul(d, d).Cmp(n) > 0 {
+			break
+		}
+		for new(big.Int).Rem(n, d).Sign() == 0 {
+			factors = append(factors, big.NewInt(p))
+			n.Quo(n, d)
+		}
+		p += []int64{4, 2, 4, 2, 4, 6, 2, 6}[i%8]
+	}
+
+	large, ok := factorLarge(n)
+	if !ok {
+		return nil, false
+	}
+	factors = append(factors, large...)
+	slices.SortFunc(factors, func(a, b *big.Int) int { return a.Cmp(b) })
+	return factors, true
+}
+
+// factorLarge returns the prime factors of n, which has no factors less than
+// 7, unsorted.
+func factorLarge(n *big.Int) ([]*big.Int, bool) {
+	switch {
+	case n.Cmp(one) <= 0:
+		return nil, true
+	case IsPrime(n):
+		return []*big.Int{n}, true
+	}
+
+	d, ok := pollardRho(n)
+	if !ok {
+		return nil, false
+	}
+	left, ok := factorLarge(d)
+	if !ok {
+		return nil, false
+	}
+	right, ok := factorLarge(new(big.Int).Quo(n, d))
+	return append(left, right...), ok
+}
+
+// maxRhoSteps limits the steps of pollardRho, which finds the factors up to
+// about 10^12 in time.
+const maxRhoSteps = 1 << 21
+
+// pollardRho returns a non-trivial factor of the composite n, using Brent's
+// cycle detection with the polynomials x^2 + c.
+func pollardRho(n *big.Int) (*big.Int, bool) {
+	const batch = 128
+	x, y, ys, q, g, diff := new(big.Int), new(big.Int), new(big.Int), new(big.Int), new(big.Int), new(big.Int)
+
+	steps := 0
+	for c := big.NewInt(1); steps < maxRhoSteps; c.Add(c, one) {
+		step := func(v *big.Int) {
+			v.Mul(v, v)
+			v.Add(v, c)
+			v.Mod(v, n)
+		}
+
+		y.SetInt64(2)
+		q.SetInt64(1)
+		g.SetInt64(1)
+		for r := 1; g.Cmp(one) == 0 && steps < maxRhoSteps; r *= 2 {
+			steps += 2 * r
+			x.Set(y)
+			for i := 0; i < r; i++ {
+				step(y)
+			}
+			// the product of the differences of a batch needs one gcd
+			for k := 0; k < r && g.Cmp(one) == 0; k += batch {
+				ys.Set(y)
+				for i := 0; i < min(batch, r-k); i++ {
+					step(y)
+					q.Mul(q, diff.Abs(diff.Sub(x, y)))
+					q.Mod(q, n)
+				}
+				g.GCD(nil, nil, q, n)
+			}
+		}
+
+		if g.Cmp(n) == 0 {
+			// the batch passed the factor, step through it again
+			for {
+				step(ys)
+				g.GCD(nil, nil, diff.Abs(diff.Sub(x, ys)), n)
+				if g.Cmp(one) > 0 {
+					break
+				}
+			}
+		}
+		if g.Cmp(n) != 0 && g.Cmp(one) != 0 {
+			return new(big.Int).Set(g), true
+		}
+	}
+	return nil, false
+}
+
+// Totient returns Euler's totient of n > 0, the number of the integers from 1
+// to n coprime to n. It reports false if n cannot be factored.
+func Totient(n *big.Int) (*big.Int, bool) {
+	result := new(big.Int).Set(n)
+	factors, ok := Factor(n)
+	if !ok {
+		return nil, false
+	}
+	for i, p := range factors {
+		if i > 0 && p.Cmp(factors[i-1]) == 0 {
+			continue
+		}
+		// result *= (1 - 1/p)
+		result.Quo(result, p)
+		result.Mul(result, new(big.Int).Sub(p, one))
+	}
+	return result, true
+}
+
+// Fibonacci returns the n-th Fibonacci number, F(0) = 0 and F(1) = 1, using
+// the fast doubling F(2k) = F(k)(2F(k+1) - F(k)) and F(2k+1) = F(k)^2 +
+// F(k+1)^2. The negative n are F(-n) = (-1)^(n+1) F(n).
+func Fibonacci(n int64) *big.Int {
+	negative := n < 0
+	if negative {
+		n = -n
+	}
+
+	a, b := big.NewInt(0), big.NewInt(1) // F(k), F(k+1)
+	t := new(big.Int)
+	for bit := 62; bit >= 0; bit-- {
+		// a, b = F(2k), F(2k+1)
+		t.Lsh(b, 1)
+		t.Sub(t, a)
+		t.Mul(t, a)
+		b.Add(new(big.Int).Mul(a, a), new(big.Int).Mul(b, b))
+		a.Set(t)
+		if n>>bit&1 == 1 {
+			a, b = b, a.Add(a, b)
+		}
+	}
+
+	if negative && n%2 == 0 {
+		a.Neg(a)
+	}
+	return a
+}
+
+// ModInverse returns the inverse of a modulo m > 1, false if a and m are not
+// coprime.
+func ModInverse(a, m *big.Int) (*big.Int, bool) {
+	g, x := new(big.Int), new(big.Int)
+	g.GCD(x, nil, new(big.Int).Mod(a, m), m)
+	if g.Cmp(one) != 0 {
+		return nil, false
+	}
+	return x.Mod(x, m), true
+}
+
+// ModPow returns b^e modulo m > 0, the negative exponents are the powers of
+// the inverse of b, false if it does not exist.
+func ModPow(b, e, m *big.Int) (*big.Int, bool) {
+	if e.Sign() < 0 {
+		inv, ok := ModInverse(b, m)
+		if !ok {
+			return nil, false
+		}
+		return new(big.Int).Exp(inv, new(big.Int).Neg(e), m), true
+	}
+	return new(big.Int).Exp(new(big.Int).Mod(b, m), e, m), true
+}
@@ -0,0 +1,101 @@
+package numbertheory
+
+import (
+	"fmt"
+	"math/big"
+	"testing"
+)
+
+func parse(t *testing.T, s string) *big.Int {
+	n, ok := new(big.Int).SetString(s, 10)
+	if !ok {
+		t.Fatalf("invalid integer %q", s)
+	}
+	return n
+}
+
+func TestFactor(t *testing.T) {
+	tests := []struct {
+		n      string
+		expect string
+	}{
+		{"2", "[2]"},
+		{"12", "[2 2 3]"},
+		{"97", "[97]"},
+		{"1001", "[7 11 13]"},
+		{"1000000007", "[1000000007]"},
+		{"600851475143", "[71 839 1471 6857]"},
+		{"1000000016000000063", "[1000000007 1000000009]"},
+		{"2432902008176640000", "[2 2 2 2 2 2 2 2 2 2 2 2 2 2 2 2 2 2 3 3 3 3 3 3 3 3 5 5 5 5 7 7 11 13 17 19]"},
+	}
+
+	for _, tt := range tests {
+		factors, ok := Factor(parse(t, tt.n))
+		if got := fmt.Sprint(factors); !ok || got != tt.expect {
+			t.Errorf("invalid factors of %s, expect=%s, got=%s", tt.n, tt.expect, factors)
+		}
+	}
+
+	// 2^128 + 1 is 59649589127497217 * 5704689200685129054721
+	if factors, ok := Factor(parse(t, "340282366920938463463374607431768211457")); ok {
+		t.Errorf("expect the factors to be too large, got=%s", factors)
+	}
+}
+
+func TestPrimes(t *testing.T) {
+	for _, tt := range []struct {
+		n       string
+		isPrime bool
+		next    string
+	}{
+		{"1", false, "2"},
+		{"2", true, "3"},
+		{"90", false, "97"},
+		{"561", false, "563"}, // a Carmichael number
+		{"1000000007", true, "1000000009"},
+	} {
+		n := parse(t, tt.n)
+		if got := IsPrime(n); got != tt.isPrime {
+			t.Errorf("invalid IsPrime(%s), expect=%t, got=%t", tt.n, tt.isPrime, got)
+		}
+		if got := NextPrime(n).String(); got != tt.next {
+			t.Errorf("invalid NextPrime(%s), expect=%s, got=%s", tt.n, tt.next, got)
+		}
+	}
+}
+
+func TestFunctions(t *testing.T) {
+	modPow, _ := ModPow(big.NewInt(4), big.NewInt(13), big.NewInt(497))
+	modPowInverse, _ := ModPow(big.NewInt(3), big.NewInt(-1), big.NewInt(7))
+	modInverse, _ := ModInverse(big.NewInt(-3), big.NewInt(7))
+	_, notCoprime := ModInverse(big.NewInt(4), big.NewInt(8))
+	totient := func(n int64) string {
+		result, _ := Totient(big.NewInt(n))
+		return result.String()
+	}
+
+	tests := []struct {
+		name   string
+		got    string
+		expect string
+	}{
+		{"totient of 1", totient(1), "1"},
+		{"totient of 36", totient(36), "12"},
+		{"totient of a prime", totient(97), "96"},
+		{"fibonacci of 0", Fibonacci(0).String(), "0"},
+		{"fibonacci of 10", Fibonacci(10).String(), "55"},
+		{"fibonacci of 100", Fibonacci(100).String(), "354224848179261915075"},
+		{"fibonacci of -8", Fibonacci(-8).String(), "-21"},
+		{"fibonacci of -7", Fibonacci(-7).String(), "13"},
+		{"modpow", modPow.String(), "445"},
+		{"modpow of a negative exponent", modPowInverse.String(), "5"},
+		{"modinverse of a negative", modInverse.String(), "2"},
+		{"modinverse of not coprime", fmt.Sprint(notCoprime), "false"},
+	}
+
+	for _, tt := range tests {
+		if tt.got != tt.expect {
+			t.Errorf("invalid %s, expect=%s, got=%s", tt.name, tt.expect, tt.got)
+		}
+	}
+}
@@ -1,14 +1,29 @@
 package object
 
+import "sort"
+
 type Environment struct {
-	store map[string]Object
-	outer *Environment
+	store    map[string]Object
+	consts   map[string]bool // the names of the constant bindings
+	outer    *Environment
+	random   *Random
+	block    bool         // the scope of a block, not of a function
+	file     string       // the file of a module environment
+	importer *Environment // the environment which imports the module
+	sandbox  bool         // a copy made by Sandbox
 }
 
 func NewEnvironment() *Environment {
+	return NewEnvironmentWithRandom(NewTimeSeededRandom())
+}
+
+// NewEnvironmentWithRandom returns an outermost environment which uses the
+// random number generator, so the generator can outlive the environment.
+func NewEnvironmentWithRandom(random *Random) *Environment {
 	return &Environment{
-		store: make(map[string]Object),
-		outer: nil,
+		store:  make(map[string]Object),
+		outer:  nil,
+		random: random,
 	}
 }
 
@@ -19,6 +34,17 @@ func NewEnclosedEnvironment(outer *Environment) *Environment {
 	}
 }
 
+// NewBlockEnvironment returns the scope of a block, such as a branch of an if
+// expression or an iteration of a loop. The assignments of new names in a
+// block bind them in the enclosing function scope.
+func NewBlockEnvironment(outer *Environment) *Environment {
+	return &Environment{
+		store: make(map[string]Object),
+		outer: outer,
+		block: true,
+	}
+}
+
 func (e *Environment) Get(name string) (Object, bool) {
 	obj, ok := e.store[name]
 	if !ok && e.outer != nil {
@@ -27,6 +53,122 @@ func (e *Environment) Get(name string) (Object, bool) {
 	return obj, ok
 }
 
+// NewModuleEnvironment returns the environment of the module of the file,
+// which does not see the bindings of the importer but shares its random
+// number generator.
+func NewModuleEnvironment(file string, importer *Environment) *Environment {
+	return &Environment{
+		store:    make(map[string]Object),
+		random:   importer.Random(),
+		file:     file,
+		importer: importer,
+		sandbox:  importer.Sandboxed(),
+	}
+}
+
+// GetLocal returns the binding of this environment, excluding the bindings of
+// the outer environments.
+func (e *Environment) GetLocal(name string) (Object, bool) {
+	obj, ok := e.store[name]
+	return obj, ok
+}
+
 func (e *Environment) Set(name string, value Object) {
 	e.store[name] = value
 }
+
+// Assign sets the nearest binding of the name, in this or the outer
+// environments. A new name is bound in the nearest function scope.
+func (e *Environment) Assign(name string, value Object) {
+	for env := e; env != nil; env = env.outer {
+		if _, ok := env.store[name]; ok {
+			env.store[name] = value
+			return
+		}
+	}
+
+	env := e
+	for env.block {
+		env = env.outer
+	}
+	env.store[name] = value
+}
+
+// SetConst binds the name to a constant value in this environment.
+func (e *Environment) SetConst(name string, value Object) {
+	e.store[name] = value
+	if e.consts == nil {
+		e.consts = make(map[string]bool)
+	}
+	e.consts[name] = true
+}
+
+// IsConst reports whether the nearest binding of the name, in this or the
+// outer environments, is a constant.
+func (e *Environment) IsConst(name string) bool {
+	for env := e; env != nil; env = env.outer {
+		if _, ok := env.store[name]; ok {
+			return env.consts[name]
+		}
+	}
+	return false
+}
+
+// Delete removes the binding from this environment and reports whether it existed.
+func (e *Environment) Delete(name string) bool {
+	if _, ok := e.store[name]; !ok {
+		return false
+	}
+	delete(e.store, name)
+	delete(e.consts, name)
+	return true
+}
+
+func (e *Environment) Outer() *Environment {
+	return e.outer
+}
+
+// Random returns the random number generator of the outermost environment,
+// shared by all of its enclosed environments.
+func (e *Environment) Random() *Random {
+	for e.outer != nil {
+		e = e.outer
+	}
+	return e.random
+}
+
+// File returns the file of the module which the environment belongs to, ""
+// if it is not in a module.
+func (e *Environment) File() string {
+	for e.outer != nil {
+		e = e.outer
+	}
+	return e.file
+}
+
+// Imports returns the files of the modules being imported up to the
+// environment, the nearest first.
+func (e *Environment) Imports() []string {
+	var files []string
+	for e != nil {
+		for e.outer != nil {
+			e = e.outer
+		}
+		if e.file != "" {
+			files = append(files, e.file)
+		}
+		e = e.importer
+	}
+	return files
+}
+
+// Names returns the sorted names of the bindings in this environment,
+// excluding the bindings of the outer environments.
+func (e *Environment) Names() []string {
+	names := make([]string, 0, len(e.store))
+	for name := range e.store {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
@@ -0,0 +1,296 @@
+package object
+
+import (
+	"fmt"
+	"math"
+	"math/big"
+	"strconv"
+	"strings"
+)
+
+type Notation string
+
+const (
+	NOTATION_AUTO        Notation = "auto"        // e.g. "1234.5", "1e+21"
+	NOTATION_FIXED       Notation = "fixed"       // e.g. "1234.5", "1000000000000000000000"
+	NOTATION_SCIENTIFIC  Notation = "scientific"  // e.g. "1.2345e+03"
+	NOTATION_ENGINEERING Notation = "engineering" // e.g. "1.2345e+03", "123.45e-06"
+)
+
+// NumberFormat describes how numbers are displayed.
+type NumberFormat struct {
+	Notation          Notation
+	Precision         int // the number of decimal places, -1 for as many as needed
+	SignificantDigits int // the number of significant digits, 0 for as many as needed
+	GroupDigits       bool
+	DecimalSeparator  string // "." or ","
+	TrimZeros         bool   // trim the trailing zeros of the decimal places
+}
+
+// DefaultNumberFormat displays numbers the same way as fmt.Sprint, except the
+// integers below 1e21, which have all of their digits, e.g. 1500000 instead of
+// 1.5e+06.
+var DefaultNumberFormat = NumberFormat{
+	Notation:          NOTATION_AUTO,
+	Precision:         -1,
+	SignificantDigits: 0,
+	GroupDigits:       false,
+	DecimalSeparator:  ".",
+	TrimZeros:         false,
+}
+
+func (f NumberFormat) Format(value float64) string {
+	if math.IsNaN(value) || math.IsInf(value, 0) {
+		return fmt.Sprint(value)
+	}
+
+	var mantissa, exponent string
+	switch f.Notation {
+	case NOTATION_SCIENTIFIC:
+		mantissa, exponent = f.scientific(value, false)
+	case NOTATION_ENGINEERING:
+		mantissa, exponent = f.scientific(value, true)
+	case NOTATION_FIXED:
+		mantissa = f.fixed(value)
+	default:
+		if f.Precision < 0 && f.SignificantDigits <= 0 {
+			mantissa, exponent, _ = strings.Cut(fmt.Sprint(value), "e")
+			if value == math.Trunc(value) && math.Abs(value) < 1e21 {
+				// the integers have all of their digits, like the exact ones
+				mantissa, exponent = strconv.FormatFloat(value, 'f', -1, 64), ""
+			}
+		} else {
+			mantissa = f.fixed(value)
+		}
+	}
+
+	if f.TrimZeros && strings.Contains(mantissa, ".") {
+		mantissa = strings.TrimRight(mantissa, "0")
+		mantissa = strings.TrimSuffix(mantissa, ".")
+	}
+
+	integer, fraction, hasFraction := strings.Cut(mantissa, ".")
+	if f.GroupDigits {
+		integer = groupDigits(integer, f.groupSeparator())
+	}
+
+	result := integer
+	if hasFraction {
+		result += f.decimalSeparator() + fraction
+	}
+	if exponent != "" {
+		result += "e" + exponent
+	}
+	return result
+}
+
+// FormatInteger formats the exact integer with all of its digits, except in
+// the scientific and engineering notations and with significant digits.
+func (f NumberFormat) FormatInteger(n *big.Int) string {
+	if f.Notation == NOTATION_SCIENTIFIC || f.Notation == NOTATION_ENGINEERING || f.SignificantDigits > 0 {
+		value, _ := new(big.Float).SetInt(n).Float64()
+		return f.Format(value)
+	}
+
+	result := n.String()
+	if f.GroupDigits {
+		result = groupDigits(result, f.groupSeparator())
+	}
+	if f.Precision > 0 && !f.TrimZeros {
+		result += f.decimalSeparator() + strings.Repeat("0", f.Precision)
+	}
+	return result
+}
+
+// FormatObject returns the inspection of the object with its numbers in the
+// format, including the numbers inside lists, matrices, complex numbers and
+// polynomials.
+func (f NumberFormat) FormatObject(obj Object) string {
+	switch obj := obj.(type) {
+	case *LetValue:
+		return f.FormatObject(obj.Value)
+	case *Number:
+		if obj.Exact != nil {
+			return f.FormatInteger(obj.Exact)
+		}
+		return f.Format(obj.Value)
+	case *Estimate:
+		return f.Format(obj.Value) + " ± " + strconv.FormatFloat(obj.Error, 'g', 2, 64)
+	case *Polynomial:
+		return FormatPolynomial(obj.Coeffs, f.Format)
+	case *Complex:
+		return FormatComplex(obj.Value, f.Format)
+	case *Matrix:
+		return FormatMatrix(obj.Rows, f.Format)
+	case *List:
+		elements := make([]string, len(obj.Elements))
+		for i, element := range obj.Elements {
+			elements[i] = f.FormatObject(element)
+		}
+		return "[" + strings.Join(elements, ", ") + "]"
+	default:
+		return obj.Inspect()
+	}
+}
+
+// fixed formats the value without an exponent.
+func (f NumberFormat) fixed(value float64) string {
+	if f.Precision >= 0 {
+		return strconv.FormatFloat(value, 'f', f.Precision, 64)
+	}
+	if f.SignificantDigits <= 0 || value == 0 {
+		return strconv.FormatFloat(value, 'f', -1, 64)
+	}
+
+	rounded, _ := strconv.ParseFloat(strconv.FormatFloat(value, 'e', f.SignificantDigits-1, 64), 64)
+	exp := int(math.Floor(math.Log10(math.Abs(rounded))))
+	return strconv.FormatFloat(rounded, 'f', max(0, f.SignificantDigits-1-exp), 64)
+}
+
+// scientific formats the value as a mantissa and an exponent, e.g. "1.5" and "+03".
+// The exponent is a multiple of 3 if engineering is true.
+func (f NumberFormat) scientific(value float64, engineering bool) (string, string) {
+	digits := -1
+	if f.Precision >= 0 {
+		digits = f.Precision
+	} else if f.SignificantDigits > 0 {
+		digits = f.SignificantDigits - 1
+	}
+
+	mantissa, exponent, _ := strings.Cut(strconv.FormatFloat(value, 'e', digits, 64), "e")
+	exp, _ := strconv.Atoi(exponent)
+	if !engineering || exp%3 == 0 {
+		return mantissa, exponent
+	}
+
+	shift := exp % 3
+	if shift < 0 {
+		shift += 3
+	}
+	exp -= shift
+
+	// move the decimal point of the mantissa to the right by shift digits
+	sign := ""
+	if strings.HasPrefix(mantissa, "-") {
+		sign, mantissa = "-", mantissa[1:]
+	}
+	integer, fraction, _ := strings.Cut(mantissa, ".")
+	for len(fraction) < shift {
+		fraction += "0"
+	}
+	mantissa = sign + integer + fraction[:shift]
+	if fraction[shift:] != "" {
+		mantissa += "." + fraction[shift:]
+	}
+
+	return mantissa, fmt.Sprintf("%+03d", exp)
+}
+
+func (f NumberFormat) decimalSeparator() string {
+	if f.DecimalSeparator == "" {
+		return "."
+	}
+	return f.DecimalSeparator
+}
+
+func (f NumberFormat) groupSeparator() string {
+	if f.decimalSeparator() == "," {
+		return "."
+	}
+	return ","
+}
+
+// groupDigits inserts the separator between every 3 digits, e.g. "-1,234,567"
+func groupDigits(integer string, separator string) string {
+	sign := ""
+	if strings.HasPrefix(integer, "-") {
+		sign, integer = "-", integer[1:]
+	}
+
+	var sb strings.Builder
+	for i, ch := range integer {
+		if i > 0 && (len(integer)-i)%3 == 0 {
+			sb.WriteString(separator)
+		}
+		sb.WriteRune(ch)
+	}
+
+	return sign + sb.String()
+}
+
+// FormatPolynomial returns the polynomial in math notation, e.g. `3x^2 - 2x + 1`
+func FormatPolynomial(coeffs []float64, format func(float64) string) string {
+	var sb strings.Builder
+
+	for i := len(coeffs) - 1; i >= 0; i-- {
+		c := coeffs[i]
+		if c == 0 {
+			continue
+		}
+
+		switch {
+		case sb.Len() == 0 && c < 0:
+			sb.WriteString("-")
+		case sb.Len() > 0 && c < 0:
+			sb.WriteString(" - ")
+		case sb.Len() > 0:
+			sb.WriteString(" + ")
+		}
+
+		if abs := math.Abs(c); abs != 1 || i == 0 {
+			sb.WriteString(format(abs))
+		}
+		if i >= 1 {
+			sb.WriteString("x")
+		}
+		if i >= 2 {
+			sb.WriteString("^" + strconv.Itoa(i))
+		}
+	}
+
+	if sb.Len() == 0 {
+		return "0"
+	}
+	return sb.String()
+}
+
+// FormatComplex returns the complex number as `a + bi`
+func FormatComplex(value complex128, format func(float64) string) string {
+	re, im := real(value), imag(value)
+	if re == 0 {
+		return format(im) + "i"
+	}
+	if math.Signbit(im) {
+		return format(re) + " - " + format(-im) + "i"
+	}
+	return format(re) + " + " + format(im) + "i"
+}
+
+// FormatMatrix returns the matrix as a grid of right aligned columns, e.g.
+//
+//	[ 1  -2 ]
+//	[ 3   4 ]
+func FormatMatrix(rows [][]float64, format func(float64) string) string {
+	if len(rows) == 0 {
+		return "[]"
+	}
+
+	cells := make([][]string, len(rows))
+	widths := make([]int, len(rows[0]))
+	for i, row := range rows {
+		cells[i] = make([]string, len(row))
+		for j, v := range row {
+			cells[i][j] = format(v)
+			widths[j] = max(widths[j], len(cells[i][j]))
+		}
+	}
+
+	lines := make([]string, len(rows))
+	for i, row := range cells {
+		for j, cell := range row {
+			row[j] = strings.Repeat(" ", widths[j]-len(cell)) + cell
+		}
+		lines[i] = "[ " + strings.Join(row, "  ") + " ]"
+	}
+	return strings.Join(lines, "\n")
+}
@@ -0,0 +1,205 @@
+package object
+
+import (
+	"math/big"
+	"testing"
+)
+
+func TestNumberFormat(t *testing.T) {
+	fixed := func(precision int) NumberFormat {
+		f := DefaultNumberFormat
+		f.Notation = NOTATION_FIXED
+		f.Precision = precision
+		return f
+	}
+	significant := func(digits int) NumberFormat {
+		f := DefaultNumberFormat
+		f.SignificantDigits = digits
+		return f
+	}
+	notation := func(n Notation, precision int) NumberFormat {
+		f := DefaultNumberFormat
+		f.Notation = n
+		f.Precision = precision
+		return f
+	}
+	grouped := func(separator string) NumberFormat {
+		f := DefaultNumberFormat
+		f.Notation = NOTATION_FIXED
+		f.GroupDigits = true
+		f.DecimalSeparator = separator
+		return f
+	}
+	trimmed := func(f NumberFormat) NumberFormat {
+		f.TrimZeros = true
+		return f
+	}
+
+	tests := []struct {
+		format NumberFormat
+		value  float64
+		expect string
+	}{
+		// default
+		{DefaultNumberFormat, 5, "5"},
+		{DefaultNumberFormat, 0.30000000000000004, "0.30000000000000004"},
+		{DefaultNumberFormat, 1e21, "1e+21"},
+		{DefaultNumberFormat, 2432902008176640000, "2432902008176640000"},
+		{DefaultNumberFormat, 1.5e6, "1500000"},
+		{DefaultNumberFormat, -1.5e-7, "-1.5e-07"},
+		// fixed decimal places
+		{fixed(-1), 1e21, "1000000000000000000000"},
+		{fixed(2), 0.30000000000000004, "0.30"},
+		{fixed(2), -3.14159, "-3.14"},
+		{fixed(0), 2.5, "2"},
+		// significant digits
+		{significant(3), 3.14159, "3.14"},
+		{significant(2), 1234.5, "1200"},
+		{significant(3), 0.000123456, "0.000123"},
+		{significant(3), 1, "1.00"},
+		{significant(3), 0, "0"},
+		// scientific and engineering notation
+		{notation(NOTATION_SCIENTIFIC, -1), 1234.5, "1.2345e+03"},
+		{notation(NOTATION_SCIENTIFIC, 2), 1234.5, "1.23e+03"},
+		{notation(NOTATION_SCIENTIFIC, 2), -0.000123, "-1.23e-04"},
+		{notation(NOTATION_ENGINEERING, -1), 1234.5, "1.2345e+03"},
+		{notation(NOTATION_ENGINEERING, -1), 12345, "12.345e+03"},
+		{notation(NOTATION_ENGINEERING, -1), 123450, "123.45e+03"},
+		{notation(NOTATION_ENGINEERING, 1), 0.00012, "120e-06"},
+		{notation(NOTATION_ENGINEERING, 2), -0.0012, "-1.20e-03"},
+		// digit grouping and decimal separator
+		{grouped("."), 1234567.25, "1,234,567.25"},
+		{grouped("."), -123456, "-123,456"},
+		{grouped("."), 999, "999"},
+		{grouped(","), 1234567.25, "1.234.567,25"},
+		// trimming trailing zeros
+		{trimmed(fixed(4)), 2.5, "2.5"},
+		{trimmed(fixed(4)), 2, "2"},
+		{trimmed(significant(3)), 1, "1"},
+		{trimmed(notation(NOTATION_SCIENTIFIC, 3)), 1500, "1.5e+03"},
+	}
+
+	for i, tt := range tests {
+		got := tt.format.Format(tt.value)
+		if got != tt.expect {
+			t.Errorf("tests[%d] - invalid format of %v, expect=%q, got=%q", i, tt.value, tt.expect, got)
+		}
+	}
+}
+
+func TestFormatInteger(t *testing.T) {
+	n, _ := new(big.Int).SetString("-12345678901234567890", 10)
+	grouped := DefaultNumberFormat
+	grouped.GroupDigits = true
+	fixed := DefaultNumberFormat
+	fixed.Notation = NOTATION_FIXED
+	fixed.Precision = 2
+	scientific := DefaultNumberFormat
+	scientific.Notation = NOTATION_SCIENTIFIC
+	scientific.Precision = 3
+
+	tests := []struct {
+		format NumberFormat
+		expect string
+	}{
+		{DefaultNumberFormat, "-12345678901234567890"},
+		{grouped, "-12,345,678,901,234,567,890"},
+		{fixed, "-12345678901234567890.00"},
+		{scientific, "-1.235e+19"},
+	}
+
+	for i, tt := range tests {
+		got := tt.format.FormatInteger(n)
+		if got != tt.expect {
+			t.Errorf("tests[%d] - invalid format of %v, expect=%q, got=%q", i, n, tt.expect, got)
+		}
+	}
+}
+
+func TestFormatPolynomial(t *testing.T) {
+	tests := []struct {
+		coeffs []float64
+		expect string
+	}{
+		{[]float64{1, -2, 3}, "3x^2 - 2x + 1"},
+		{[]float64{-4, 0, 1}, "x^2 - 4"},
+		{[]float64{0, -1}, "-x"},
+		{[]float64{0.5, 0, 0, -1}, "-x^3 + 0.5"},
+		{[]float64{-1}, "-1"},
+		{[]float64{}, "0"},
+	}
+
+	for _, tt := range tests {
+		got := FormatPolynomial(tt.coeffs, DefaultNumberFormat.Format)
+		if got != tt.expect {
+			t.Errorf("invalid format of %v, expect=%q, got=%q", tt.coeffs, tt.expect, got)
+		}
+	}
+}
+
+func TestFormatComplex(t *testing.T) {
+	tests := []struct {
+		value  complex128
+		expect string
+	}{
+		{1 + 2i, "1 + 2i"},
+		{0.5 - 1.5i, "0.5 - 1.5i"},
+		{-2i, "-2i"},
+		{-3, "-3 + 0i"},
+	}
+
+	for _, tt := range tests {
+		got := FormatComplex(tt.value, DefaultNumberFormat.Format)
+		if got != tt.expect {
+			t.Errorf("invalid format of %v, expect=%q, got=%q", tt.value, tt.expect, got)
+		}
+	}
+}
+
+func TestFormatMatrix(t *testing.T) {
+	tests := []struct {
+		rows   [][]float64
+		expect string
+	}{
+		{[][]float64{{1, -2}, {3, 4}}, "[ 1  -2 ]\n[ 3   4 ]"},
+		{[][]float64{{0.5, 10, 100}}, "[ 0.5  10  100 ]"},
+		{[][]float64{{1}, {-1}}, "[  1 ]\n[ -1 ]"},
+		{nil, "[]"},
+	}
+
+	for _, tt := range tests {
+		got := FormatMatrix(tt.rows, DefaultNumberFormat.Format)
+		if got != tt.expect {
+			t.Errorf("invalid format of %v, expect=%q, got=%q", tt.rows, tt.expect, got)
+		}
+	}
+}
+
+func TestFormatObject(t *testing.T) {
+	format := DefaultNumberFormat
+	format.Notation = NOTATION_FIXED
+	format.Precision = 2
+
+	exact, _ := new(big.Int).SetString("18446744073709551617", 10)
+
+	tests := []struct {
+		obj    Object
+		expect string
+	}{
+		{&Number{Value: 1.2345}, "1.23"},
+		{&Number{Value: 18446744073709551617, Exact: exact}, "18446744073709551617.00"},
+		{&LetValue{Value: &Number{Value: 2}}, "2.00"},
+		{&Estimate{Value: 1.2345, Error: 0.0001}, "1.23 ± 0.0001"},
+		{&List{Elements: []Object{&Number{Value: 1.2345}, &List{Elements: []Object{&Number{Value: 2}}}}}, "[1.23, [2.00]]"},
+		{&List{Elements: []Object{&Complex{Value: 1.2345 - 2i}, &Boolean{Value: true}}}, "[1.23 - 2.00i, true]"},
+		{&Polynomial{Coeffs: []float64{0.5, 2}}, "2.00x + 0.50"},
+		{&Matrix{Rows: [][]float64{{1, 2.5}}}, "[ 1.00  2.50 ]"},
+	}
+
+	for _, tt := range tests {
+		got := format.FormatObject(tt.obj)
+		if got != tt.expect {
+			t.Errorf("invalid format of %s, expect=%q, got=%q", tt.obj.Inspect(), tt.expect, got)
+		}
+	}
+}
@@ -2,9 +2,11 @@ package object
 
 import (
 	"fmt"
+	"math/big"
 	"strings"
 
 	"github.com/DeepAung/qcal/internal/ast"
+	"github.com/DeepAung/qcal/internal/plot"
 )
 
 type ObjectType string
@@ -12,13 +14,27 @@ type ObjectType string
 const (
 	NUMBER_OBJ           ObjectType = "NUMBER"
 	BOOLEAN_OBJ          ObjectType = "BOOLEAN"
+	STRING_OBJ           ObjectType = "STRING"
 	NULL_OBJ             ObjectType = "NULL"
 	ERROR_OBJ            ObjectType = "ERROR"
+	ERROR_VALUE_OBJ      ObjectType = "ERROR_VALUE"
 	LET_VALUE_OBJ        ObjectType = "LET_VAULE"
 	RETURN_VALUE_OBJ     ObjectType = "RETURN_VALUE"
+	BREAK_OBJ            ObjectType = "BREAK"
+	CONTINUE_OBJ         ObjectType = "CONTINUE"
+	TAIL_CALL_OBJ        ObjectType = "TAIL_CALL"
+	NAMED_ARGUMENT_OBJ   ObjectType = "NAMED_ARGUMENT"
 	FUNCTION_OBJ         ObjectType = "FUNCTION"
 	BUILTIN_FUNCTION_OBJ ObjectType = "BUILTIN_FUNCTION"
 	BUILTIN_VALUE_OBJ    ObjectType = "BUILTIN_VALUE"
+	PLOT_OBJ             ObjectType = "PLOT"
+	ESTIMATE_OBJ         ObjectType = "ESTIMATE"
+	EQUATION_OBJ         ObjectType = "EQUATION"
+	LIST_OBJ             ObjectType = "LIST"
+	POLYNOMIAL_OBJ       ObjectType = "POLYNOMIAL"
+	COMPLEX_OBJ          ObjectType = "COMPLEX"
+	MATRIX_OBJ           ObjectType = "MATRIX"
+	MODULE_OBJ           ObjectType = "MODULE"
 )
 
 type Object interface {
@@ -26,12 +42,20 @@ type Object interface {
 	Inspect() string
 }
 
+// Number is a float64. Exact is the exact value of an integer too large for
+// the float64 Value, nil for the other numbers.
 type Number struct {
 	Value float64
+	Exact *big.Int
 }
 
 func (i *Number) Type() ObjectType { return NUMBER_OBJ }
-func (i *Number) Inspect() string  { return fmt.Sprint(i.Value) }
+func (i *Number) Inspect() string {
+	if i.Exact != nil {
+		return i.Exact.String()
+	}
+	return sprint(i.Value)
+}
 
 type Boolean struct {
 	Value bool
@@ -40,18 +64,52 @@ type Boolean struct {
 func (b *Boolean) Type() ObjectType { return BOOLEAN_OBJ }
 func (b *Boolean) Inspect() string  { return fmt.Sprint(b.Value) }
 
+type String struct {
+	Value string
+}
+
+func (s *String) Type() ObjectType { return STRING_OBJ }
+func (s *String) Inspect() string  { return s.Value }
+
 type Null struct{}
 
 func (n *Null) Type() ObjectType { return NULL_OBJ }
 func (n *Null) Inspect() string  { return "null" }
 
+// Error stops the evaluation up to a try expression. Code is the kind of the
+// error, e.g. "runtime" for the errors of the evaluator, and Line and Column
+// are the position of the expression of the error, 0 if it is unknown.
 type Error struct {
 	Message string
+	Code    string
+	Line    int
+	Column  int
 }
 
 func (e *Error) Type() ObjectType { return ERROR_OBJ }
 func (e *Error) Inspect() string  { return "ERROR: " + e.Message }
 
+// ErrorValue is an error caught by a try expression, a value which does not
+// stop the evaluation.
+type ErrorValue struct {
+	Error *Error
+}
+
+func (ev *ErrorValue) Type() ObjectType { return ERROR_VALUE_OBJ }
+func (ev *ErrorValue) Inspect() string {
+	var sb strings.Builder
+
+	sb.WriteString(ev.Error.Code)
+	sb.WriteString(" error")
+	if ev.Error.Line > 0 {
+		fmt.Fprintf(&sb, " at %d:%d", ev.Error.Line, ev.Error.Column)
+	}
+	sb.WriteString(": ")
+	sb.WriteString(ev.Error.Message)
+
+	return sb.String()
+}
+
 type LetValue struct {
 	Value Object
 }
@@ -66,8 +124,41 @@ type ReturnValue struct {
 func (rv *ReturnValue) Type() ObjectType { return RETURN_VALUE_OBJ }
 func (rv *ReturnValue) Inspect() string  { return rv.Value.Inspect() }
 
+// Break is the result of a `break` statement, which stops the innermost loop.
+type Break struct{}
+
+func (b *Break) Type() ObjectType { return BREAK_OBJ }
+func (b *Break) Inspect() string  { return "break" }
+
+// Continue is the result of a `continue` statement, which skips to the next
+// iteration of the innermost loop.
+type Continue struct{}
+
+func (c *Continue) Type() ObjectType { return CONTINUE_OBJ }
+func (c *Continue) Inspect() string  { return "continue" }
+
+// TailCall is a call in the tail position of a function body, which is
+// returned to the caller to run instead of growing the stack.
+type TailCall struct {
+	Function  Object
+	Arguments []Object
+}
+
+func (tc *TailCall) Type() ObjectType { return TAIL_CALL_OBJ }
+func (tc *TailCall) Inspect() string  { return "tail call of " + tc.Function.Inspect() }
+
+// NamedArgument is the value of a named argument of a call, which is bound to
+// the parameter of the same name.
+type NamedArgument struct {
+	Name  string
+	Value Object
+}
+
+func (na *NamedArgument) Type() ObjectType { return NAMED_ARGUMENT_OBJ }
+func (na *NamedArgument) Inspect() string  { return na.Name + " = " + na.Value.Inspect() }
+
 type NormalFunction struct {
-	Parameters []*ast.Identifier
+	Parameters []*ast.Parameter
 	Body       *ast.BlockStatement
 	Env        *Environment
 }
@@ -76,14 +167,8 @@ func (f *NormalFunction) Type() ObjectType { return FUNCTION_OBJ }
 func (f *NormalFunction) Inspect() string {
 	var sb strings.Builder
 
-	var params []string
-	for _, p := range f.Parameters {
-		params = append(params, p.String())
-	}
-
-	sb.WriteString("(")
-	sb.WriteString(strings.Join(params, ", "))
-	sb.WriteString(") => {\n")
+	sb.WriteString(ast.ParametersString(f.Parameters))
+	sb.WriteString(" => {\n")
 	sb.WriteString(f.Body.String())
 	sb.WriteString("\n}")
 
@@ -91,7 +176,7 @@ func (f *NormalFunction) Inspect() string {
 }
 
 type ConciseFunction struct {
-	Parameters []*ast.Identifier
+	Parameters []*ast.Parameter
 	Body       ast.Expression
 	Env        *Environment
 }
@@ -100,20 +185,146 @@ func (f *ConciseFunction) Type() ObjectType { return FUNCTION_OBJ }
 func (f *ConciseFunction) Inspect() string {
 	var sb strings.Builder
 
-	var params []string
-	for _, p := range f.Parameters {
-		params = append(params, p.String())
-	}
-
-	sb.WriteString("(")
-	sb.WriteString(strings.Join(params, ", "))
-	sb.WriteString(") => ")
+	sb.WriteString(ast.ParametersString(f.Parameters))
+	sb.WriteString(" => ")
 	sb.WriteString(f.Body.String())
 
 	return sb.String()
 }
 
+// MemoFunction is a user function with a cache of its results, keyed on the
+// values of the arguments. Checked reports whether the function was checked at
+// its first call, when the names bound after memo are known.
+type MemoFunction struct {
+	Function Object
+	Cache    map[string]Object
+	Checked  bool
+}
+
+func (f *MemoFunction) Type() ObjectType { return FUNCTION_OBJ }
+func (f *MemoFunction) Inspect() string  { return "memo(" + f.Function.Inspect() + ")" }
+
+// PartialFunction is a function with some of its arguments given, by a call
+// of a user function without all of its arguments or by `partial`. Open are
+// the parameters still to be given, nil if they are unknown.
+type PartialFunction struct {
+	Function  Object
+	Arguments []Object
+	Open      []*ast.Parameter
+}
+
+func (f *PartialFunction) Type() ObjectType { return FUNCTION_OBJ }
+func (f *PartialFunction) Inspect() string {
+	var sb strings.Builder
+
+	if f.Open != nil {
+		sb.WriteString(ast.ParametersString(f.Open))
+	} else {
+		sb.WriteString("(...)")
+	}
+	sb.WriteString(" => (")
+	sb.WriteString(f.Function.Inspect())
+	sb.WriteString(")(")
+	for _, arg := range f.Arguments {
+		sb.WriteString(arg.Inspect())
+		sb.WriteString(", ")
+	}
+	sb.WriteString("...)")
+
+	return sb.String()
+}
+
+// ComposedFunction is `Outer ∘ Inner`, which calls Outer with the result of
+// Inner.
+type ComposedFunction struct {
+	Outer Object
+	Inner Object
+}
+
+func (f *ComposedFunction) Type() ObjectType { return FUNCTION_OBJ }
+func (f *ComposedFunction) Inspect() string {
+	return "(" + f.Outer.Inspect() + ") ∘ (" + f.Inner.Inspect() + ")"
+}
+
 type BuiltinFunction func(args ...Object) Object
 
 func (b BuiltinFunction) Type() ObjectType { return BUILTIN_FUNCTION_OBJ }
 func (b BuiltinFunction) Inspect() string  { return "builtin function" }
+
+type Plot struct {
+	Series []plot.Series
+}
+
+func (p *Plot) Type() ObjectType { return PLOT_OBJ }
+func (p *Plot) Inspect() string  { return plot.Braille(p.Series, plot.Width, plot.Height) }
+
+// Estimate is a numerical approximation with its error bound. It is used as a
+// number by the operators and the builtin functions.
+type Estimate struct {
+	Value float64
+	Error float64
+}
+
+func (e *Estimate) Type() ObjectType { return ESTIMATE_OBJ }
+func (e *Estimate) Inspect() string  { return DefaultNumberFormat.FormatObject(e) }
+
+// Equation is an unevaluated `left := right` equation for the solve builtins.
+type Equation struct {
+	Left  ast.Expression
+	Right ast.Expression
+	Env   *Environment
+}
+
+func (e *Equation) Type() ObjectType { return EQUATION_OBJ }
+func (e *Equation) Inspect() string  { return e.Left.String() + " := " + e.Right.String() }
+
+// Module is the namespace of an imported file, its bindings are read as
+// `lib.fn`.
+type Module struct {
+	Name string
+	File string // the absolute path of the file
+	Env  *Environment
+}
+
+func (m *Module) Type() ObjectType { return MODULE_OBJ }
+func (m *Module) Inspect() string  { return "module " + m.Name + " (" + m.File + ")" }
+
+type List struct {
+	Elements []Object
+}
+
+func (l *List) Type() ObjectType { return LIST_OBJ }
+func (l *List) Inspect() string {
+	elements := make([]string, len(l.Elements))
+	for i, element := range l.Elements {
+		elements[i] = element.Inspect()
+	}
+	return "[" + strings.Join(elements, ", ") + "]"
+}
+
+// Polynomial has real coefficients from the constant term up, without
+// trailing zeros, e.g. 3x^2 - 2x + 1 is [1, -2, 3].
+type Polynomial struct {
+	Coeffs []float64
+}
+
+func (p *Polynomial) Type() ObjectType { return POLYNOMIAL_OBJ }
+func (p *Polynomial) Inspect() string  { return FormatPolynomial(p.Coeffs, sprint) }
+
+type Complex struct {
+	Value complex128
+}
+
+func (c *Complex) Type() ObjectType { return COMPLEX_OBJ }
+func (c *Complex) Inspect() string  { return FormatComplex(c.Value, sprint) }
+
+// Matrix has rows of the same length, a list literal of number lists of the
+// same length is a matrix, e.g. [[1, 2], [3, 4]].
+type Matrix struct {
+	Rows [][]float64
+}
+
+func (m *Matrix) Type() ObjectType { return MATRIX_OBJ }
+func (m *Matrix) Inspect() string  { return FormatMatrix(m.Rows, sprint) }
+
+func sprint(value float64) string { return DefaultNumberFormat.Format(value) }
@@ -0,0 +1,28 @@
+package object
+
+import (
+	"math/rand/v2"
+	"time"
+)
+
+// Random is a seeded source of random numbers, the same seed gives the same
+// numbers.
+type Random struct {
+	*rand.Rand
+	source *rand.PCG
+}
+
+func NewRandom(seed uint64) *Random {
+	source := rand.NewPCG(seed, seed)
+	return &Random{Rand: rand.New(source), source: source}
+}
+
+// Seed restarts the numbers from the seed.
+func (r *Random) Seed(seed uint64) {
+	r.source.Seed(seed, seed)
+}
+
+// NewTimeSeededRandom returns a generator seeded with the current time.
+func NewTimeSeededRandom() *Random {
+	return NewRandom(uint64(time.Now().UnixNano()))
+}
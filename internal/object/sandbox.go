@@ -0,0 +1,139 @@
+package object
+
+import (
+	"maps"
+	"math/rand/v2"
+)
+
+// Sandbox returns a deep copy of the environment, of its outer and module
+// environments, and of the functions and the random number generator bound in
+// them. The evaluation in the copy does not change this environment, and the
+// builtins which write files do not write them, see Sandboxed.
+func (e *Environment) Sandbox() *Environment {
+	c := &copier{
+		envs:    map[*Environment]*Environment{},
+		objects: map[Object]Object{},
+		randoms: map[*Random]*Random{},
+	}
+	return c.env(e)
+}
+
+// Sandboxed reports whether the environment is in a copy made by Sandbox.
+func (e *Environment) Sandboxed() bool {
+	for e.outer != nil {
+		e = e.outer
+	}
+	return e.sandbox
+}
+
+// Clone returns a copy of the generator, which gives the same numbers as this
+// generator without advancing it.
+func (r *Random) Clone() *Random {
+	source := *r.source
+	return &Random{Rand: rand.New(&source), source: &source}
+}
+
+// copier copies each environment, function and generator once, so the copies
+// share them as the originals do.
+type copier struct {
+	envs    map[*Environment]*Environment
+	objects map[Object]Object
+	randoms map[*Random]*Random
+}
+
+func (c *copier) env(e *Environment) *Environment {
+	if e == nil {
+		return nil
+	}
+	if copied, ok := c.envs[e]; ok {
+		return copied
+	}
+
+	copied := &Environment{
+		store:   make(map[string]Object, len(e.store)),
+		consts:  maps.Clone(e.consts),
+		block:   e.block,
+		file:    e.file,
+		sandbox: true,
+	}
+	c.envs[e] = copied
+
+	copied.outer = c.env(e.outer)
+	copied.importer = c.env(e.importer)
+	if e.random != nil {
+		if _, ok := c.randoms[e.random]; !ok {
+			c.randoms[e.random] = e.random.Clone()
+		}
+		copied.random = c.randoms[e.random]
+	}
+	for name, obj := range e.store {
+		copied.store[name] = c.object(obj)
+	}
+	return copied
+}
+
+// object returns the copy of the objects which refer to environments, the
+// other objects are values which are never changed.
+func (c *copier) object(obj Object) Object {
+	if _, ok := obj.(BuiltinFunction); ok {
+		return obj
+	}
+	if copied, ok := c.objects[obj]; ok {
+		return copied
+	}
+
+	switch obj := obj.(type) {
+	case *NormalFunction:
+		copied := &NormalFunction{Parameters: obj.Parameters, Body: obj.Body}
+		c.objects[obj] = copied
+		copied.Env = c.env(obj.Env)
+		return copied
+	case *ConciseFunction:
+		copied := &ConciseFunction{Parameters: obj.Parameters, Body: obj.Body}
+		c.objects[obj] = copied
+		copied.Env = c.env(obj.Env)
+		return copied
+	case *MemoFunction:
+		copied := &MemoFunction{Cache: maps.Clone(obj.Cache), Checked: obj.Checked}
+		c.objects[obj] = copied
+		copied.Function = c.object(obj.Function)
+		return copied
+	case *PartialFunction:
+		copied := &PartialFunction{Open: obj.Open, Arguments: make([]Object, len(obj.Arguments))}
+		c.objects[obj] = copied
+		copied.Function = c.object(obj.Function)
+		for i, arg := range obj.Arguments {
+			copied.Arguments[i] = c.object(arg)
+		}
+		return copied
+	case *ComposedFunction:
+		copied := &ComposedFunction{}
+		c.objects[obj] = copied
+		copied.Outer, copied.Inner = c.object(obj.Outer), c.object(obj.Inner)
+		return copied
+	case *NamedArgument:
+		copied := &NamedArgument{Name: obj.Name}
+		c.objects[obj] = copied
+		copied.Value = c.object(obj.Value)
+		return copied
+	case *Equation:
+		copied := &Equation{Left: obj.Left, Right: obj.Right}
+		c.objects[obj] = copied
+		copied.Env = c.env(obj.Env)
+		return copied
+	case *Module:
+		copied := &Module{Name: obj.Name, File: obj.File}
+		c.objects[obj] = copied
+		copied.Env = c.env(obj.Env)
+		return copied
+	case *List:
+		copied := &List{Elements: make([]Object, len(obj.Elements))}
+		c.objects[obj] = copied
+		for i, element := range obj.Elements {
+			copied.Elements[i] = c.object(element)
+		}
+		return copied
+	default:
+		return obj
+	}
+}
@@ -2,7 +2,9 @@ package parser
 
 import (
 	"fmt"
+	"path/filepath"
 	"strconv"
+	"strings"
 
 	"github.com/DeepAung/qcal/internal/ast"
 	"github.com/DeepAung/qcal/internal/lexer"
@@ -13,21 +15,26 @@ import (
 const (
 	_ int = iota
 	LOWEST
-	OR  // or
-	AND // and
-	EQUALS
+	EQUALS   // :=
+	OR       // or
+	AND      // and
 	COMPARE  // ==, !=, <, <=, >, >=
+	PIPE     // |>
 	SUM      // +, -
 	PRODUCT  // *, /, %
 	PREFIX   // -5, !true
 	EXPONENT // ^
 	POSTFIX  // 5!
+	COMPOSE  // f ∘ g
 	CALL     // myFunc()
+	MEMBER   // e.message
 
 	ARROW_FUNCTION
 )
 
 var precedences = map[token.TokenType]int{
+	token.EQUATION: EQUALS,
+	token.PIPE:     PIPE,
 	token.OR:       OR,
 	token.AND:      AND,
 	token.EQ:       COMPARE,
@@ -43,23 +50,29 @@ var precedences = map[token.TokenType]int{
 	token.PERCENT:  PRODUCT,
 	token.CARET:    EXPONENT,
 	token.BANG:     POSTFIX,
+	token.COMPOSE:  COMPOSE,
 	token.LPAREN:   CALL,
+	token.LBRACKET: CALL,
+	token.DOT:      MEMBER,
 
 	token.ARROW: ARROW_FUNCTION,
 }
 
 var associativity = map[int]string{
 	LOWEST:   "left",
+	EQUALS:   "left",
 	OR:       "left",
 	AND:      "left",
-	EQUALS:   "left",
 	COMPARE:  "left",
+	PIPE:     "left",
 	SUM:      "left",
 	PRODUCT:  "left",
 	PREFIX:   "left",
 	EXPONENT: "right", // !important
 	POSTFIX:  "left",
+	COMPOSE:  "right",
 	CALL:     "left",
+	MEMBER:   "left",
 }
 
 type (
@@ -90,10 +103,16 @@ func New(l *lexer.Lexer) *Parser {
 	}
 
 	p.registerPrefix(token.IDENT, p.parseIdentifier)
+	p.registerPrefix(token.REF, p.parseIdentifier)
 	p.registerPrefix(token.TRUE, p.parseBoolean)
 	p.registerPrefix(token.FALSE, p.parseBoolean)
 	p.registerPrefix(token.NUMBER, p.parseNumber)
+	p.registerPrefix(token.STRING, p.parseString)
 	p.registerPrefix(token.IF, p.parseIfExpression)
+	p.registerPrefix(token.WHILE, p.parseWhileExpression)
+	p.registerPrefix(token.FOR, p.parseForExpression)
+	p.registerPrefix(token.TRY, p.parseTryExpression)
+	p.registerPrefix(token.LBRACKET, p.parseListLiteral)
 	p.registerPrefix(token.LPAREN, p.parseGroupedExpressionOrFunctionLiteral)
 
 	p.registerPrefix(token.BANG, p.parsePrefixExpression)
@@ -102,6 +121,7 @@ func New(l *lexer.Lexer) *Parser {
 
 	p.registerInfix(token.BANG, p.parsePostfixExpression)
 
+	p.registerInfix(token.EQUATION, p.parseEquationExpression)
 	p.registerInfix(token.EQ, p.parseInfixExpression)
 	p.registerInfix(token.NOT_EQ, p.parseInfixExpression)
 	p.registerInfix(token.LT, p.parseInfixExpression)
@@ -114,7 +134,11 @@ func New(l *lexer.Lexer) *Parser {
 	p.registerInfix(token.SLASH, p.parseInfixExpression)
 	p.registerInfix(token.PERCENT, p.parseInfixExpression)
 	p.registerInfix(token.CARET, p.parseInfixExpression)
+	p.registerInfix(token.COMPOSE, p.parseInfixExpression)
+	p.registerInfix(token.DOT, p.parseInfixExpression)
+	p.registerInfix(token.PIPE, p.parsePipeExpression)
 	p.registerInfix(token.LPAREN, p.parseCallExpression)
+	p.registerInfix(token.LBRACKET, p.parseIndexExpression)
 	p.registerInfix(token.ARROW, p.parseInfixFunctionLiteral)
 
 	p.nextToken()
@@ -142,13 +166,41 @@ func (p *Parser) ParseProgram() (*ast.Program, []string) {
 	return program, p.errors
 }
 
+// ParseExpressionList parses the input as comma separated expressions, e.g.
+// `x => x^2, sin`.
+func (p *Parser) ParseExpressionList() ([]ast.Expression, []string) {
+	var exps []ast.Expression
+
+	for p.curToken.Type != token.EOF {
+		exps = append(exps, p.parseExpression(LOWEST))
+		if p.peekToken.Type != token.EOF && !p.expectPeek(token.COMMA) {
+			break
+		}
+		p.nextToken()
+	}
+
+	return exps, p.errors
+}
+
 func (p *Parser) parseStatement() ast.Statement {
 	if p.curToken.Type == token.IDENT && p.peekToken.Type == token.ASSIGN {
 		return p.parseLetStatement()
 	}
+	if _, ok := compoundOperators[p.peekToken.Type]; ok && p.curToken.Type == token.IDENT {
+		return p.parseCompoundAssignment()
+	}
 
-	if p.curToken.Type == token.RETURN {
+	switch p.curToken.Type {
+	case token.LET, token.CONST:
+		return p.parseDeclaration()
+	case token.RETURN:
 		return p.parseReturnStatement()
+	case token.BREAK:
+		return p.parseBreakStatement()
+	case token.CONTINUE:
+		return p.parseContinueStatement()
+	case token.IMPORT:
+		return p.parseImportStatement()
 	}
 
 	if p.curToken.Type == token.SEMICOLON {
@@ -189,6 +241,99 @@ func (p *Parser) parseReturnStatement() *ast.ReturnStatement {
 	return stmt
 }
 
+// parseDeclaration parses the `let` and `const` declarations.
+func (p *Parser) parseDeclaration() *ast.LetStatement {
+	tok := p.curToken
+	if !p.expectPeek(token.IDENT) {
+		return nil
+	}
+
+	stmt := p.parseLetStatement()
+	if stmt == nil {
+		return nil
+	}
+	stmt.Token = tok
+	stmt.Declare = true
+	stmt.Constant = tok.Type == token.CONST
+	return stmt
+}
+
+// compoundOperators are the infix operators of the compound assignments.
+var compoundOperators = map[token.TokenType]token.Token{
+	token.PLUS_ASSIGN:     {Type: token.PLUS, Literal: "+"},
+	token.MINUS_ASSIGN:    {Type: token.MINUS, Literal: "-"},
+	token.ASTERISK_ASSIGN: {Type: token.ASTERISK, Literal: "*"},
+	token.SLASH_ASSIGN:    {Type: token.SLASH, Literal: "/"},
+}
+
+// parseCompoundAssignment parses `x += 1` as the assignment `x = x + 1`.
+func (p *Parser) parseCompoundAssignment() *ast.LetStatement {
+	stmt := &ast.LetStatement{Token: p.curToken}
+	stmt.Name = &ast.Identifier{Token: p.curToken, Value: p.curToken.Literal}
+
+	p.nextToken()
+	operator := compoundOperators[p.curToken.Type]
+	p.nextToken()
+
+	stmt.Value = &ast.InfixExpression{
+		Token:    operator,
+		Operator: operator.Literal,
+		Left:     stmt.Name,
+		Right:    p.parseExpression(LOWEST),
+	}
+
+	if p.peekToken.Type == token.SEMICOLON {
+		p.nextToken()
+	}
+
+	return stmt
+}
+
+func (p *Parser) parseBreakStatement() *ast.BreakStatement {
+	stmt := &ast.BreakStatement{Token: p.curToken}
+
+	if p.peekToken.Type == token.SEMICOLON {
+		p.nextToken()
+	}
+
+	return stmt
+}
+
+func (p *Parser) parseContinueStatement() *ast.ContinueStatement {
+	stmt := &ast.ContinueStatement{Token: p.curToken}
+
+	if p.peekToken.Type == token.SEMICOLON {
+		p.nextToken()
+	}
+
+	return stmt
+}
+
+func (p *Parser) parseImportStatement() *ast.ImportStatement {
+	stmt := &ast.ImportStatement{Token: p.curToken}
+
+	if !p.expectPeek(token.STRING) {
+		return nil
+	}
+	stmt.Path = p.curToken.Literal
+
+	// the module name is the file name without its extension
+	base := filepath.Base(stmt.Path)
+	name := strings.TrimSuffix(base, filepath.Ext(base))
+	if tok := lexer.New(name).NextToken(); tok.Type != token.IDENT || tok.Literal != name {
+		p.errors = append(p.errors, fmt.Sprintf("invalid module name %q of %q, expect an identifier", name, stmt.Path))
+		return nil
+	}
+	nameToken := token.Token{Type: token.IDENT, Literal: name, Line: p.curToken.Line, Column: p.curToken.Column}
+	stmt.Name = &ast.Identifier{Token: nameToken, Value: name}
+
+	if p.peekToken.Type == token.SEMICOLON {
+		p.nextToken()
+	}
+
+	return stmt
+}
+
 func (p *Parser) parseExpressionStatement() *ast.ExpressionStatement {
 	stmt := &ast.ExpressionStatement{Token: p.curToken}
 	stmt.Expression = p.parseExpression(LOWEST)
@@ -253,16 +398,67 @@ func (p *Parser) parseNumber() ast.Expression {
 	return lit
 }
 
+func (p *Parser) parseString() ast.Expression {
+	return &ast.StringLiteral{Token: p.curToken, Value: p.curToken.Literal}
+}
+
 func (p *Parser) parseIfExpression() ast.Expression {
 	exp := &ast.IfExpression{Token: p.curToken}
 
+	exp.Condition = p.parseCondition()
+	if exp.Condition == nil || !p.expectPeek(token.LBRACE) {
+		return nil
+	}
+
+	exp.Consequence = p.parseBlockStatement()
+
+	if p.peekToken.Type != token.ELSE {
+		return exp
+	}
 	p.nextToken()
-	if p.curToken.Type != token.LPAREN {
-		exp.Condition = p.parseExpression(LOWEST)
-	} else {
-		p.nextToken()
-		exp.Condition = p.parseExpression(LOWEST)
+	if !p.expectPeek(token.LBRACE) {
+		return nil
+	}
+
+	exp.Alternative = p.parseBlockStatement()
+
+	return exp
+}
+
+func (p *Parser) parseWhileExpression() ast.Expression {
+	exp := &ast.WhileExpression{Token: p.curToken}
+
+	exp.Condition = p.parseCondition()
+	if exp.Condition == nil || !p.expectPeek(token.LBRACE) {
+		return nil
+	}
+
+	exp.Body = p.parseBlockStatement()
+
+	return exp
+}
 
+func (p *Parser) parseTryExpression() ast.Expression {
+	exp := &ast.TryExpression{Token: p.curToken}
+
+	if !p.expectPeek(token.LBRACE) {
+		return nil
+	}
+	exp.Body = p.parseBlockStatement()
+
+	if !p.expectPeek(token.CATCH) {
+		return nil
+	}
+	switch p.peekToken.Type {
+	case token.IDENT:
+		p.nextToken()
+		exp.Variable = &ast.Identifier{Token: p.curToken, Value: p.curToken.Literal}
+	case token.LPAREN:
+		p.nextToken()
+		if !p.expectPeek(token.IDENT) {
+			return nil
+		}
+		exp.Variable = &ast.Identifier{Token: p.curToken, Value: p.curToken.Literal}
 		if !p.expectPeek(token.RPAREN) {
 			return nil
 		}
@@ -271,22 +467,51 @@ func (p *Parser) parseIfExpression() ast.Expression {
 	if !p.expectPeek(token.LBRACE) {
 		return nil
 	}
+	exp.Handler = p.parseBlockStatement()
 
-	exp.Consequence = p.parseBlockStatement()
+	return exp
+}
 
-	if p.peekToken.Type != token.ELSE {
-		return exp
+func (p *Parser) parseForExpression() ast.Expression {
+	exp := &ast.ForExpression{Token: p.curToken}
+
+	if !p.expectPeek(token.IDENT) {
+		return nil
+	}
+	exp.Variable = &ast.Identifier{Token: p.curToken, Value: p.curToken.Literal}
+
+	if !p.expectPeek(token.IN) {
+		return nil
 	}
 	p.nextToken()
-	if !p.expectPeek(token.LBRACE) {
+	exp.Iterable = p.parseExpression(LOWEST)
+
+	if exp.Iterable == nil || !p.expectPeek(token.LBRACE) {
 		return nil
 	}
 
-	exp.Alternative = p.parseBlockStatement()
+	exp.Body = p.parseBlockStatement()
 
 	return exp
 }
 
+// parseCondition parses the condition after the `if` or `while` token, which
+// may be wrapped in parentheses.
+func (p *Parser) parseCondition() ast.Expression {
+	p.nextToken()
+	if p.curToken.Type != token.LPAREN {
+		return p.parseExpression(LOWEST)
+	}
+
+	p.nextToken()
+	condition := p.parseExpression(LOWEST)
+
+	if !p.expectPeek(token.RPAREN) {
+		return nil
+	}
+	return condition
+}
+
 func (p *Parser) parseBlockStatement() *ast.BlockStatement {
 	block := &ast.BlockStatement{
 		Token:      p.curToken,
@@ -336,8 +561,12 @@ func (p *Parser) parseGroupedExpressionOrFunctionLiteral() ast.Expression {
 		return nil
 	}
 
+	if p.peekToken.Type == token.ELLIPSIS {
+		return p.parseFunctionLiteral()
+	}
+
 	if p.peekToken.Type == token.IDENT {
-		if p.peek2Token.Type == token.COMMA {
+		if p.peek2Token.Type == token.COMMA || p.peek2Token.Type == token.ASSIGN {
 			return p.parseFunctionLiteral()
 		}
 
@@ -356,20 +585,32 @@ func (p *Parser) parseGroupedExpressionOrFunctionLiteral() ast.Expression {
 }
 
 func (p *Parser) parseFunctionLiteral() ast.Expression {
-	parameters := p.parseFunctionParameters()
-
-	p.nextToken()
+	parameters, ok := p.parseFunctionParameters()
+	if !ok && p.peekToken.Type != token.ARROW {
+		return nil
+	}
+	if !p.expectPeek(token.ARROW) {
+		return nil
+	}
 	tok := p.curToken // the arrow token
 
+	var literal ast.Expression
 	if p.peekToken.Type == token.LBRACE {
 		p.nextToken()
 		body := p.parseBlockStatement()
-		return &ast.NormalFunctionLiteral{Token: tok, Parameters: parameters, Body: body}
+		literal = &ast.NormalFunctionLiteral{Token: tok, Parameters: parameters, Body: body}
+	} else {
+		p.nextToken()
+		body := p.parseExpression(LOWEST)
+		literal = &ast.ConciseFunctionLiteral{Token: tok, Parameters: parameters, Body: body}
 	}
 
-	p.nextToken()
-	body := p.parseExpression(LOWEST)
-	return &ast.ConciseFunctionLiteral{Token: tok, Parameters: parameters, Body: body}
+	// the body of invalid parameters is parsed too, so that their error is
+	// the only one
+	if !ok {
+		return nil
+	}
+	return literal
 }
 
 func (p *Parser) parseInfixFunctionLiteral(left ast.Expression) ast.Expression {
@@ -377,7 +618,7 @@ func (p *Parser) parseInfixFunctionLiteral(left ast.Expression) ast.Expression {
 	if !ok {
 		return nil
 	}
-	parameters := []*ast.Identifier{param}
+	parameters := []*ast.Parameter{{Name: param}}
 
 	tok := p.curToken
 
@@ -392,27 +633,96 @@ func (p *Parser) parseInfixFunctionLiteral(left ast.Expression) ast.Expression {
 	return &ast.ConciseFunctionLiteral{Token: tok, Parameters: parameters, Body: body}
 }
 
-func (p *Parser) parseFunctionParameters() []*ast.Identifier {
-	idents := make([]*ast.Identifier, 0)
+// parseFunctionParameters parses the parameter list up to its `)`, it reports
+// false after the first error of the parameters.
+func (p *Parser) parseFunctionParameters() ([]*ast.Parameter, bool) {
+	params := make([]*ast.Parameter, 0)
 
 	if p.peekToken.Type == token.RPAREN {
 		p.nextToken()
-		return idents
+		return params, true
 	}
-	p.nextToken()
 
-	idents = append(idents, &ast.Identifier{Token: p.curToken, Value: p.curToken.Literal})
-	for p.peekToken.Type == token.COMMA {
+	for {
 		p.nextToken()
+		param := p.parseParameter()
+		if param == nil {
+			p.skipParameters()
+			return nil, false
+		}
+		params = append(params, param)
+
+		if p.peekToken.Type != token.COMMA {
+			break
+		}
 		p.nextToken()
-		idents = append(idents, &ast.Identifier{Token: p.curToken, Value: p.curToken.Literal})
 	}
 
 	if !p.expectPeek(token.RPAREN) {
+		p.skipParameters()
+		return nil, false
+	}
+
+	names := make(map[string]bool, len(params))
+	for i, param := range params {
+		if names[param.Name.Value] {
+			p.errors = append(p.errors, fmt.Sprintf("duplicate parameter %s", param.Name.Value))
+			return nil, false
+		}
+		names[param.Name.Value] = true
+
+		if param.Variadic && i != len(params)-1 {
+			p.errors = append(p.errors, fmt.Sprintf("variadic parameter %s should be the last parameter", param))
+			return nil, false
+		}
+		if i > 0 && param.Default == nil && !param.Variadic && params[i-1].Default != nil {
+			p.errors = append(p.errors, fmt.Sprintf("parameter %s without a default value follows %s", param, params[i-1]))
+			return nil, false
+		}
+	}
+
+	return params, true
+}
+
+// skipParameters skips the tokens of an invalid parameter list up to its `)`.
+func (p *Parser) skipParameters() {
+	depth := 0
+	for p.curToken.Type != token.EOF && (p.curToken.Type != token.RPAREN || depth > 0) {
+		switch p.curToken.Type {
+		case token.LPAREN:
+			depth++
+		case token.RPAREN:
+			depth--
+		}
+		p.nextToken()
+	}
+}
+
+// parseParameter parses `x`, `x = <default>` or `...xs`.
+func (p *Parser) parseParameter() *ast.Parameter {
+	param := &ast.Parameter{}
+
+	if p.curToken.Type == token.ELLIPSIS {
+		param.Variadic = true
+		p.nextToken()
+	}
+
+	if p.curToken.Type != token.IDENT {
+		p.errors = append(p.errors, fmt.Sprintf("expect parameter name, got %s %q", p.curToken.Type, p.curToken.Literal))
 		return nil
 	}
+	param.Name = &ast.Identifier{Token: p.curToken, Value: p.curToken.Literal}
 
-	return idents
+	if !param.Variadic && p.peekToken.Type == token.ASSIGN {
+		p.nextToken()
+		p.nextToken()
+		param.Default = p.parseExpression(LOWEST)
+		if param.Default == nil {
+			return nil
+		}
+	}
+
+	return param
 }
 
 func (p *Parser) parseGroupedExpression() ast.Expression {
@@ -465,19 +775,63 @@ func (p *Parser) parseInfixExpression(left ast.Expression) ast.Expression {
 	return exp
 }
 
+func (p *Parser) parseEquationExpression(left ast.Expression) ast.Expression {
+	exp := &ast.EquationExpression{Token: p.curToken, Left: left}
+
+	precedence := p.curPrecedence()
+	p.nextToken()
+	exp.Right = p.parseExpression(precedence)
+
+	return exp
+}
+
 func (p *Parser) parseCallExpression(left ast.Expression) ast.Expression {
-	switch left := left.(type) {
-	case *ast.Identifier, *ast.CallExpression:
-		exp := &ast.CallExpression{Token: p.curToken, Function: left}
-		exp.Arguments = p.parseExpressionList(token.RPAREN)
-		return exp
-	default:
-		p.errors = append(p.errors, fmt.Sprintf("cannot call a function of %q", left.String()))
+	exp := &ast.CallExpression{Token: p.curToken, Function: left}
+	exp.Arguments = p.parseExpressionList(token.RPAREN, p.parseArgument)
+
+	for i := 1; i < len(exp.Arguments); i++ {
+		_, isNamed := exp.Arguments[i].(*ast.NamedArgument)
+		if _, prevNamed := exp.Arguments[i-1].(*ast.NamedArgument); prevNamed && !isNamed {
+			p.errors = append(p.errors, fmt.Sprintf("positional argument %s follows a named argument", exp.Arguments[i]))
+			return nil
+		}
+	}
+	return exp
+}
+
+// parsePipeExpression parses `x |> f` as the call `f(x)`.
+func (p *Parser) parsePipeExpression(left ast.Expression) ast.Expression {
+	tok := p.curToken
+	precedence := p.curPrecedence()
+	p.nextToken()
+
+	function := p.parseExpression(precedence)
+	if function == nil {
 		return nil
 	}
+	return &ast.CallExpression{Token: tok, Function: function, Arguments: []ast.Expression{left}}
 }
 
-func (p *Parser) parseExpressionList(end token.TokenType) []ast.Expression {
+func (p *Parser) parseIndexExpression(left ast.Expression) ast.Expression {
+	exp := &ast.IndexExpression{Token: p.curToken, Left: left}
+
+	p.nextToken()
+	exp.Index = p.parseExpression(LOWEST)
+	if !p.expectPeek(token.RBRACKET) {
+		return nil
+	}
+	return exp
+}
+
+func (p *Parser) parseListLiteral() ast.Expression {
+	list := &ast.ListLiteral{Token: p.curToken}
+	list.Elements = p.parseExpressionList(token.RBRACKET, p.parseElement)
+	return list
+}
+
+// parseExpressionList parses the comma separated elements until the end
+// token, parseElement parses an element from the current token.
+func (p *Parser) parseExpressionList(end token.TokenType, parseElement func() ast.Expression) []ast.Expression {
 	args := []ast.Expression{}
 
 	if p.peekToken.Type == end {
@@ -486,12 +840,12 @@ func (p *Parser) parseExpressionList(end token.TokenType) []ast.Expression {
 	}
 	p.nextToken()
 
-	args = append(args, p.parseExpression(LOWEST))
+	args = append(args, parseElement())
 
 	for p.peekToken.Type == token.COMMA {
 		p.nextToken()
 		p.nextToken()
-		args = append(args, p.parseExpression(LOWEST))
+		args = append(args, parseElement())
 	}
 
 	if !p.expectPeek(end) {
@@ -501,6 +855,27 @@ func (p *Parser) parseExpressionList(end token.TokenType) []ast.Expression {
 	return args
 }
 
+func (p *Parser) parseElement() ast.Expression {
+	return p.parseExpression(LOWEST)
+}
+
+// parseArgument parses an argument of a call, which may be named, e.g.
+// `base = 2`.
+func (p *Parser) parseArgument() ast.Expression {
+	if p.curToken.Type != token.IDENT || p.peekToken.Type != token.ASSIGN {
+		return p.parseExpression(LOWEST)
+	}
+
+	arg := &ast.NamedArgument{Token: p.curToken}
+	arg.Name = &ast.Identifier{Token: p.curToken, Value: p.curToken.Literal}
+
+	p.nextToken()
+	p.nextToken()
+	arg.Value = p.parseExpression(LOWEST)
+
+	return arg
+}
+
 // Helper functions ----------------------------------------------------------------- //
 
 func (p *Parser) nextToken() {
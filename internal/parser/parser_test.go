@@ -36,6 +36,71 @@ func TestLetStatement(t *testing.T) {
 	}
 }
 
+func TestConstStatement(t *testing.T) {
+	input := "const g = 9.81; x = g;"
+
+	l := lexer.New(input)
+	p := New(l)
+	program, errors := p.ParseProgram()
+	checkParserErrors(t, errors)
+
+	if len(program.Statements) != 2 {
+		t.Fatalf("invalid program.Statements length, expect=2, got=%d", len(program.Statements))
+	}
+	for i, constant := range []bool{true, false} {
+		stmt, ok := program.Statements[i].(*ast.LetStatement)
+		if !ok {
+			t.Fatalf("invalid program.Statements[%d] type, expect=*ast.LetStatement, got=%T", i, program.Statements[i])
+		}
+		if stmt.Constant != constant {
+			t.Fatalf("invalid program.Statements[%d].Constant, expect=%t, got=%t", i, constant, stmt.Constant)
+		}
+	}
+	if program.String() != "const g = 9.81;x = g;" {
+		t.Fatalf("invalid program.String(), got=%q", program.String())
+	}
+
+	_, errors = New(lexer.New("const 5 = x")).ParseProgram()
+	if len(errors) == 0 {
+		t.Fatalf("expect an error of a constant without a name")
+	}
+}
+
+func TestDeclarationAndAssignment(t *testing.T) {
+	tests := []struct {
+		input   string
+		expect  string
+		declare bool
+	}{
+		{"let x = 5", "let x = 5;", true},
+		{"x = 5", "x = 5;", false},
+		{"x += 2 * y", "x = (x + (2 * y));", false},
+		{"x -= 1;", "x = (x - 1);", false},
+		{"x *= -y", "x = (x * (-y));", false},
+		{"x /= 2 + 1", "x = (x / (2 + 1));", false},
+	}
+
+	for _, tt := range tests {
+		program, errors := New(lexer.New(tt.input)).ParseProgram()
+		checkParserErrors(t, errors)
+		testProgramStatement(t, program, &ast.LetStatement{})
+
+		stmt := program.Statements[0].(*ast.LetStatement)
+		if stmt.Declare != tt.declare {
+			t.Fatalf("input %q: invalid stmt.Declare, expect=%t, got=%t", tt.input, tt.declare, stmt.Declare)
+		}
+		if stmt.String() != tt.expect {
+			t.Fatalf("input %q: invalid stmt.String(), expect=%q, got=%q", tt.input, tt.expect, stmt.String())
+		}
+	}
+
+	for _, input := range []string{"let x += 1", "let 5 = x"} {
+		if _, errors := New(lexer.New(input)).ParseProgram(); len(errors) == 0 {
+			t.Fatalf("input %q: expect a parser error", input)
+		}
+	}
+}
+
 func TestReturnStatement(t *testing.T) {
 	tests := []struct {
 		input         string
@@ -66,6 +131,52 @@ func TestReturnStatement(t *testing.T) {
 	}
 }
 
+func TestImportStatement(t *testing.T) {
+	tests := []struct {
+		input  string
+		path   string
+		name   string
+		expect string
+	}{
+		{`import "lib.qcal"`, "lib.qcal", "lib", `import "lib.qcal";`},
+		{`import "../shared/units.qcal";`, "../shared/units.qcal", "units", `import "../shared/units.qcal";`},
+		{`import "/tmp/stats"`, "/tmp/stats", "stats", `import "/tmp/stats";`},
+	}
+
+	for _, tt := range tests {
+		l := lexer.New(tt.input)
+		p := New(l)
+		program, errors := p.ParseProgram()
+		checkParserErrors(t, errors)
+		testProgramStatement(t, program, &ast.ImportStatement{})
+
+		stmt := program.Statements[0].(*ast.ImportStatement)
+		if stmt.Path != tt.path {
+			t.Fatalf("invalid stmt.Path, expect=%q, got=%q", tt.path, stmt.Path)
+		}
+		testIdentifier(t, stmt.Name, tt.name)
+		if stmt.String() != tt.expect {
+			t.Fatalf("invalid stmt.String(), expect=%q, got=%q", tt.expect, stmt.String())
+		}
+	}
+
+	errorTests := []struct {
+		input    string
+		expected string
+	}{
+		{"import lib", "expect next token to be STRING, got IDENT instead"},
+		{`import "my-lib.qcal"`, `invalid module name "my-lib" of "my-lib.qcal", expect an identifier`},
+		{`import "if.qcal"`, `invalid module name "if" of "if.qcal", expect an identifier`},
+	}
+
+	for _, tt := range errorTests {
+		_, errors := New(lexer.New(tt.input)).ParseProgram()
+		if len(errors) == 0 || errors[0] != tt.expected {
+			t.Fatalf("input %q: invalid errors, expect=%q, got=%q", tt.input, tt.expected, errors)
+		}
+	}
+}
+
 func TestIdentifierExpression(t *testing.T) {
 	input := "foobar;"
 	expectedIdentifier := "foobar"
@@ -81,6 +192,28 @@ func TestIdentifierExpression(t *testing.T) {
 	testIdentifier(t, stmt.Expression, expectedIdentifier)
 }
 
+func TestRefExpression(t *testing.T) {
+	tests := []struct {
+		input  string
+		expect string
+	}{
+		{"$1;", "$1"},
+		{"$20;", "$20"},
+	}
+
+	for _, tt := range tests {
+		l := lexer.New(tt.input)
+		p := New(l)
+		program, errors := p.ParseProgram()
+		checkParserErrors(t, errors)
+		testProgramStatement(t, program, &ast.ExpressionStatement{})
+
+		stmt := program.Statements[0].(*ast.ExpressionStatement)
+
+		testIdentifier(t, stmt.Expression, tt.expect)
+	}
+}
+
 func TestNumberLiteralExpression(t *testing.T) {
 	tests := []struct {
 		input    string
@@ -114,6 +247,38 @@ func TestNumberLiteralExpression(t *testing.T) {
 	}
 }
 
+func TestStringLiteralExpression(t *testing.T) {
+	tests := []struct {
+		input  string
+		expect string
+	}{
+		{`"plot.svg";`, "plot.svg"},
+		{`"a \"b\"\n";`, "a \"b\"\n"},
+		{`"";`, ""},
+	}
+
+	for _, tt := range tests {
+		l := lexer.New(tt.input)
+		p := New(l)
+		program, errors := p.ParseProgram()
+		checkParserErrors(t, errors)
+		testProgramStatement(t, program, &ast.ExpressionStatement{})
+
+		stmt := program.Statements[0].(*ast.ExpressionStatement)
+
+		str, ok := stmt.Expression.(*ast.StringLiteral)
+		if !ok {
+			t.Fatalf("invalid expression type, expect=*ast.StringLiteral, got=%T", stmt.Expression)
+		}
+		if str.Value != tt.expect {
+			t.Fatalf("invalid string literal value, expect=%q, got=%q", tt.expect, str.Value)
+		}
+		if str.String() != tt.input[:len(tt.input)-1] {
+			t.Fatalf("invalid string literal, expect=%s, got=%s", tt.input[:len(tt.input)-1], str.String())
+		}
+	}
+}
+
 func TestBooleanLiteralExpression(t *testing.T) {
 	tests := []struct {
 		input  string
@@ -268,6 +433,69 @@ func TestInfixExpression(t *testing.T) {
 	}
 }
 
+func TestListLiteral(t *testing.T) {
+	tests := []struct {
+		input  string
+		length int
+		expect string
+	}{
+		{"[1, 2 * 3, x]", 3, "[1, (2 * 3), x]"},
+		{"[[1, 2], [3, 4]]", 2, "[[1, 2], [3, 4]]"},
+		{"[]", 0, "[]"},
+	}
+
+	for _, tt := range tests {
+		l := lexer.New(tt.input)
+		p := New(l)
+		program, errors := p.ParseProgram()
+		checkParserErrors(t, errors)
+		testProgramStatement(t, program, &ast.ExpressionStatement{})
+
+		stmt := program.Statements[0].(*ast.ExpressionStatement)
+
+		list, ok := stmt.Expression.(*ast.ListLiteral)
+		if !ok {
+			t.Fatalf("invalid expression type, expect=*ast.ListLiteral, got=%T", stmt.Expression)
+		}
+		if len(list.Elements) != tt.length {
+			t.Fatalf("invalid list length, expect=%d, got=%d", tt.length, len(list.Elements))
+		}
+		if list.String() != tt.expect {
+			t.Fatalf("invalid list literal, expect=%s, got=%s", tt.expect, list.String())
+		}
+	}
+}
+
+func TestExpressionList(t *testing.T) {
+	tests := []struct {
+		input  string
+		expect []string
+	}{
+		{"x => x^2, sin", []string{"(x) => (x ^ 2)", "sin"}},
+		{"x => max(x, 0)", []string{"(x) => max(x, 0)"}},
+		{"", nil},
+	}
+
+	for _, tt := range tests {
+		exps, errors := New(lexer.New(tt.input)).ParseExpressionList()
+		checkParserErrors(t, errors)
+
+		if len(exps) != len(tt.expect) {
+			t.Fatalf("invalid number of expressions, expect=%d, got=%d", len(tt.expect), len(exps))
+		}
+		for i, exp := range exps {
+			if exp.String() != tt.expect[i] {
+				t.Fatalf("invalid expression, expect=%s, got=%s", tt.expect[i], exp.String())
+			}
+		}
+	}
+
+	_, errors := New(lexer.New("sin cos")).ParseExpressionList()
+	if len(errors) == 0 {
+		t.Fatalf("expect a parser error for missing comma")
+	}
+}
+
 func TestOperatorPrecedence(t *testing.T) {
 	tests := []struct {
 		input  string
@@ -405,6 +633,73 @@ func TestOperatorPrecedence(t *testing.T) {
 			"add(a + b + c * d / f + g)",
 			"add((((a + b) + ((c * d) / f)) + g))",
 		},
+		// with equation
+		{
+			"x^3 - 2*x := 5 + a",
+			"(((x ^ 3) - (2 * x)) := (5 + a))",
+		},
+		{
+			"solve(x^2 := a == b, 1)",
+			"solve(((x ^ 2) := (a == b)), 1)",
+		},
+		// with call, index and pipeline
+		{
+			"(x => x * 2)(4)",
+			"((x) => (x * 2))(4)",
+		},
+		{
+			"fs[0](1) + a[i + 1]!",
+			"(fs[0](1) + (a[(i + 1)]!))",
+		},
+		{
+			"(if (a) { f } else { g })(x)",
+			"(if a { f } else { g })(x)",
+		},
+		{
+			"x + 1 |> f |> g",
+			"g(f((x + 1)))",
+		},
+		{
+			"xs |> (ys => ys[-1]) == 3",
+			"(((ys) => ys[(-1)])(xs) == 3)",
+		},
+		{
+			"x |> f(a) |> y => y * 2",
+			"((y) => (y * 2))(f(a)(x))",
+		},
+		// with composition
+		{
+			"f ∘ g ∘ h",
+			"(f ∘ (g ∘ h))",
+		},
+		{
+			"(f . g)(x) + f ∘ g(x)",
+			"((f . g)(x) + (f ∘ g(x)))",
+		},
+		{
+			"x |> f ∘ g",
+			"(f ∘ g)(x)",
+		},
+		{
+			"lib.sq . lib.units.tw . f",
+			"((lib . sq) . (((lib . units) . tw) . f))",
+		},
+		{
+			"lib.sq.tw(x)",
+			"((lib . sq) . tw)(x)",
+		},
+		{
+			"e.message + e.code",
+			"((e . message) + (e . code))",
+		},
+		{
+			"-e.position[0]",
+			"(-(e . position)[0])",
+		},
+		{
+			"try { ln(x) } catch { 0 }(1)",
+			"(try { ln(x) } catch { 0 })(1)",
+		},
 	}
 
 	for _, tt := range tests {
@@ -515,6 +810,135 @@ func TestIfElseExpression(t *testing.T) {
 	testIdentifier(t, alternative.Expression, "y")
 }
 
+func TestWhileExpression(t *testing.T) {
+	inputs := []string{"while x < y { x }", "while (x < y) { x }"}
+
+	for _, input := range inputs {
+		l := lexer.New(input)
+		p := New(l)
+		program, errors := p.ParseProgram()
+		checkParserErrors(t, errors)
+		testProgramStatement(t, program, &ast.ExpressionStatement{})
+
+		stmt := program.Statements[0].(*ast.ExpressionStatement)
+
+		exp, ok := stmt.Expression.(*ast.WhileExpression)
+		if !ok {
+			t.Fatalf(
+				"invalid stmt.Expression type, expect=*ast.WhileExpression, got=%T",
+				stmt.Expression,
+			)
+		}
+
+		testInfixExpression(t, exp.Condition, "x", "<", "y")
+
+		if len(exp.Body.Statements) != 1 {
+			t.Fatalf(
+				"invalid exp.Body.Statements length, expect=1, got=%d",
+				len(exp.Body.Statements),
+			)
+		}
+		body, ok := exp.Body.Statements[0].(*ast.ExpressionStatement)
+		if !ok {
+			t.Fatalf(
+				"invalid body statement type, expect=*ast.ExpressionStatement, got=%T",
+				exp.Body.Statements[0],
+			)
+		}
+		testIdentifier(t, body.Expression, "x")
+	}
+}
+
+func TestForExpression(t *testing.T) {
+	input := "for x in range(3) { break; continue }"
+
+	l := lexer.New(input)
+	p := New(l)
+	program, errors := p.ParseProgram()
+	checkParserErrors(t, errors)
+	testProgramStatement(t, program, &ast.ExpressionStatement{})
+
+	stmt := program.Statements[0].(*ast.ExpressionStatement)
+
+	exp, ok := stmt.Expression.(*ast.ForExpression)
+	if !ok {
+		t.Fatalf(
+			"invalid stmt.Expression type, expect=*ast.ForExpression, got=%T",
+			stmt.Expression,
+		)
+	}
+
+	testIdentifier(t, exp.Variable, "x")
+
+	call, ok := exp.Iterable.(*ast.CallExpression)
+	if !ok {
+		t.Fatalf("invalid exp.Iterable type, expect=*ast.CallExpression, got=%T", exp.Iterable)
+	}
+	testIdentifier(t, call.Function, "range")
+
+	if len(exp.Body.Statements) != 2 {
+		t.Fatalf(
+			"invalid exp.Body.Statements length, expect=2, got=%d",
+			len(exp.Body.Statements),
+		)
+	}
+	if _, ok := exp.Body.Statements[0].(*ast.BreakStatement); !ok {
+		t.Fatalf(
+			"invalid body statement type, expect=*ast.BreakStatement, got=%T",
+			exp.Body.Statements[0],
+		)
+	}
+	if _, ok := exp.Body.Statements[1].(*ast.ContinueStatement); !ok {
+		t.Fatalf(
+			"invalid body statement type, expect=*ast.ContinueStatement, got=%T",
+			exp.Body.Statements[1],
+		)
+	}
+
+	if exp.String() != "for x in range(3) { break; continue; }" {
+		t.Fatalf("invalid exp.String(), got=%q", exp.String())
+	}
+}
+
+func TestTryExpression(t *testing.T) {
+	tests := []struct {
+		input    string
+		variable string
+		expect   string
+	}{
+		{"try { ln(x) } catch e { e.message }", "e", "try { ln(x) } catch e { (e . message) }"},
+		{"try { ln(x) } catch (e) { 0 }", "e", "try { ln(x) } catch e { 0 }"},
+		{"try { ln(x) } catch { 0 }", "", "try { ln(x) } catch { 0 }"},
+	}
+
+	for _, tt := range tests {
+		l := lexer.New(tt.input)
+		p := New(l)
+		program, errors := p.ParseProgram()
+		checkParserErrors(t, errors)
+		testProgramStatement(t, program, &ast.ExpressionStatement{})
+
+		stmt := program.Statements[0].(*ast.ExpressionStatement)
+		exp, ok := stmt.Expression.(*ast.TryExpression)
+		if !ok {
+			t.Fatalf(
+				"invalid stmt.Expression type, expect=*ast.TryExpression, got=%T",
+				stmt.Expression,
+			)
+		}
+		if tt.variable == "" {
+			if exp.Variable != nil {
+				t.Fatalf("invalid exp.Variable, expect=nil, got=%q", exp.Variable.Value)
+			}
+		} else {
+			testIdentifier(t, exp.Variable, tt.variable)
+		}
+		if exp.String() != tt.expect {
+			t.Fatalf("invalid exp.String(), expect=%q, got=%q", tt.expect, exp.String())
+		}
+	}
+}
+
 func TestNormalFunctionLiteral(t *testing.T) {
 	tests := []struct {
 		input      string
@@ -524,10 +948,13 @@ func TestNormalFunctionLiteral(t *testing.T) {
 		{"() => {}", []string{}, ""},
 		{"(i) => {}", []string{"i"}, ""},
 		{"(i, j, k) => {}", []string{"i", "j", "k"}, ""},
+		{"(x, base = 10) => {}", []string{"x", "base = 10"}, ""},
+		{"(...xs) => {}", []string{"...xs"}, ""},
+		{"(a, b = a * 2, ...cs) => {}", []string{"a", "b = (a * 2)", "...cs"}, ""},
 		{"i => {}", []string{"i"}, ""},
 		{"i => { i + 1 }", []string{"i"}, "(i + 1)"},
 		{"i => { i + 1; };", []string{"i"}, "(i + 1)"},
-		{"i => { i + 1; i }", []string{"i"}, "(i + 1)i"},
+		{"i => { i + 1; i }", []string{"i"}, "(i + 1); i"},
 	}
 
 	for _, tt := range tests {
@@ -657,6 +1084,65 @@ func TestCallExpression(t *testing.T) {
 	testInfixExpression(t, exp.Arguments[2], 4, "+", 5)
 }
 
+func TestFunctionParametersError(t *testing.T) {
+	tests := []struct {
+		input    string
+		expected string
+	}{
+		{"(...xs, y) => xs", "variadic parameter ...xs should be the last parameter"},
+		{"(x = 1, y) => x", "parameter y without a default value follows x = 1"},
+		{"(x, 1) => x", `expect parameter name, got NUMBER "1"`},
+		{"(x, y) + 1", "expect next token to be =>, got + instead"},
+		{"(x, x) => x", "duplicate parameter x"},
+		{"(x, y = 1, ...x) => x", "duplicate parameter x"},
+		{"((x, ...ys, z) => x)(1)", "variadic parameter ...ys should be the last parameter"},
+		{"f = (a, (b)) => a; g = 1", `expect parameter name, got ( "("`},
+		{"g = (...xs = [1]) => xs", "expect next token to be ), got = instead"},
+	}
+
+	// the first error of the parameters is the only one
+	for _, tt := range tests {
+		l := lexer.New(tt.input)
+		p := New(l)
+		_, errors := p.ParseProgram()
+
+		if len(errors) != 1 || errors[0] != tt.expected {
+			t.Fatalf("input %q: invalid errors, expect=%q, got=%q", tt.input, tt.expected, errors)
+		}
+	}
+}
+
+func TestNamedArguments(t *testing.T) {
+	input := "log(x = 8, base = 2)"
+
+	l := lexer.New(input)
+	p := New(l)
+	program, errors := p.ParseProgram()
+	checkParserErrors(t, errors)
+	testProgramStatement(t, program, &ast.ExpressionStatement{})
+
+	exp := program.Statements[0].(*ast.ExpressionStatement).Expression.(*ast.CallExpression)
+
+	if len(exp.Arguments) != 2 {
+		t.Fatalf("invalid exp.Arguments length, expect=2, got=%d", len(exp.Arguments))
+	}
+	for i, name := range []string{"x", "base"} {
+		arg, ok := exp.Arguments[i].(*ast.NamedArgument)
+		if !ok {
+			t.Fatalf("invalid exp.Arguments[%d] type, expect=*ast.NamedArgument, got=%T", i, exp.Arguments[i])
+		}
+		testIdentifier(t, arg.Name, name)
+	}
+	if exp.String() != input {
+		t.Fatalf("invalid exp.String(), expect=%q, got=%q", input, exp.String())
+	}
+
+	_, errors = New(lexer.New("f(x = 1, 2)")).ParseProgram()
+	if len(errors) == 0 || errors[0] != "positional argument 2 follows a named argument" {
+		t.Fatalf("invalid errors of a positional argument after a named one, got=%q", errors)
+	}
+}
+
 func TestNestedCallExpression(t *testing.T) {
 	input := "add(1)(4);"
 
@@ -0,0 +1,69 @@
+package plot
+
+const brailleBlank = '⠀'
+
+// brailleDots maps the dot position [col][row] in a braille character to its bit.
+var brailleDots = [dotsPerColumn][dotsPerRow]rune{
+	{0x01, 0x02, 0x04, 0x40},
+	{0x08, 0x10, 0x20, 0x80},
+}
+
+// canvas is a grid of braille characters, each character has 2x4 dots.
+type canvas struct {
+	width   int
+	height  int
+	cells   [][]rune
+	markers map[[2]int]rune
+}
+
+func newCanvas(width, height int) *canvas {
+	cells := make([][]rune, height)
+	for i := range cells {
+		cells[i] = make([]rune, width)
+		for j := range cells[i] {
+			cells[i][j] = brailleBlank
+		}
+	}
+
+	return &canvas{
+		width:   width,
+		height:  height,
+		cells:   cells,
+		markers: map[[2]int]rune{},
+	}
+}
+
+func (c *canvas) dotWidth() int  { return c.width * dotsPerColumn }
+func (c *canvas) dotHeight() int { return c.height * dotsPerRow }
+
+// set turns on the dot, dots outside the canvas are ignored.
+func (c *canvas) set(col, row int) {
+	if col < 0 || col >= c.dotWidth() || row < 0 || row >= c.dotHeight() {
+		return
+	}
+	c.cells[row/dotsPerRow][col/dotsPerColumn] |= brailleDots[col%dotsPerColumn][row%dotsPerRow]
+}
+
+// mark replaces the character containing the dot with the marker.
+func (c *canvas) mark(col, row int, marker rune) {
+	if col < 0 || col >= c.dotWidth() || row < 0 || row >= c.dotHeight() {
+		return
+	}
+	c.markers[[2]int{row / dotsPerRow, col / dotsPerColumn}] = marker
+}
+
+func (c *canvas) lines() []string {
+	lines := make([]string, c.height)
+	for i, row := range c.cells {
+		line := make([]rune, len(row))
+		for j, cell := range row {
+			if marker, ok := c.markers[[2]int{i, j}]; ok {
+				line[j] = marker
+			} else {
+				line[j] = cell
+			}
+		}
+		lines[i] = string(line)
+	}
+	return lines
+}
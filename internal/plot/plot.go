@@ -0,0 +1,236 @@
+package plot
+
+import (
+	"math"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+const (
+	Width  = 60 // the default chart width in characters
+	Height = 15 // the default chart height in characters
+
+	// each braille character has 2x4 dots
+	dotsPerColumn = 2
+	dotsPerRow    = 4
+
+	rootMarker    = 'o'
+	maximumMarker = '^'
+	minimumMarker = 'v'
+
+	// poleFactor is how much larger than the typical magnitude of a function
+	// the values around a sign change are at a pole.
+	poleFactor = 10
+)
+
+// Series is a function sampled at evenly spaced x values.
+type Series struct {
+	Label string
+	Xs    []float64
+	Ys    []float64
+}
+
+// Samples returns the number of samples needed to draw one series on a chart
+// of the given width.
+func Samples(width int) int {
+	return width * dotsPerColumn
+}
+
+// Xs returns n evenly spaced x values from xmin to xmax.
+func Xs(xmin, xmax float64, n int) []float64 {
+	xs := make([]float64, n)
+	for i := range xs {
+		xs[i] = xmin + (xmax-xmin)*float64(i)/float64(n-1)
+	}
+	return xs
+}
+
+// Braille draws the series on a braille chart with axes, marking the roots
+// with `o`, the local maxima with `^` and the local minima with `v`.
+func Braille(series []Series, width, height int) string {
+	xmin, xmax := xRange(series)
+	ymin, ymax := yRange(series)
+
+	c := newCanvas(width, height)
+	toCol := func(x float64) int {
+		return int(math.Round((x - xmin) / (xmax - xmin) * float64(c.dotWidth()-1)))
+	}
+	toRow := func(y float64) int {
+		return int(math.Round((ymax - y) / (ymax - ymin) * float64(c.dotHeight()-1)))
+	}
+
+	// dotted zero lines
+	if ymin < 0 && 0 < ymax {
+		row := toRow(0)
+		for col := 0; col < c.dotWidth(); col += 2 {
+			c.set(col, row)
+		}
+	}
+	if xmin < 0 && 0 < xmax {
+		col := toCol(0)
+		for row := 0; row < c.dotHeight(); row += 2 {
+			c.set(col, row)
+		}
+	}
+
+	for _, s := range series {
+		scale := magnitude(s.Ys)
+		for i := range s.Xs {
+			if !isFinite(s.Ys[i]) {
+				continue
+			}
+			col, row := toCol(s.Xs[i]), toRow(s.Ys[i])
+			c.set(col, row)
+
+			// connect to the next sample so steep curves stay continuous,
+			// but not across a pole
+			if i+1 < len(s.Xs) && isFinite(s.Ys[i+1]) && !isPole(s.Ys[i], s.Ys[i+1], scale) {
+				next := toRow(s.Ys[i+1])
+				for r := min(row, next) + 1; r < max(row, next); r++ {
+					c.set(col, r)
+				}
+			}
+		}
+	}
+
+	for _, s := range series {
+		scale := magnitude(s.Ys)
+		for i := range s.Xs {
+			if marker, ok := markerAt(s.Ys, i, scale); ok {
+				c.mark(toCol(s.Xs[i]), toRow(s.Ys[i]), marker)
+			}
+		}
+	}
+
+	return frame(c, series, xmin, xmax, ymin, ymax)
+}
+
+// markerAt reports whether the sample i is a root or a local extremum. The
+// sign change and the extrema at a pole are not marked, see isPole.
+func markerAt(ys []float64, i int, scale float64) (rune, bool) {
+	y := ys[i]
+	if !isFinite(y) {
+		return 0, false
+	}
+
+	if i+1 < len(ys) && isFinite(ys[i+1]) {
+		next := ys[i+1]
+		if y == 0 || (isSignChange(y, next) && !isPole(y, next, scale)) {
+			return rootMarker, true
+		}
+	} else if y == 0 {
+		return rootMarker, true
+	}
+
+	if i == 0 || i+1 == len(ys) || !isFinite(ys[i-1]) || !isFinite(ys[i+1]) {
+		return 0, false
+	}
+	prev, next := ys[i-1], ys[i+1]
+	if isPole(prev, y, scale) || isPole(y, next, scale) {
+		return 0, false
+	}
+	switch {
+	case prev < y && y >= next:
+		return maximumMarker, true
+	case prev > y && y <= next:
+		return minimumMarker, true
+	}
+	return 0, false
+}
+
+func frame(c *canvas, series []Series, xmin, xmax, ymin, ymax float64) string {
+	top, bottom := formatLabel(ymax), formatLabel(ymin)
+	gutter := max(len(top), len(bottom))
+
+	var sb strings.Builder
+	for i, line := range c.lines() {
+		label := ""
+		switch i {
+		case 0:
+			label = top
+		case len(c.cells) - 1:
+			label = bottom
+		}
+		sb.WriteString(strings.Repeat(" ", gutter-len(label)) + label + " ┤" + line + "\n")
+	}
+
+	sb.WriteString(strings.Repeat(" ", gutter+1) + "└" + strings.Repeat("─", c.width) + "\n")
+
+	left, right := formatLabel(xmin), formatLabel(xmax)
+	padding := max(1, c.width-len(left)-len(right)+1)
+	sb.WriteString(strings.Repeat(" ", gutter+2) + left + strings.Repeat(" ", padding) + right)
+
+	for i, s := range series {
+		sb.WriteString("\n[" + strconv.Itoa(i+1) + "] " + s.Label)
+	}
+
+	return sb.String()
+}
+
+func xRange(series []Series) (float64, float64) {
+	xmin, xmax := math.Inf(1), math.Inf(-1)
+	for _, s := range series {
+		for _, x := range s.Xs {
+			xmin, xmax = math.Min(xmin, x), math.Max(xmax, x)
+		}
+	}
+	return widenRange(xmin, xmax)
+}
+
+func yRange(series []Series) (float64, float64) {
+	ymin, ymax := math.Inf(1), math.Inf(-1)
+	for _, s := range series {
+		for _, y := range s.Ys {
+			if isFinite(y) {
+				ymin, ymax = math.Min(ymin, y), math.Max(ymax, y)
+			}
+		}
+	}
+	return widenRange(ymin, ymax)
+}
+
+// widenRange makes sure that the range is finite and not empty.
+func widenRange(lo, hi float64) (float64, float64) {
+	if !isFinite(lo) || !isFinite(hi) {
+		return -1, 1
+	}
+	if lo == hi {
+		return lo - 1, hi + 1
+	}
+	return lo, hi
+}
+
+func formatLabel(value float64) string {
+	return strconv.FormatFloat(value, 'g', 4, 64)
+}
+
+// isPole reports whether the function has a pole between the samples y0 and
+// y1, e.g. 1/x at 0: it changes sign between values much larger than its
+// typical magnitude, the scale.
+func isPole(y0, y1, scale float64) bool {
+	return isSignChange(y0, y1) && math.Min(math.Abs(y0), math.Abs(y1)) > poleFactor*scale
+}
+
+func isSignChange(y0, y1 float64) bool {
+	return (y0 < 0 && y1 > 0) || (y0 > 0 && y1 < 0)
+}
+
+// magnitude returns the median of the absolute finite values.
+func magnitude(ys []float64) float64 {
+	var abs []float64
+	for _, y := range ys {
+		if isFinite(y) {
+			abs = append(abs, math.Abs(y))
+		}
+	}
+	if len(abs) == 0 {
+		return 0
+	}
+	sort.Float64s(abs)
+	return abs[len(abs)/2]
+}
+
+func isFinite(value float64) bool {
+	return !math.IsNaN(value) && !math.IsInf(value, 0)
+}
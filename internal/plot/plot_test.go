@@ -0,0 +1,139 @@
+package plot
+
+import (
+	"flag"
+	"math"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+var update = flag.Bool("update", false, "update the golden files")
+
+func TestBraille(t *testing.T) {
+	sample := func(label string, f func(float64) float64, xmin, xmax float64) Series {
+		return sampleSeries(label, f, xmin, xmax, Samples(Width))
+	}
+
+	tests := []struct {
+		name   string
+		series []Series
+	}{
+		{
+			"parabola",
+			[]Series{sample("(x) => ((x ^ 2) - 3)", func(x float64) float64 { return x*x - 3 }, -3, 3)},
+		},
+		{
+			"sin_cos",
+			[]Series{
+				sample("sin", math.Sin, 0, 2*math.Pi),
+				sample("cos", math.Cos, 0, 2*math.Pi),
+			},
+		},
+		{
+			"constant",
+			[]Series{sample("(x) => 2", func(x float64) float64 { return 2 }, 1, 5)},
+		},
+		{
+			"ln",
+			[]Series{sample("ln", math.Log, -1, 4)},
+		},
+		{
+			"reciprocal",
+			[]Series{sample("(x) => (1 / x)", func(x float64) float64 { return 1 / x }, -1, 1)},
+		},
+	}
+
+	for _, tt := range tests {
+		got := Braille(tt.series, Width, Height)
+		testGolden(t, tt.name, got)
+	}
+}
+
+func TestFiles(t *testing.T) {
+	sample := func(label string, f func(float64) float64, xmin, xmax float64) Series {
+		return sampleSeries(label, f, xmin, xmax, DefaultOptions.Width)
+	}
+
+	tests := []struct {
+		name   string
+		series []Series
+	}{
+		{
+			"parabola",
+			[]Series{sample("(x) => ((x ^ 2) - 3)", func(x float64) float64 { return x*x - 3 }, -3, 3)},
+		},
+		{
+			"tan",
+			[]Series{sample("tan", math.Tan, -2*math.Pi, 2*math.Pi)},
+		},
+		{
+			"sin_cos",
+			[]Series{
+				sample("sin", math.Sin, 0, 2*math.Pi),
+				sample("cos", math.Cos, 0, 2*math.Pi),
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		testGolden(t, tt.name+".svg", string(SVG(tt.series, DefaultOptions)))
+
+		img, err := PNG(tt.series, DefaultOptions)
+		if err != nil {
+			t.Fatalf("%s - cannot encode png: %v", tt.name, err)
+		}
+		testGolden(t, tt.name+".png", string(img))
+	}
+}
+
+func TestSegments(t *testing.T) {
+	series := sampleSeries("tan", math.Tan, -2*math.Pi, 2*math.Pi, DefaultOptions.Width)
+	ymin, ymax := viewRange([]Series{series})
+
+	// tan jumps at -3pi/2, -pi/2, pi/2 and 3pi/2
+	parts := segments(series, ymin, ymax)
+	if len(parts) != 5 {
+		t.Fatalf("invalid number of segments, expect=5, got=%d", len(parts))
+	}
+
+	series = sampleSeries("(x) => (1 / x)", func(x float64) float64 { return 1 / x }, -1, 1, DefaultOptions.Width)
+	ymin, ymax = viewRange([]Series{series})
+
+	// 1/x has a pole at 0
+	parts = segments(series, ymin, ymax)
+	if len(parts) != 2 {
+		t.Fatalf("invalid number of segments, expect=2, got=%d", len(parts))
+	}
+}
+
+func sampleSeries(label string, f func(float64) float64, xmin, xmax float64, n int) Series {
+	xs := Xs(xmin, xmax, n)
+	ys := make([]float64, len(xs))
+	for i, x := range xs {
+		ys[i] = f(x)
+	}
+	return Series{Label: label, Xs: xs, Ys: ys}
+}
+
+func testGolden(t *testing.T, name string, got string) {
+	t.Helper()
+
+	if filepath.Ext(name) == "" {
+		name += ".golden"
+	}
+	path := filepath.Join("testdata", name)
+	if *update {
+		if err := os.WriteFile(path, []byte(got), 0o644); err != nil {
+			t.Fatalf("cannot update golden file: %v", err)
+		}
+	}
+
+	expect, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatalf("cannot read golden file: %v", err)
+	}
+	if got != string(expect) {
+		t.Errorf("%s - invalid output, expect=\n%s\ngot=\n%s", name, expect, got)
+	}
+}
@@ -0,0 +1,106 @@
+package plot
+
+import (
+	"bytes"
+	"image"
+	"image/color"
+	"image/draw"
+	"image/png"
+	"math"
+	"strconv"
+)
+
+var (
+	white     = color.RGBA{0xff, 0xff, 0xff, 0xff}
+	lightGray = color.RGBA{0xee, 0xee, 0xee, 0xff}
+	gray      = color.RGBA{0x99, 0x99, 0x99, 0xff}
+	darkGray  = color.RGBA{0x33, 0x33, 0x33, 0xff}
+)
+
+// PNG draws the series on a chart with axes and tick marks. The standard
+// library cannot draw text, so the PNG has no labels, unlike the SVG.
+func PNG(series []Series, opts Options) ([]byte, error) {
+	t := newTransform(series, opts)
+	img := image.NewRGBA(image.Rect(0, 0, opts.Width, opts.Height))
+	draw.Draw(img, img.Bounds(), image.NewUniform(white), image.Point{}, draw.Src)
+
+	chart := image.Rect(margin, margin, opts.Width-margin+1, opts.Height-margin+1)
+	left, top := float64(margin), float64(margin)
+	right, bottom := float64(opts.Width-margin), float64(opts.Height-margin)
+
+	for _, x := range ticks(t.xmin, t.xmax, 8) {
+		drawLine(img, chart, t.px(x), top, t.px(x), bottom, lightGray, 1)
+		drawLine(img, img.Bounds(), t.px(x), bottom, t.px(x), bottom+5, darkGray, 1)
+	}
+	for _, y := range ticks(t.ymin, t.ymax, 6) {
+		drawLine(img, chart, left, t.py(y), right, t.py(y), lightGray, 1)
+		drawLine(img, img.Bounds(), left-5, t.py(y), left, t.py(y), darkGray, 1)
+	}
+
+	if t.ymin < 0 && 0 < t.ymax {
+		drawLine(img, chart, left, t.py(0), right, t.py(0), gray, 1)
+	}
+	if t.xmin < 0 && 0 < t.xmax {
+		drawLine(img, chart, t.px(0), top, t.px(0), bottom, gray, 1)
+	}
+
+	drawLine(img, img.Bounds(), left, top, right, top, darkGray, 1)
+	drawLine(img, img.Bounds(), left, bottom, right, bottom, darkGray, 1)
+	drawLine(img, img.Bounds(), left, top, left, bottom, darkGray, 1)
+	drawLine(img, img.Bounds(), right, top, right, bottom, darkGray, 1)
+
+	for i, s := range series {
+		c := parseColor(palette[i%len(palette)])
+		for _, part := range segments(s, t.ymin, t.ymax) {
+			for j := 1; j < len(part); j++ {
+				x0, y0 := t.px(part[j-1].x), clampPixel(t.py(part[j-1].y))
+				x1, y1 := t.px(part[j].x), clampPixel(t.py(part[j].y))
+				drawLine(img, chart, x0, y0, x1, y1, c, 2)
+			}
+		}
+	}
+
+	var buf bytes.Buffer
+	if err := png.Encode(&buf, img); err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), nil
+}
+
+// drawLine draws a line with the given thickness, pixels outside clip are skipped.
+func drawLine(
+	img *image.RGBA,
+	clip image.Rectangle,
+	x0, y0, x1, y1 float64,
+	c color.RGBA,
+	thickness int,
+) {
+	steps := int(math.Max(math.Abs(x1-x0), math.Abs(y1-y0)))
+	for i := 0; i <= steps; i++ {
+		ratio := 0.0
+		if steps > 0 {
+			ratio = float64(i) / float64(steps)
+		}
+		x := int(math.Round(x0 + (x1-x0)*ratio))
+		y := int(math.Round(y0 + (y1-y0)*ratio))
+
+		for dx := 0; dx < thickness; dx++ {
+			for dy := 0; dy < thickness; dy++ {
+				if p := image.Pt(x+dx, y+dy); p.In(clip) {
+					img.SetRGBA(p.X, p.Y, c)
+				}
+			}
+		}
+	}
+}
+
+// clampPixel keeps the line length reasonable for values far outside the chart.
+func clampPixel(value float64) float64 {
+	return math.Max(-1e4, math.Min(value, 1e4))
+}
+
+// parseColor parses a "#rrggbb" color.
+func parseColor(hex string) color.RGBA {
+	value, _ := strconv.ParseUint(hex[1:], 16, 32)
+	return color.RGBA{uint8(value >> 16), uint8(value >> 8), uint8(value), 0xff}
+}
@@ -0,0 +1,148 @@
+package plot
+
+import (
+	"math"
+	"sort"
+)
+
+// Options of the plot files.
+type Options struct {
+	Width  int // in pixels
+	Height int // in pixels
+}
+
+var DefaultOptions = Options{Width: 640, Height: 400}
+
+const margin = 50 // the space around the chart for the axis labels, in pixels
+
+// palette of the series colors
+var palette = []string{"#1f77b4", "#d62728", "#2ca02c", "#ff7f0e", "#9467bd", "#8c564b"}
+
+type point struct {
+	x, y float64
+}
+
+// viewRange returns the y range to display. Values far outside the bulk of
+// the samples, e.g. tan near pi/2, are left out so they do not squash the chart.
+func viewRange(series []Series) (float64, float64) {
+	var ys []float64
+	for _, s := range series {
+		for _, y := range s.Ys {
+			if isFinite(y) {
+				ys = append(ys, y)
+			}
+		}
+	}
+	if len(ys) == 0 {
+		return -1, 1
+	}
+	sort.Float64s(ys)
+
+	lo, hi := ys[0], ys[len(ys)-1]
+	qlo, qhi := quantile(ys, 0.05), quantile(ys, 0.95)
+	if spread := qhi - qlo; spread > 0 && hi-lo > 10*spread {
+		lo, hi = qlo-spread, qhi+spread
+	}
+
+	return widenRange(lo, hi)
+}
+
+// quantile returns the q-quantile of the sorted values.
+func quantile(sorted []float64, q float64) float64 {
+	return sorted[int(math.Round(q*float64(len(sorted)-1)))]
+}
+
+// segments splits the series into continuous parts. A part ends at a value
+// that is not finite, at a jump, e.g. tan at pi/2, or at a pole, e.g. 1/x at 0.
+func segments(s Series, ylo, yhi float64) [][]point {
+	var parts [][]point
+	var current []point
+	prevDelta := 0.0
+	scale := magnitude(s.Ys)
+
+	for i := range s.Xs {
+		y := s.Ys[i]
+		if !isFinite(y) {
+			if len(current) > 0 {
+				parts = append(parts, current)
+			}
+			current = nil
+			continue
+		}
+
+		if len(current) > 0 {
+			delta := y - current[len(current)-1].y
+			y0 := current[len(current)-1].y
+			if isJump(prevDelta, delta, y0, y, ylo, yhi) || isPole(y0, y, scale) {
+				parts = append(parts, current)
+				current = nil
+				delta = 0
+			}
+			prevDelta = delta
+		}
+		current = append(current, point{s.Xs[i], y})
+	}
+
+	if len(current) > 0 {
+		parts = append(parts, current)
+	}
+	return parts
+}
+
+// isJump reports whether going from y0 to y1 is a discontinuity: it crosses
+// the whole view, or it is larger than the view and against the trend, e.g.
+// tan rising to +Inf then continuing from -Inf.
+func isJump(prevDelta, delta, y0, y1, ylo, yhi float64) bool {
+	if (y0 > yhi && y1 < ylo) || (y0 < ylo && y1 > yhi) {
+		return true
+	}
+	return math.Abs(delta) > yhi-ylo && prevDelta*delta < 0
+}
+
+// ticks returns about n evenly spaced round numbers from lo to hi.
+func ticks(lo, hi float64, n int) []float64 {
+	step := niceStep((hi - lo) / float64(n))
+	var result []float64
+	for t := math.Ceil(lo/step) * step; t <= hi+step*1e-9; t += step {
+		if math.Abs(t) < step*1e-9 {
+			t = 0
+		}
+		result = append(result, t)
+	}
+	return result
+}
+
+// niceStep rounds the step to 1, 2 or 5 times a power of 10.
+func niceStep(step float64) float64 {
+	magnitude := math.Pow(10, math.Floor(math.Log10(step)))
+	switch fraction := step / magnitude; {
+	case fraction <= 1:
+		return magnitude
+	case fraction <= 2:
+		return 2 * magnitude
+	case fraction <= 5:
+		return 5 * magnitude
+	default:
+		return 10 * magnitude
+	}
+}
+
+// transform maps chart coordinates to pixel coordinates.
+type transform struct {
+	xmin, xmax, ymin, ymax float64
+	width, height          int
+}
+
+func newTransform(series []Series, opts Options) transform {
+	xmin, xmax := xRange(series)
+	ymin, ymax := viewRange(series)
+	return transform{xmin, xmax, ymin, ymax, opts.Width, opts.Height}
+}
+
+func (t transform) px(x float64) float64 {
+	return margin + (x-t.xmin)/(t.xmax-t.xmin)*float64(t.width-2*margin)
+}
+
+func (t transform) py(y float64) float64 {
+	return float64(t.height-margin) - (y-t.ymin)/(t.ymax-t.ymin)*float64(t.height-2*margin)
+}
@@ -0,0 +1,95 @@
+package plot
+
+import (
+	"bytes"
+	"fmt"
+	"html"
+	"math"
+	"strconv"
+)
+
+// SVG draws the series on a chart with axes, ticks and a legend.
+func SVG(series []Series, opts Options) []byte {
+	t := newTransform(series, opts)
+	left, top := float64(margin), float64(margin)
+	right, bottom := float64(opts.Width-margin), float64(opts.Height-margin)
+
+	var buf bytes.Buffer
+	fmt.Fprintf(
+		&buf,
+		`<svg xmlns="http://www.w3.org/2000/svg" width="%d" height="%d" viewBox="0 0 %d %d">`+"\n",
+		opts.Width, opts.Height, opts.Width, opts.Height,
+	)
+	fmt.Fprintf(&buf, `<rect width="%d" height="%d" fill="white"/>`+"\n", opts.Width, opts.Height)
+	fmt.Fprintf(
+		&buf,
+		`<clipPath id="chart"><rect x="%s" y="%s" width="%s" height="%s"/></clipPath>`+"\n",
+		num(left), num(top), num(right-left), num(bottom-top),
+	)
+
+	// ticks and grid
+	buf.WriteString(`<g font-family="sans-serif" font-size="11" fill="#333">` + "\n")
+	for _, x := range ticks(t.xmin, t.xmax, 8) {
+		px := t.px(x)
+		fmt.Fprintf(&buf, `<line x1="%s" y1="%s" x2="%s" y2="%s" stroke="#eee"/>`+"\n", num(px), num(top), num(px), num(bottom))
+		fmt.Fprintf(&buf, `<text x="%s" y="%s" text-anchor="middle">%s</text>`+"\n", num(px), num(bottom+16), formatLabel(x))
+	}
+	for _, y := range ticks(t.ymin, t.ymax, 6) {
+		py := t.py(y)
+		fmt.Fprintf(&buf, `<line x1="%s" y1="%s" x2="%s" y2="%s" stroke="#eee"/>`+"\n", num(left), num(py), num(right), num(py))
+		fmt.Fprintf(&buf, `<text x="%s" y="%s" text-anchor="end">%s</text>`+"\n", num(left-6), num(py+4), formatLabel(y))
+	}
+	buf.WriteString("</g>\n")
+
+	// zero lines and frame
+	if t.ymin < 0 && 0 < t.ymax {
+		fmt.Fprintf(&buf, `<line x1="%s" y1="%s" x2="%s" y2="%s" stroke="#999"/>`+"\n", num(left), num(t.py(0)), num(right), num(t.py(0)))
+	}
+	if t.xmin < 0 && 0 < t.xmax {
+		fmt.Fprintf(&buf, `<line x1="%s" y1="%s" x2="%s" y2="%s" stroke="#999"/>`+"\n", num(t.px(0)), num(top), num(t.px(0)), num(bottom))
+	}
+	fmt.Fprintf(
+		&buf,
+		`<rect x="%s" y="%s" width="%s" height="%s" fill="none" stroke="#333"/>`+"\n",
+		num(left), num(top), num(right-left), num(bottom-top),
+	)
+
+	// series
+	for i, s := range series {
+		var path bytes.Buffer
+		for _, part := range segments(s, t.ymin, t.ymax) {
+			for j, p := range part {
+				command := "L"
+				if j == 0 {
+					command = "M"
+				}
+				fmt.Fprintf(&path, "%s%s %s", command, num(t.px(p.x)), num(t.py(p.y)))
+			}
+		}
+		fmt.Fprintf(
+			&buf,
+			`<path d="%s" fill="none" stroke="%s" stroke-width="2" clip-path="url(#chart)"/>`+"\n",
+			path.String(), palette[i%len(palette)],
+		)
+	}
+
+	// legend
+	for i, s := range series {
+		y := top + 16*float64(i+1)
+		fmt.Fprintf(
+			&buf,
+			`<text x="%s" y="%s" font-family="sans-serif" font-size="12" fill="%s">%s</text>`+"\n",
+			num(left+8), num(y), palette[i%len(palette)], html.EscapeString(s.Label),
+		)
+	}
+
+	buf.WriteString("</svg>\n")
+	return buf.Bytes()
+}
+
+// num formats the coordinate with 2 decimal places at most. Coordinates far
+// outside the chart are clamped, they are clipped anyway.
+func num(value float64) string {
+	value = math.Max(-1e5, math.Min(value, 1e5))
+	return strconv.FormatFloat(math.Round(value*100)/100, 'f', -1, 64)
+}
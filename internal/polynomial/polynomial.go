@@ -0,0 +1,148 @@
+// Package polynomial implements the arithmetic of polynomials with real
+// coefficients. A polynomial is its coefficients from the constant term up,
+// e.g. x^2 - 4 is [-4, 0, 1]. The results have no trailing zero coefficients,
+// the zero polynomial is empty.
+package polynomial
+
+import "math"
+
+// Trim removes the trailing zero coefficients.
+func Trim(p []float64) []float64 {
+	return p[:Degree(p)+1]
+}
+
+// Degree returns the degree of the polynomial, -1 for the zero polynomial.
+func Degree(p []float64) int {
+	for i := len(p) - 1; i >= 0; i-- {
+		if p[i] != 0 {
+			return i
+		}
+	}
+	return -1
+}
+
+// Evaluate returns the value of the polynomial at x, using Horner's method.
+func Evaluate(p []float64, x float64) float64 {
+	result := 0.0
+	for i := len(p) - 1; i >= 0; i-- {
+		result = result*x + p[i]
+	}
+	return result
+}
+
+// EvaluateComplex returns the value of the polynomial at the complex z.
+func EvaluateComplex(p []float64, z complex128) complex128 {
+	var result complex128
+	for i := len(p) - 1; i >= 0; i-- {
+		result = result*z + complex(p[i], 0)
+	}
+	return result
+}
+
+func Add(p, q []float64) []float64 {
+	result := make([]float64, max(len(p), len(q)))
+	copy(result, p)
+	for i, c := range q {
+		result[i] += c
+	}
+	return Trim(result)
+}
+
+func Sub(p, q []float64) []float64 {
+	return Add(p, Scale(q, -1))
+}
+
+func Mul(p, q []float64) []float64 {
+	if len(p) == 0 || len(q) == 0 {
+		return []float64{}
+	}
+	result := make([]float64, len(p)+len(q)-1)
+	for i, a := range p {
+		for j, b := range q {
+			result[i+j] += a * b
+		}
+	}
+	return Trim(result)
+}
+
+func Scale(p []float64, factor float64) []float64 {
+	result := make([]float64, len(p))
+	for i, c := range p {
+		result[i] = c * factor
+	}
+	return Trim(result)
+}
+
+// Pow returns p^n for n >= 0.
+func Pow(p []float64, n int) []float64 {
+	result := []float64{1}
+	for ; n > 0; n-- {
+		result = Mul(result, p)
+	}
+	return result
+}
+
+// DivMod returns the quotient and the remainder of the long division of p by
+// q, q should not be the zero polynomial.
+func DivMod(p, q []float64) ([]float64, []float64) {
+	q = Trim(q)
+	remainder := append([]float64{}, Trim(p)...)
+	if len(remainder) < len(q) {
+		return []float64{}, remainder
+	}
+
+	quotient := make([]float64, len(remainder)-len(q)+1)
+	lead := q[len(q)-1]
+	for i := len(quotient) - 1; i >= 0; i-- {
+		c := remainder[i+len(q)-1] / lead
+		quotient[i] = c
+		for j, b := range q {
+			remainder[i+j] -= c * b
+		}
+		// the leading term is cancelled exactly
+		remainder[i+len(q)-1] = 0
+	}
+
+	return Trim(quotient), Trim(cleanup(remainder[:len(q)-1], p))
+}
+
+// GCD returns the monic greatest common divisor of p and q, coefficients that
+// are rounding errors are treated as zero.
+func GCD(p, q []float64) []float64 {
+	a, b := Trim(p), Trim(q)
+	for len(b) > 0 {
+		_, r := DivMod(a, b)
+		a, b = b, r
+	}
+	if len(a) == 0 {
+		return a
+	}
+	return Scale(a, 1/a[len(a)-1])
+}
+
+// Derivative returns p'.
+func Derivative(p []float64) []float64 {
+	if len(p) <= 1 {
+		return []float64{}
+	}
+	result := make([]float64, len(p)-1)
+	for i := 1; i < len(p); i++ {
+		result[i-1] = float64(i) * p[i]
+	}
+	return Trim(result)
+}
+
+// cleanup sets the coefficients that are tiny compared to the coefficients of
+// reference to zero.
+func cleanup(p, reference []float64) []float64 {
+	magnitude := 0.0
+	for _, c := range reference {
+		magnitude = math.Max(magnitude, math.Abs(c))
+	}
+	for i, c := range p {
+		if math.Abs(c) <= 1e-10*magnitude {
+			p[i] = 0
+		}
+	}
+	return p
+}
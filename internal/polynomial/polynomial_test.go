@@ -0,0 +1,109 @@
+package polynomial
+
+import (
+	"math"
+	"math/cmplx"
+	"slices"
+	"testing"
+)
+
+func TestArithmetic(t *testing.T) {
+	p := []float64{1, -2, 3} // 3x^2 - 2x + 1
+	q := []float64{-1, 1}    // x - 1
+
+	tests := []struct {
+		name   string
+		got    []float64
+		expect []float64
+	}{
+		{"add", Add(p, q), []float64{0, -1, 3}},
+		{"sub", Sub(p, p), []float64{}},
+		{"mul", Mul(p, q), []float64{-1, 3, -5, 3}},
+		{"pow", Pow(q, 3), []float64{-1, 3, -3, 1}},
+		{"derivative", Derivative(p), []float64{-2, 6}},
+		{"derivative of constant", Derivative([]float64{5}), []float64{}},
+		{"gcd", GCD(Mul(q, p), Mul(q, []float64{2, 1})), []float64{-1, 1}},
+		{"gcd of coprime", GCD(p, q), []float64{1}},
+	}
+
+	for _, tt := range tests {
+		if !slices.Equal(tt.got, tt.expect) {
+			t.Errorf("invalid %s, expect=%v, got=%v", tt.name, tt.expect, tt.got)
+		}
+	}
+
+	quotient, remainder := DivMod(p, q)
+	if !slices.Equal(quotient, []float64{1, 3}) || !slices.Equal(remainder, []float64{2}) {
+		t.Errorf("invalid divmod, expect=[1 3] [2], got=%v %v", quotient, remainder)
+	}
+	quotient, remainder = DivMod(q, p)
+	if !slices.Equal(quotient, []float64{}) || !slices.Equal(remainder, q) {
+		t.Errorf("invalid divmod, expect=[] %v, got=%v %v", q, quotient, remainder)
+	}
+
+	if got := Evaluate(p, 2); got != 9 {
+		t.Errorf("invalid evaluate, expect=9, got=%v", got)
+	}
+}
+
+func TestRealRoots(t *testing.T) {
+	tests := []struct {
+		coeffs []float64
+		expect []float64
+	}{
+		{[]float64{-6, 2}, []float64{3}},
+		{[]float64{-4, 0, 1}, []float64{-2, 2}},
+		{[]float64{1, 0, 1}, nil},
+		{[]float64{1, -2, 1}, []float64{1}},
+		{[]float64{1e-10, 1, 1e-10}, []float64{-1e10, -1e-10}},
+		{[]float64{-6, 11, -6, 1}, []float64{1, 2, 3}},
+		{[]float64{-5, -2, 0, 1}, []float64{2.0945514815423265}},
+		{[]float64{0, 0, 0, 2}, []float64{0}},
+		{[]float64{-1, 1, 1, -1, 0}, []float64{-1, 1}},
+		{[]float64{0, 2}, []float64{0}},
+		{[]float64{0, -3, 1}, []float64{0, 3}},
+	}
+
+	for _, tt := range tests {
+		got := RealRoots(tt.coeffs)
+		if len(got) != len(tt.expect) {
+			t.Errorf("invalid roots of %v, expect=%v, got=%v", tt.coeffs, tt.expect, got)
+			continue
+		}
+		for i := range got {
+			if math.Signbit(got[i]) != math.Signbit(tt.expect[i]) || math.Abs(got[i]-tt.expect[i]) > 1e-12*math.Max(1, math.Abs(tt.expect[i])) {
+				t.Errorf("invalid roots of %v, expect=%v, got=%v", tt.coeffs, tt.expect, got)
+				break
+			}
+		}
+	}
+}
+
+func TestRoots(t *testing.T) {
+	tests := []struct {
+		coeffs []float64
+		expect []complex128
+	}{
+		{[]float64{-4, 0, 1}, []complex128{-2, 2}},
+		{[]float64{1, 0, 1}, []complex128{-1i, 1i}},
+		{[]float64{0, 0, -1, 1}, []complex128{0, 0, 1}},
+		{[]float64{-1, 0, 0, 0, 1}, []complex128{-1, -1i, 1i, 1}},
+		{[]float64{-6, 11, -6, 1}, []complex128{1, 2, 3}},
+		{[]float64{5, -2, 1}, []complex128{1 - 2i, 1 + 2i}},
+		{[]float64{3}, nil},
+	}
+
+	for _, tt := range tests {
+		got := Roots(tt.coeffs)
+		if len(got) != len(tt.expect) {
+			t.Errorf("invalid roots of %v, expect=%v, got=%v", tt.coeffs, tt.expect, got)
+			continue
+		}
+		for i := range got {
+			if cmplx.Abs(got[i]-tt.expect[i]) > 1e-9 {
+				t.Errorf("invalid roots of %v, expect=%v, got=%v", tt.coeffs, tt.expect, got)
+				break
+			}
+		}
+	}
+}
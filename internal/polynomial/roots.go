@@ -0,0 +1,197 @@
+package polynomial
+
+import (
+	"cmp"
+	"math"
+	"math/cmplx"
+	"slices"
+	"sort"
+)
+
+// RealRoots returns the sorted real roots of the polynomial of degree 1 to 3,
+// using the closed form solutions.
+func RealRoots(p []float64) []float64 {
+	var roots []float64
+	switch Degree(p) {
+	case 1:
+		roots = []float64{-p[0] / p[1]}
+	case 2:
+		roots = quadraticRoots(p[2], p[1], p[0])
+	case 3:
+		roots = cubicRoots(p[2]/p[3], p[1]/p[3], p[0]/p[3])
+	default:
+		return nil
+	}
+
+	// the closed forms lose a few digits, one Newton step gets them back
+	d := Derivative(p)
+	for i, root := range roots {
+		if slope := Evaluate(d, root); slope != 0 {
+			if polished := root - Evaluate(p, root)/slope; math.Abs(Evaluate(p, polished)) < math.Abs(Evaluate(p, root)) {
+				roots[i] = polished
+			}
+		}
+		roots[i] += 0 // -0 is 0
+	}
+
+	sort.Float64s(roots)
+	return slices.Compact(roots)
+}
+
+// quadraticRoots returns the real roots of ax^2 + bx + c, computed without
+// the cancellation of the textbook formula.
+func quadraticRoots(a, b, c float64) []float64 {
+	discriminant := b*b - 4*a*c
+	if discriminant < 0 {
+		return nil
+	}
+
+	q := -(b + math.Copysign(math.Sqrt(discriminant), b)) / 2
+	if q == 0 {
+		return []float64{0}
+	}
+	return []float64{q / a, c / q}
+}
+
+// cubicRoots returns the real roots of x^3 + ax^2 + bx + c, using the
+// trigonometric solution for three real roots and Cardano's formula otherwise.
+func cubicRoots(a, b, c float64) []float64 {
+	q := (a*a - 3*b) / 9
+	r := (2*a*a*a - 9*a*b + 27*c) / 54
+
+	if r*r < q*q*q {
+		theta := math.Acos(r / math.Sqrt(q*q*q))
+		m := -2 * math.Sqrt(q)
+		return []float64{
+			m*math.Cos(theta/3) - a/3,
+			m*math.Cos((theta+2*math.Pi)/3) - a/3,
+			m*math.Cos((theta-2*math.Pi)/3) - a/3,
+		}
+	}
+
+	s := -math.Copysign(math.Cbrt(math.Abs(r)+math.Sqrt(r*r-q*q*q)), r)
+	t := 0.0
+	if s != 0 {
+		t = q / s
+	}
+	roots := []float64{s + t - a/3}
+	if s == t && s != 0 {
+		// a double root
+		roots = append(roots, -s-a/3)
+	}
+	return roots
+}
+
+// Roots returns all the complex roots of the polynomial, with multiplicity,
+// using the closed forms up to degree 2 and the Durand-Kerner method above.
+// They are sorted by the real part, then the imaginary part.
+func Roots(p []float64) []complex128 {
+	p = Trim(p)
+
+	// the zero roots are factored out, they are exact
+	var roots []complex128
+	for len(p) > 1 && p[0] == 0 {
+		roots = append(roots, 0)
+		p = p[1:]
+	}
+
+	switch n := Degree(p); {
+	case n == 1:
+		roots = append(roots, complex(-p[0]/p[1], 0))
+	case n == 2:
+		roots = append(roots, complexQuadraticRoots(p[2], p[1], p[0])...)
+	case n > 2:
+		roots = append(roots, polish(p, durandKerner(Scale(p, 1/p[n])))...)
+	}
+
+	slices.SortFunc(roots, func(a, b complex128) int {
+		if c := cmp.Compare(real(a), real(b)); c != 0 {
+			return c
+		}
+		return cmp.Compare(imag(a), imag(b))
+	})
+	return roots
+}
+
+// complexQuadraticRoots returns the two roots of ax^2 + bx + c, c is not 0.
+func complexQuadraticRoots(a, b, c float64) []complex128 {
+	discriminant := b*b - 4*a*c
+	if discriminant >= 0 {
+		xs := quadraticRoots(a, b, c)
+		return []complex128{complex(xs[0], 0), complex(xs[1], 0)}
+	}
+
+	re, im := -b/(2*a), math.Sqrt(-discriminant)/math.Abs(2*a)
+	return []complex128{complex(re, -im), complex(re, im)}
+}
+
+// polish improves the roots with one Newton step, if it gets closer to 0.
+func polish(p []float64, roots []complex128) []complex128 {
+	d := Derivative(p)
+	for i, root := range roots {
+		slope := EvaluateComplex(d, root)
+		if slope == 0 {
+			continue
+		}
+		polished := root - EvaluateComplex(p, root)/slope
+		if cmplx.Abs(EvaluateComplex(p, polished)) < cmplx.Abs(EvaluateComplex(p, root)) {
+			roots[i] = polished
+		}
+	}
+	return roots
+}
+
+// durandKerner finds the roots of the monic polynomial simultaneously:
+// z_k -= p(z_k) / prod_{j != k} (z_k - z_j).
+func durandKerner(monic []float64) []complex128 {
+	const maxIterations = 1000
+
+	n := Degree(monic)
+	if n == 1 {
+		return []complex128{complex(-monic[0], 0)}
+	}
+
+	// the initial guesses are spread on a circle of the root bound radius
+	radius := 0.0
+	for _, c := range monic[:n] {
+		radius = math.Max(radius, math.Abs(c))
+	}
+	radius = math.Min(1+radius, 1e6)
+
+	z := make([]complex128, n)
+	seed := complex(0.4, 0.9)
+	for k := range z {
+		z[k] = complex(radius, 0) * cmplx.Pow(seed, complex(float64(k), 0))
+	}
+
+	for iteration := 0; iteration < maxIterations; iteration++ {
+		change := 0.0
+		for k := range z {
+			denominator := complex(1, 0)
+			for j := range z {
+				if j != k {
+					denominator *= z[k] - z[j]
+				}
+			}
+			if denominator == 0 {
+				denominator = complex(1e-12, 0)
+			}
+
+			delta := EvaluateComplex(monic, z[k]) / denominator
+			z[k] -= delta
+			change = math.Max(change, cmplx.Abs(delta)/math.Max(1, cmplx.Abs(z[k])))
+		}
+		if change < 1e-15 {
+			break
+		}
+	}
+
+	// the roots close to the real axis are real, the complex roots of real
+	// polynomials come in conjugate pairs
+	for k := range z {
+		if math.Abs(imag(z[k])) <= 1e-10*math.Max(1, cmplx.Abs(z[k])) {
+			z[k] = complex(real(z[k]), 0)
+		}
+	}
+	return z
+}
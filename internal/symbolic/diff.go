@@ -0,0 +1,208 @@
+package symbolic
+
+import (
+	"fmt"
+
+	"github.com/DeepAung/qcal/internal/ast"
+)
+
+// Derivative returns the derivative of the expression with respect to the
+// variable x, without simplifying it. Identifiers other than x are constants.
+func Derivative(exp ast.Expression, x string) (ast.Expression, error) {
+	switch exp := exp.(type) {
+	case *ast.NumberLiteral:
+		return number(0), nil
+
+	case *ast.Identifier:
+		if exp.Value == x {
+			return number(1), nil
+		}
+		return number(0), nil
+
+	case *ast.PrefixExpression:
+		d, err := Derivative(exp.Right, x)
+		if err != nil {
+			return nil, err
+		}
+		switch exp.Operator {
+		case "+":
+			return d, nil
+		case "-":
+			return neg(d), nil
+		}
+
+	case *ast.InfixExpression:
+		return infixDerivative(exp, x)
+
+	case *ast.CallExpression:
+		return callDerivative(exp, x)
+	}
+
+	return nil, fmt.Errorf("cannot differentiate %s", exp.String())
+}
+
+func infixDerivative(exp *ast.InfixExpression, x string) (ast.Expression, error) {
+	u, v := exp.Left, exp.Right
+
+	du, err := Derivative(u, x)
+	if err != nil {
+		return nil, err
+	}
+	dv, err := Derivative(v, x)
+	if err != nil {
+		return nil, err
+	}
+
+	switch exp.Operator {
+	case "+":
+		return add(du, dv), nil
+	case "-":
+		return sub(du, dv), nil
+	case "*":
+		// (uv)' = u'v + uv'
+		return add(mul(du, v), mul(u, dv)), nil
+	case "/":
+		// (u/v)' = (u'v - uv') / v^2
+		return div(sub(mul(du, v), mul(u, dv)), pow(v, number(2))), nil
+	case "^":
+		return powerDerivative(u, v, du, dv, x), nil
+	}
+
+	return nil, fmt.Errorf("cannot differentiate %s", exp.String())
+}
+
+func powerDerivative(u, v, du, dv ast.Expression, x string) ast.Expression {
+	switch {
+	case !dependsOn(v, x):
+		// (u^c)' = c u^(c-1) u'
+		return mul(mul(v, pow(u, sub(v, number(1)))), du)
+	case !dependsOn(u, x):
+		// (c^v)' = c^v ln(c) v'
+		return mul(mul(pow(u, v), call("ln", u)), dv)
+	default:
+		// (u^v)' = u^v (v' ln(u) + v u'/u)
+		return mul(pow(u, v), add(mul(dv, call("ln", u)), div(mul(v, du), u)))
+	}
+}
+
+// unaryDerivatives returns f'(u) of the unary builtin functions f.
+var unaryDerivatives = map[string]func(u ast.Expression) ast.Expression{
+	"abs":   func(u ast.Expression) ast.Expression { return div(u, call("abs", u)) },
+	"ceil":  func(u ast.Expression) ast.Expression { return number(0) },
+	"floor": func(u ast.Expression) ast.Expression { return number(0) },
+	"round": func(u ast.Expression) ast.Expression { return number(0) },
+
+	"sqrt": func(u ast.Expression) ast.Expression {
+		return div(number(1), mul(number(2), call("sqrt", u)))
+	},
+	"cbrt": func(u ast.Expression) ast.Expression {
+		return div(number(1), mul(number(3), pow(call("cbrt", u), number(2))))
+	},
+
+	"ln": func(u ast.Expression) ast.Expression { return div(number(1), u) },
+	"log10": func(u ast.Expression) ast.Expression {
+		return div(number(1), mul(u, call("ln", number(10))))
+	},
+	"log2": func(u ast.Expression) ast.Expression {
+		return div(number(1), mul(u, call("ln", number(2))))
+	},
+	"pow10": func(u ast.Expression) ast.Expression {
+		return mul(call("pow10", u), call("ln", number(10)))
+	},
+
+	"sin":  func(u ast.Expression) ast.Expression { return call("cos", u) },
+	"cos":  func(u ast.Expression) ast.Expression { return neg(call("sin", u)) },
+	"tan":  func(u ast.Expression) ast.Expression { return div(number(1), pow(call("cos", u), number(2))) },
+	"sinh": func(u ast.Expression) ast.Expression { return call("cosh", u) },
+	"cosh": func(u ast.Expression) ast.Expression { return call("sinh", u) },
+	"tanh": func(u ast.Expression) ast.Expression { return div(number(1), pow(call("cosh", u), number(2))) },
+
+	"arcsin": func(u ast.Expression) ast.Expression {
+		return div(number(1), call("sqrt", sub(number(1), pow(u, number(2)))))
+	},
+	"arccos": func(u ast.Expression) ast.Expression {
+		return neg(div(number(1), call("sqrt", sub(number(1), pow(u, number(2))))))
+	},
+	"arctan": func(u ast.Expression) ast.Expression {
+		return div(number(1), add(number(1), pow(u, number(2))))
+	},
+	"arcsinh": func(u ast.Expression) ast.Expression {
+		return div(number(1), call("sqrt", add(pow(u, number(2)), number(1))))
+	},
+	"arccosh": func(u ast.Expression) ast.Expression {
+		return div(number(1), call("sqrt", sub(pow(u, number(2)), number(1))))
+	},
+	"arctanh": func(u ast.Expression) ast.Expression {
+		return div(number(1), sub(number(1), pow(u, number(2))))
+	},
+
+	"gamma": func(u ast.Expression) ast.Expression {
+		return mul(call("gamma", u), call("digamma", u))
+	},
+}
+
+func callDerivative(exp *ast.CallExpression, x string) (ast.Expression, error) {
+	ident, ok := exp.Function.(*ast.Identifier)
+	if !ok {
+		return nil, fmt.Errorf("cannot differentiate %s", exp.String())
+	}
+
+	args := exp.Arguments
+	derivatives := make([]ast.Expression, len(args))
+	for i, arg := range args {
+		d, err := Derivative(arg, x)
+		if err != nil {
+			return nil, err
+		}
+		derivatives[i] = d
+	}
+
+	if rule, ok := unaryDerivatives[ident.Value]; ok && len(args) == 1 {
+		// chain rule: f(u)' = f'(u) u'
+		return mul(rule(args[0]), derivatives[0]), nil
+	}
+
+	if len(args) == 2 {
+		u, v, du, dv := args[0], args[1], derivatives[0], derivatives[1]
+		switch ident.Value {
+		case "pow":
+			return powerDerivative(u, v, du, dv, x), nil
+		case "log":
+			// log(u, v) = ln(u) / ln(v)
+			return Derivative(div(call("ln", u), call("ln", v)), x)
+		case "hypot":
+			// hypot(u, v)' = (u u' + v v') / hypot(u, v)
+			return div(add(mul(u, du), mul(v, dv)), exp), nil
+		}
+	}
+
+	return nil, fmt.Errorf("cannot differentiate %s", exp.String())
+}
+
+// dependsOn reports whether the expression contains the variable x.
+func dependsOn(exp ast.Expression, x string) bool {
+	switch exp := exp.(type) {
+	case *ast.Identifier:
+		return exp.Value == x
+	case *ast.PrefixExpression:
+		return dependsOn(exp.Right, x)
+	case *ast.PostfixExpression:
+		return dependsOn(exp.Left, x)
+	case *ast.InfixExpression:
+		return dependsOn(exp.Left, x) || dependsOn(exp.Right, x)
+	case *ast.CallExpression:
+		if _, ok := exp.Function.(*ast.Identifier); !ok && dependsOn(exp.Function, x) {
+			return true
+		}
+		for _, arg := range exp.Arguments {
+			if dependsOn(arg, x) {
+				return true
+			}
+		}
+		return false
+	case *ast.NumberLiteral:
+		return false
+	default:
+		return true
+	}
+}
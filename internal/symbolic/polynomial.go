@@ -0,0 +1,83 @@
+package symbolic
+
+import (
+	"math"
+
+	"github.com/DeepAung/qcal/internal/ast"
+	"github.com/DeepAung/qcal/internal/polynomial"
+)
+
+// maxDegree is the largest power expanded by Coefficients.
+const maxDegree = 64
+
+// Coefficients returns the coefficients of the polynomial expression in x,
+// from the constant term up, e.g. `(x ^ 2) - 4` is [-4, 0, 1]. value resolves
+// the other identifiers to numbers. It reports false if the expression is not
+// a polynomial.
+func Coefficients(
+	exp ast.Expression,
+	x string,
+	value func(name string) (float64, bool),
+) ([]float64, bool) {
+	switch exp := exp.(type) {
+	case *ast.NumberLiteral:
+		return polynomial.Trim([]float64{exp.Value}), true
+
+	case *ast.Identifier:
+		if exp.Value == x {
+			return []float64{0, 1}, true
+		}
+		v, ok := value(exp.Value)
+		return polynomial.Trim([]float64{v}), ok
+
+	case *ast.PrefixExpression:
+		right, ok := Coefficients(exp.Right, x, value)
+		if !ok {
+			return nil, false
+		}
+		switch exp.Operator {
+		case "+":
+			return right, true
+		case "-":
+			return polynomial.Scale(right, -1), true
+		}
+
+	case *ast.InfixExpression:
+		left, ok := Coefficients(exp.Left, x, value)
+		if !ok {
+			return nil, false
+		}
+		right, ok := Coefficients(exp.Right, x, value)
+		if !ok {
+			return nil, false
+		}
+
+		switch exp.Operator {
+		case "+":
+			return polynomial.Add(left, right), true
+		case "-":
+			return polynomial.Sub(left, right), true
+		case "*":
+			return polynomial.Mul(left, right), true
+		case "/":
+			if polynomial.Degree(right) != 0 {
+				return nil, false
+			}
+			return polynomial.Scale(left, 1/right[0]), true
+		case "^":
+			if polynomial.Degree(right) > 0 {
+				return nil, false
+			}
+			n := 0.0 // the zero polynomial is 0
+			if len(right) > 0 {
+				n = right[0]
+			}
+			if n < 0 || n != math.Trunc(n) || n*float64(polynomial.Degree(left)) > maxDegree {
+				return nil, false
+			}
+			return polynomial.Pow(left, int(n)), true
+		}
+	}
+
+	return nil, false
+}
@@ -0,0 +1,284 @@
+package symbolic
+
+import (
+	"math"
+	"strconv"
+	"strings"
+
+	"github.com/DeepAung/qcal/internal/ast"
+	"github.com/DeepAung/qcal/internal/token"
+)
+
+// Simplify folds the constants, removes the identity operations and collects
+// the like terms and factors of the expression, e.g. `((2 * (x ^ (2 - 1))) * 1)`
+// becomes `(2 * x)` and `((x + x) * x)` becomes `(2 * (x ^ 2))`.
+func Simplify(exp ast.Expression) ast.Expression {
+	switch exp := exp.(type) {
+	case *ast.PrefixExpression:
+		return simplifyPrefix(exp.Operator, Simplify(exp.Right))
+	case *ast.InfixExpression:
+		return simplifyInfix(exp.Operator, Simplify(exp.Left), Simplify(exp.Right))
+	case *ast.CallExpression:
+		args := make([]ast.Expression, len(exp.Arguments))
+		for i, arg := range exp.Arguments {
+			args[i] = Simplify(arg)
+		}
+		if isIdentifier(exp.Function, "ln") && len(args) == 1 && isIdentifier(args[0], "e") {
+			return number(1)
+		}
+		return &ast.CallExpression{Token: exp.Token, Function: exp.Function, Arguments: args}
+	default:
+		return exp
+	}
+}
+
+func simplifyPrefix(operator string, right ast.Expression) ast.Expression {
+	if operator == "+" {
+		return right
+	}
+	if operator != "-" {
+		return &ast.PrefixExpression{Token: operatorToken(operator), Operator: operator, Right: right}
+	}
+
+	if n, ok := numberValue(right); ok {
+		return number(-n)
+	}
+	if inner, ok := right.(*ast.PrefixExpression); ok && inner.Operator == "-" {
+		return inner.Right
+	}
+	return neg(right)
+}
+
+func simplifyInfix(operator string, left, right ast.Expression) ast.Expression {
+	l, isLeftNumber := numberValue(left)
+	r, isRightNumber := numberValue(right)
+
+	if isLeftNumber && isRightNumber {
+		if result, ok := fold(operator, l, r); ok {
+			return number(result)
+		}
+	}
+
+	switch operator {
+	case "+":
+		switch {
+		case isLeftNumber && l == 0:
+			return right
+		case isRightNumber && r == 0:
+			return left
+		case isRightNumber && r < 0:
+			return simplifyInfix("-", left, number(-r))
+		}
+		// `(x + (2 * x))` becomes `(3 * x)`
+		if !isLeftNumber && !isRightNumber {
+			cl, bl := splitCoefficient(left)
+			cr, br := splitCoefficient(right)
+			if bl.String() == br.String() {
+				return simplifyInfix("*", number(cl+cr), bl)
+			}
+		}
+		if inner, ok := right.(*ast.PrefixExpression); ok && inner.Operator == "-" {
+			return simplifyInfix("-", left, inner.Right)
+		}
+
+	case "-":
+		switch {
+		case isRightNumber && r == 0:
+			return left
+		case isLeftNumber && l == 0:
+			return simplifyPrefix("-", right)
+		case left.String() == right.String():
+			return number(0)
+		}
+		// `((3 * x) - x)` becomes `(2 * x)`
+		if !isLeftNumber && !isRightNumber {
+			cl, bl := splitCoefficient(left)
+			cr, br := splitCoefficient(right)
+			if bl.String() == br.String() {
+				return simplifyInfix("*", number(cl-cr), bl)
+			}
+		}
+
+	case "*":
+		switch {
+		case (isLeftNumber && l == 0) || (isRightNumber && r == 0):
+			return number(0)
+		case isLeftNumber && l == 1:
+			return right
+		case isRightNumber && r == 1:
+			return left
+		case isLeftNumber && l == -1:
+			return simplifyPrefix("-", right)
+		case isRightNumber && r == -1:
+			return simplifyPrefix("-", left)
+		case isRightNumber:
+			// keep the constant on the left, e.g. `(x * 2)` becomes `(2 * x)`
+			return simplifyInfix("*", right, left)
+		}
+
+		// `(2 * (3 * x))` becomes `(6 * x)`
+		if inner, ok := right.(*ast.InfixExpression); ok && isLeftNumber && inner.Operator == "*" {
+			if n, ok := numberValue(inner.Left); ok {
+				return simplifyInfix("*", number(l*n), inner.Right)
+			}
+		}
+		// `((2 * x) * (x ^ 2))` becomes `(2 * (x ^ 3))`
+		if !isLeftNumber && !isRightNumber {
+			cl, bl := splitCoefficient(left)
+			cr, br := splitCoefficient(right)
+			baseLeft, el := splitExponent(bl)
+			baseRight, er := splitExponent(br)
+			if baseLeft.String() == baseRight.String() {
+				return simplifyInfix("*", number(cl*cr), simplifyInfix("^", baseLeft, number(el+er)))
+			}
+		}
+		if inner, ok := left.(*ast.PrefixExpression); ok && inner.Operator == "-" {
+			return simplifyPrefix("-", simplifyInfix("*", inner.Right, right))
+		}
+		if inner, ok := right.(*ast.PrefixExpression); ok && inner.Operator == "-" {
+			return simplifyPrefix("-", simplifyInfix("*", left, inner.Right))
+		}
+
+	case "/":
+		switch {
+		case isRightNumber && r == 1:
+			return left
+		case isLeftNumber && l == 0:
+			return number(0)
+		case left.String() == right.String():
+			return number(1)
+		case isLeftNumber && isRightNumber && l == math.Trunc(l) && r == math.Trunc(r):
+			// reduce the fraction, e.g. `(3 / 9)` becomes `(1 / 3)`
+			if g := gcd(math.Abs(l), math.Abs(r)); g > 1 {
+				return simplifyInfix("/", number(l/g), number(r/g))
+			}
+		}
+
+	case "^":
+		switch {
+		case isRightNumber && r == 0:
+			return number(1)
+		case isRightNumber && r == 1:
+			return left
+		case isLeftNumber && l == 1:
+			return number(1)
+		}
+	}
+
+	return infix(operator, left, right)
+}
+
+// fold evaluates the operation of two numbers. Divisions and powers are only
+// folded when the result is short, so `1 / 3` stays exact.
+func fold(operator string, l, r float64) (float64, bool) {
+	var result float64
+	switch operator {
+	case "+":
+		result = l + r
+	case "-":
+		result = l - r
+	case "*":
+		result = l * r
+	case "/":
+		result = l / r
+	case "^":
+		result = math.Pow(l, r)
+	default:
+		return 0, false
+	}
+
+	if math.IsNaN(result) || math.IsInf(result, 0) {
+		return 0, false
+	}
+	if operator == "/" || operator == "^" {
+		_, decimals, _ := strings.Cut(strconv.FormatFloat(result, 'f', -1, 64), ".")
+		if len(decimals) > 6 {
+			return 0, false
+		}
+	}
+	return result, true
+}
+
+// splitCoefficient splits the term into its constant coefficient and the rest,
+// e.g. `(2 * x)` into 2 and `x`, and `(-x)` into -1 and `x`.
+func splitCoefficient(exp ast.Expression) (float64, ast.Expression) {
+	switch exp := exp.(type) {
+	case *ast.InfixExpression:
+		if n, ok := numberValue(exp.Left); ok && exp.Operator == "*" {
+			return n, exp.Right
+		}
+	case *ast.PrefixExpression:
+		if exp.Operator == "-" {
+			return -1, exp.Right
+		}
+	}
+	return 1, exp
+}
+
+// splitExponent splits the factor into its base and constant exponent, e.g.
+// `(x ^ 2)` into `x` and 2, and `x` into `x` and 1.
+func splitExponent(exp ast.Expression) (ast.Expression, float64) {
+	if exp, ok := exp.(*ast.InfixExpression); ok && exp.Operator == "^" {
+		if n, ok := numberValue(exp.Right); ok {
+			return exp.Left, n
+		}
+	}
+	return exp, 1
+}
+
+func isIdentifier(exp ast.Expression, name string) bool {
+	ident, ok := exp.(*ast.Identifier)
+	return ok && ident.Value == name
+}
+
+func gcd(a, b float64) float64 {
+	for b != 0 {
+		a, b = b, math.Mod(a, b)
+	}
+	return a
+}
+
+func numberValue(exp ast.Expression) (float64, bool) {
+	if n, ok := exp.(*ast.NumberLiteral); ok {
+		return n.Value, true
+	}
+	return 0, false
+}
+
+// Expression builders ----------------------------------------------------------- //
+
+func number(value float64) *ast.NumberLiteral {
+	literal := strconv.FormatFloat(value, 'f', -1, 64)
+	return &ast.NumberLiteral{Token: token.Token{Type: token.NUMBER, Literal: literal}, Value: value}
+}
+
+func neg(right ast.Expression) ast.Expression {
+	return &ast.PrefixExpression{Token: operatorToken("-"), Operator: "-", Right: right}
+}
+
+func infix(operator string, left, right ast.Expression) ast.Expression {
+	return &ast.InfixExpression{
+		Token:    operatorToken(operator),
+		Operator: operator,
+		Left:     left,
+		Right:    right,
+	}
+}
+
+func add(left, right ast.Expression) ast.Expression { return infix("+", left, right) }
+func sub(left, right ast.Expression) ast.Expression { return infix("-", left, right) }
+func mul(left, right ast.Expression) ast.Expression { return infix("*", left, right) }
+func div(left, right ast.Expression) ast.Expression { return infix("/", left, right) }
+func pow(left, right ast.Expression) ast.Expression { return infix("^", left, right) }
+
+func call(name string, args ...ast.Expression) ast.Expression {
+	return &ast.CallExpression{
+		Token:     token.Token{Type: token.LPAREN, Literal: "("},
+		Function:  &ast.Identifier{Token: token.Token{Type: token.IDENT, Literal: name}, Value: name},
+		Arguments: args,
+	}
+}
+
+func operatorToken(operator string) token.Token {
+	return token.Token{Type: token.TokenType(operator), Literal: operator}
+}
@@ -0,0 +1,154 @@
+package symbolic
+
+import (
+	"slices"
+	"testing"
+
+	"github.com/DeepAung/qcal/internal/ast"
+	"github.com/DeepAung/qcal/internal/lexer"
+	"github.com/DeepAung/qcal/internal/parser"
+)
+
+func TestDerivative(t *testing.T) {
+	tests := []struct {
+		input  string
+		expect string
+	}{
+		// arithmetic
+		{"5", "0"},
+		{"a", "0"},
+		{"x", "1"},
+		{"-x", "-1"},
+		{"x + 3", "1"},
+		{"3 * x", "3"},
+		{"x * x", "(2 * x)"},
+		{"x * x * x", "(3 * (x ^ 2))"},
+		{"x * x - x ^ 2", "0"},
+		{"2 * x * (x ^ 2)", "(6 * (x ^ 2))"},
+		{"x - a * x", "(1 - a)"},
+		{"1 / x", "(-1 / (x ^ 2))"},
+		{"x / 2", "0.5"},
+		{"x / 3", "(1 / 3)"},
+		// powers
+		{"x ^ 2", "(2 * x)"},
+		{"x ^ 3", "(3 * (x ^ 2))"},
+		{"3 * x ^ 2 + 2 * x + 1", "((6 * x) + 2)"},
+		{"2 ^ x", "((2 ^ x) * ln(2))"},
+		{"e ^ x", "(e ^ x)"},
+		{"e ^ (2 * x)", "(2 * (e ^ (2 * x)))"},
+		{"x ^ x", "((x ^ x) * (ln(x) + 1))"},
+		{"pow(x, 2)", "(2 * x)"},
+		// builtins and the chain rule
+		{"sin(x)", "cos(x)"},
+		{"cos(x)", "(-sin(x))"},
+		{"sin(2 * x)", "(2 * cos((2 * x)))"},
+		{"ln(x ^ 2)", "((1 / (x ^ 2)) * (2 * x))"},
+		{"sqrt(x)", "(1 / (2 * sqrt(x)))"},
+		{"tan(x)", "(1 / (cos(x) ^ 2))"},
+		{"arctan(x)", "(1 / (1 + (x ^ 2)))"},
+		{"exp(x)", "error"},
+		{"gamma(x)", "(gamma(x) * digamma(x))"},
+		{"floor(x)", "0"},
+		{"x!", "error"},
+		{"f(x)", "error"},
+	}
+
+	for _, tt := range tests {
+		program, errors := parser.New(lexer.New(tt.input)).ParseProgram()
+		if len(errors) > 0 {
+			t.Fatalf("parseProgram of %q failed: %v", tt.input, errors)
+		}
+		exp := program.Statements[0].(*ast.ExpressionStatement).Expression
+
+		derivative, err := Derivative(exp, "x")
+		if tt.expect == "error" {
+			if err == nil {
+				t.Errorf("derivative of %q should error, got %s", tt.input, derivative.String())
+			}
+			continue
+		}
+		if err != nil {
+			t.Errorf("derivative of %q failed: %v", tt.input, err)
+			continue
+		}
+
+		got := Simplify(derivative).String()
+		if got != tt.expect {
+			t.Errorf("invalid derivative of %q, expect=%q, got=%q", tt.input, tt.expect, got)
+		}
+	}
+}
+
+func TestSimplify(t *testing.T) {
+	tests := []struct {
+		input  string
+		expect string
+	}{
+		{"x + x", "(2 * x)"},
+		{"3 * x - x", "(2 * x)"},
+		{"-x + x", "0"},
+		{"x * x * x", "(x ^ 3)"},
+		{"(2 * x) * (x ^ 2)", "(2 * (x ^ 3))"},
+		{"sin(x) * sin(x)", "(sin(x) ^ 2)"},
+		{"ln(e) * x", "x"},
+		{"x + y", "(x + y)"},
+		{"x * y", "(x * y)"},
+	}
+
+	for _, tt := range tests {
+		program, errors := parser.New(lexer.New(tt.input)).ParseProgram()
+		if len(errors) > 0 {
+			t.Fatalf("parseProgram of %q failed: %v", tt.input, errors)
+		}
+		exp := program.Statements[0].(*ast.ExpressionStatement).Expression
+
+		got := Simplify(exp).String()
+		if got != tt.expect {
+			t.Errorf("invalid simplification of %q, expect=%q, got=%q", tt.input, tt.expect, got)
+		}
+	}
+}
+
+func TestCoefficients(t *testing.T) {
+	tests := []struct {
+		input  string
+		expect []float64 // nil if not a polynomial
+	}{
+		{"5", []float64{5}},
+		{"x", []float64{0, 1}},
+		{"x ^ 2 - 4", []float64{-4, 0, 1}},
+		{"(x - 1) * (x + 1)", []float64{-1, 0, 1}},
+		{"a * x ^ 3 - x / 2", []float64{0, -0.5, 0, 3}},
+		{"-(x + 1) ^ 2", []float64{-1, -2, -1}},
+		{"1 / x", nil},
+		{"x ^ 0.5", nil},
+		{"sin(x)", nil},
+		{"b * x", nil},
+	}
+
+	value := func(name string) (float64, bool) {
+		if name == "a" {
+			return 3, true
+		}
+		return 0, false
+	}
+
+	for _, tt := range tests {
+		program, errors := parser.New(lexer.New(tt.input)).ParseProgram()
+		if len(errors) > 0 {
+			t.Fatalf("parseProgram of %q failed: %v", tt.input, errors)
+		}
+		exp := program.Statements[0].(*ast.ExpressionStatement).Expression
+
+		coeffs, ok := Coefficients(exp, "x", value)
+		if tt.expect == nil {
+			if ok {
+				t.Errorf("%q should not be a polynomial, got %v", tt.input, coeffs)
+			}
+			continue
+		}
+		if !ok || !slices.Equal(coeffs, tt.expect) {
+			t.Errorf("invalid coefficients of %q, expect=%v, got=%v (%v)", tt.input, tt.expect, coeffs, ok)
+		}
+	}
+}
@@ -3,6 +3,10 @@ package token
 type Token struct {
 	Type    TokenType
 	Literal string
+	// Line and Column are the position of the token in the input, from 1,
+	// or 0 for the tokens not read from an input.
+	Line   int
+	Column int
 }
 
 type TokenType string
@@ -14,6 +18,8 @@ const (
 	// Identifiers + Literals
 	IDENT  TokenType = "IDENT"
 	NUMBER TokenType = "NUMBER" // e.g. "123", "112.", ".20", "122.02"
+	REF    TokenType = "REF"    // e.g. "$1", "$20"
+	STRING TokenType = "STRING" // e.g. "\"plot.svg\""
 
 	// Operators
 	ASSIGN   TokenType = "="
@@ -32,16 +38,31 @@ const (
 	EQ     TokenType = "=="
 	NOT_EQ TokenType = "!="
 
+	PLUS_ASSIGN     TokenType = "+="
+	MINUS_ASSIGN    TokenType = "-="
+	ASTERISK_ASSIGN TokenType = "*="
+	SLASH_ASSIGN    TokenType = "/="
+
+	EQUATION TokenType = ":=" // e.g. `x^2 := 4`
+	PIPE     TokenType = "|>" // e.g. `x |> f`
+	COMPOSE  TokenType = "∘"  // e.g. `f ∘ g`, or `f . g` with spaces around the `.`
+	DOT      TokenType = "."  // e.g. `e.message` or `lib.fn`
+
 	// Delimiters
 	COMMA     TokenType = ","
 	SEMICOLON TokenType = ";"
 
+	ELLIPSIS TokenType = "..." // e.g. `(...xs) => xs`
+
 	ARROW  TokenType = "=>"
 	LPAREN TokenType = "("
 	RPAREN TokenType = ")"
 	LBRACE TokenType = "{"
 	RBRACE TokenType = "}"
 
+	LBRACKET TokenType = "["
+	RBRACKET TokenType = "]"
+
 	// Keywords
 	TRUE   TokenType = "TRUE"
 	FALSE  TokenType = "FALSE"
@@ -50,6 +71,19 @@ const (
 	IF     TokenType = "IF"
 	ELSE   TokenType = "ELSE"
 	RETURN TokenType = "RETURN"
+	LET    TokenType = "LET"
+	CONST  TokenType = "CONST"
+
+	WHILE    TokenType = "WHILE"
+	FOR      TokenType = "FOR"
+	IN       TokenType = "IN"
+	BREAK    TokenType = "BREAK"
+	CONTINUE TokenType = "CONTINUE"
+
+	TRY   TokenType = "TRY"
+	CATCH TokenType = "CATCH"
+
+	IMPORT TokenType = "IMPORT"
 )
 
 var keywords = map[string]TokenType{
@@ -60,6 +94,19 @@ var keywords = map[string]TokenType{
 	"if":     IF,
 	"else":   ELSE,
 	"return": RETURN,
+	"let":    LET,
+	"const":  CONST,
+
+	"while":    WHILE,
+	"for":      FOR,
+	"in":       IN,
+	"break":    BREAK,
+	"continue": CONTINUE,
+
+	"try":   TRY,
+	"catch": CATCH,
+
+	"import": IMPORT,
 }
 
 func LookupIdent(literal string) TokenType {